@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/wgliang/opengacm/modules/client/internal/objfile"
+	"github.com/wgliang/opengacm/modules/client/internal/proc"
+)
+
+var (
+	caddr2lineTarget = caddr2line.Arg("target", "Go binary, or PID of a running Go process.").Required().String()
+	caddr2lineAddrs  = caddr2line.Arg("addresses", "Program counters, in hexadecimal.").Required().Strings()
+	caddr2lineOffset = caddr2line.Flag("offset", "Load offset of a position-independent binary to subtract from the addresses, such as the one of a process no longer running.").String()
+)
+
+// addr2line prints the function, file and line of program counters of a
+// Go binary or process, such as the ones of crash logs.
+func addr2line() error {
+	path, err := binaryPath(*caddr2lineTarget)
+	if err != nil {
+		return err
+	}
+	f, err := objfile.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	tab, err := f.PCLineTable()
+	if err != nil {
+		return fmt.Errorf("%s: %v", *caddr2lineTarget, err)
+	}
+	var offset uint64
+	if *caddr2lineOffset != "" {
+		if offset, err = parseAddr(*caddr2lineOffset); err != nil {
+			return err
+		}
+	} else if pid, err := strconv.Atoi(*caddr2lineTarget); err == nil && path == proc.Path(pid, "exe") {
+		if offset, err = loadOffset(pid, f); err != nil {
+			return err
+		}
+	}
+
+	pcs := make([]uint64, len(*caddr2lineAddrs))
+	for i, s := range *caddr2lineAddrs {
+		if pcs[i], err = parseAddr(s); err != nil {
+			return err
+		}
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	defer tw.Flush()
+	for _, pc := range pcs {
+		file, line, fn := tab.PCToLine(pc - offset)
+		name := "??"
+		if fn != nil {
+			name = fn.Name
+		}
+		if file == "" {
+			file = "??"
+		}
+		fmt.Fprintf(tw, "%#x\t%s\t%s:%d\n", pc, name, file, line)
+	}
+	return nil
+}
+
+// parseAddr parses a hexadecimal address, with or without 0x prefix.
+func parseAddr(s string) (uint64, error) {
+	addr, err := strconv.ParseUint(strings.TrimPrefix(strings.TrimPrefix(s, "0x"), "0X"), 16, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid address %s", s)
+	}
+	return addr, nil
+}
+
+// loadOffset returns the difference between the addresses the binary f of
+// the process pid is mapped at and the ones it is linked at. It is zero
+// but for position-independent executables.
+func loadOffset(pid int, f *objfile.File) (uint64, error) {
+	maps, err := proc.Maps(pid)
+	if err != nil {
+		return 0, err
+	}
+	exe, err := os.Readlink(proc.Path(pid, "exe"))
+	if err != nil {
+		return 0, err
+	}
+	load, err := f.LoadAddress()
+	if err != nil {
+		return 0, err
+	}
+	mapped := proc.Mapped(maps, strings.TrimSuffix(exe, " (deleted)"))
+	if len(mapped) == 0 {
+		return 0, fmt.Errorf("binary of process %d is not mapped", pid)
+	}
+	return mapped[0].Start - mapped[0].Offset - load, nil
+}
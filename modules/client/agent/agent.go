@@ -165,7 +165,7 @@ func formatBytes(val uint64) string {
 	return fmt.Sprintf("%d bytes", val)
 }
 
-func handle(conn io.Writer, msg []byte) error {
+func handle(conn io.ReadWriter, msg []byte) error {
 	switch msg[0] {
 	case signal.StackTrace:
 		return pprof.Lookup("goroutine").WriteTo(conn, 2)
@@ -210,6 +210,16 @@ func handle(conn io.Writer, msg []byte) error {
 		}
 		time.Sleep(30 * time.Second)
 		pprof.StopCPUProfile()
+	case signal.CPUProfileFor:
+		secs := make([]byte, 1)
+		if _, err := io.ReadFull(conn, secs); err != nil {
+			return err
+		}
+		if err := pprof.StartCPUProfile(conn); err != nil {
+			return err
+		}
+		time.Sleep(time.Duration(secs[0]) * time.Second)
+		pprof.StopCPUProfile()
 	case signal.Stats:
 		fmt.Fprintf(conn, "goroutines: %v\n", runtime.NumGoroutine())
 		fmt.Fprintf(conn, "OS threads: %v\n", pprof.Lookup("threadcreate").Count())
@@ -5,8 +5,14 @@
 package agent
 
 import (
+	"bytes"
+	"io"
 	"os"
+	"strings"
 	"testing"
+
+	"github.com/wgliang/opengacm/modules/client/internal/profile"
+	"github.com/wgliang/opengacm/modules/client/signal"
 )
 
 func TestListen(t *testing.T) {
@@ -43,6 +49,39 @@ func TestAgentListenMultipleClose(t *testing.T) {
 	Close()
 }
 
+// conn is a connection to the agent, from which it reads the parameters of
+// a command and to which it writes the reply.
+type conn struct {
+	in  io.Reader
+	out bytes.Buffer
+}
+
+func (c *conn) Read(b []byte) (int, error)  { return c.in.Read(b) }
+func (c *conn) Write(b []byte) (int, error) { return c.out.Write(b) }
+
+func TestHandleCPUProfileFor(t *testing.T) {
+	c := &conn{in: bytes.NewReader([]byte{1})}
+	if err := handle(c, []byte{signal.CPUProfileFor}); err != nil {
+		t.Fatal(err)
+	}
+	p, err := profile.Parse(&c.out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.SampleType) == 0 || p.SampleType[len(p.SampleType)-1].Type != "cpu" {
+		t.Errorf("sample types = %v; want a CPU profile", p.SampleType)
+	}
+	if p.DurationNanos < 1e9 || p.DurationNanos > 5e9 {
+		t.Errorf("profile of %dns; want about 1s", p.DurationNanos)
+	}
+
+	// The duration is a parameter byte following the command.
+	c = &conn{in: strings.NewReader("")}
+	if err := handle(c, []byte{signal.CPUProfileFor}); err == nil {
+		t.Error("CPUProfileFor without duration succeeded")
+	}
+}
+
 func TestFormatBytes(t *testing.T) {
 	tests := []struct {
 		val  uint64
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/wgliang/opengacm/modules/client/internal/objfile"
+	"github.com/wgliang/opengacm/modules/client/internal/report"
+)
+
+var (
+	cannotateTarget    = cannotate.Arg("binary", "Profiled Go binary, or PID or host:port of an agent to fetch the binary from.").Required().String()
+	cannotateProfile   = cannotate.Arg("profile", "CPU profile of the binary, such as written by pprof-cpu or sample.").Required().String()
+	cannotateFunc      = cannotate.Flag("func", "Only annotate the functions matching this regular expression.").Short('s').String()
+	cannotateSample    = cannotate.Flag("sample", "Sample type to annotate with; defaults to the profile default.").String()
+	cannotateTop       = cannotate.Flag("top", "Number of functions to annotate, hottest first, 0 for all.").Default("10").Int()
+	cannotateSourceDir = cannotate.Flag("source-dir", "Directory to look the sources of -trimpath binaries up in, may be repeated.").Strings()
+)
+
+// hotFunc is a function of the binary with samples.
+type hotFunc struct {
+	sym       objfile.Sym
+	flat, cum int64
+}
+
+// annotate prints the instructions of the hottest functions of a profile
+// with their sample values and source lines.
+func annotate() error {
+	var filter *regexp.Regexp
+	if *cannotateFunc != "" {
+		var err error
+		if filter, err = regexp.Compile(*cannotateFunc); err != nil {
+			return err
+		}
+	}
+	p, err := readProfile(*cannotateProfile)
+	if err != nil {
+		return err
+	}
+	i, err := report.SampleIndex(p, *cannotateSample)
+	if err != nil {
+		return err
+	}
+	unit := p.SampleType[i].Unit
+	path, cleanup, err := fetchBinary(*cannotateTarget)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	f, d, err := openDisasm(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	values, err := report.Addresses(p, i, f)
+	if err != nil {
+		return err
+	}
+
+	var hot []hotFunc
+	for _, sym := range d.Funcs() {
+		if filter != nil && !filter.MatchString(sym.Name) {
+			continue
+		}
+		flat, cum := values.Range(sym.Addr, sym.Addr+uint64(sym.Size))
+		if flat != 0 || cum != 0 {
+			hot = append(hot, hotFunc{sym, flat, cum})
+		}
+	}
+	if len(hot) == 0 {
+		return fmt.Errorf("no samples in the functions of %s; is it the profiled binary?", *cannotateTarget)
+	}
+	sort.SliceStable(hot, func(i, j int) bool {
+		if hot[i].flat != hot[j].flat {
+			return hot[i].flat > hot[j].flat
+		}
+		return hot[i].cum > hot[j].cum
+	})
+	if *cannotateTop > 0 && len(hot) > *cannotateTop {
+		hot = hot[:*cannotateTop]
+	}
+
+	src := objfile.NewSourceCache(sourceDirs(*cannotateSourceDir)...)
+	w := bufio.NewWriter(os.Stdout)
+	defer w.Flush()
+	for n, fn := range hot {
+		if n > 0 {
+			fmt.Fprintln(w)
+		}
+		annotateFunc(w, d, fn, values, unit, src)
+	}
+	return nil
+}
+
+// annotateFunc prints the instructions of fn with their sample values,
+// preceded by the source lines they were compiled from.
+func annotateFunc(w io.Writer, d *objfile.Disasm, fn hotFunc, values *report.AddrValues, unit string, src *objfile.SourceCache) {
+	total := values.Total
+	value := func(v int64) (string, string) {
+		if v == 0 {
+			return ".", "."
+		}
+		return report.FormatValue(v, unit), percentOf(v, total)
+	}
+	var file string
+	d.Decode(fn.sym.Addr, fn.sym.Addr+1, nil, func(_, _ uint64, f string, _ int, _ string) { file = f })
+	fmt.Fprintf(w, "TEXT %s(SB) %s\n", fn.sym.Name, file)
+	flat, flatPct := value(fn.flat)
+	cum, cumPct := value(fn.cum)
+	fmt.Fprintf(w, "flat %s (%s), cum %s (%s) of %s total\n", flat, flatPct, cum, cumPct, report.FormatValue(total, unit))
+
+	tw := tabwriter.NewWriter(w, 1, 8, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "flat\tflat%\tcum\tcum%\t\t")
+	var lastFile string
+	var lastLine int
+	d.Decode(fn.sym.Addr, fn.sym.Addr+uint64(fn.sym.Size), fn.sym.Relocs, func(pc, size uint64, file string, line int, text string) {
+		if file != lastFile || line != lastLine {
+			lastFile, lastLine = file, line
+			if b, err := src.Line(file, line); err == nil {
+				// Tabs would be taken for cells by the tabwriter.
+				fmt.Fprintf(tw, "\t\t\t\t\t%s\n", strings.Replace(string(b), "\t", "    ", -1))
+			}
+		}
+		flat, cum := values.Range(pc, pc+size)
+		fs, fp := value(flat)
+		cs, cp := value(cum)
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t\t%s:%d  %#x  %s\n", fs, fp, cs, cp, filepath.Base(file), line, pc, strings.TrimSpace(text))
+	})
+	tw.Flush()
+}
+
+func percentOf(v, total int64) string {
+	if total == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.2f%%", 100*float64(v)/float64(total))
+}
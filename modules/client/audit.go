@@ -0,0 +1,210 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/BurntSushi/toml"
+	"github.com/wgliang/opengacm/modules/client/internal/objfile"
+)
+
+var (
+	cauditTarget = caudit.Arg("binary", "Go binary, or PID of a running Go process.").Required().String()
+	cauditPolicy = caudit.Flag("policy", "TOML policy file the binary must comply with.").String()
+	cauditJSON   = caudit.Flag("json", "Print the report as JSON.").Bool()
+)
+
+// auditReport is the hardening and build hygiene of a Go binary.
+type auditReport struct {
+	Binary     string   `json:"binary"`
+	GoVersion  string   `json:"go_version"`
+	Format     string   `json:"format"`
+	PIE        bool     `json:"pie"`
+	RELRO      string   `json:"relro,omitempty"`
+	NX         bool     `json:"nx"`
+	Stripped   bool     `json:"stripped"`
+	DWARF      bool     `json:"dwarf"`
+	Race       bool     `json:"race"`
+	Trimpath   bool     `json:"trimpath"`
+	Cgo        bool     `json:"cgo"`
+	Libraries  []string `json:"libraries"`
+	Deviations []string `json:"deviations,omitempty"`
+}
+
+// auditPolicy is the policy binaries are audited against. Unset fields
+// are not checked.
+//
+//	pie = true
+//	relro = "full"          # minimum: "none", "partial" or "full"
+//	nx = true
+//	stripped = false
+//	dwarf = false
+//	race = false
+//	trimpath = true
+//	cgo = false
+//	libraries = ["libc.so.*"]  # allowed dynamic libraries, as patterns
+type auditPolicy struct {
+	PIE       *bool    `toml:"pie"`
+	RELRO     string   `toml:"relro"`
+	NX        *bool    `toml:"nx"`
+	Stripped  *bool    `toml:"stripped"`
+	DWARF     *bool    `toml:"dwarf"`
+	Race      *bool    `toml:"race"`
+	Trimpath  *bool    `toml:"trimpath"`
+	Cgo       *bool    `toml:"cgo"`
+	Libraries []string `toml:"libraries"`
+}
+
+// relroLevels orders the RELRO levels.
+var relroLevels = map[string]int{"none": 0, "partial": 1, "full": 2}
+
+// audit reports the exploit mitigations and the build options of a Go
+// binary and, given a policy, how the binary deviates from it.
+func audit() error {
+	var policy *auditPolicy
+	if *cauditPolicy != "" {
+		policy = new(auditPolicy)
+		if _, err := toml.DecodeFile(*cauditPolicy, policy); err != nil {
+			return fmt.Errorf("%s: %v", *cauditPolicy, err)
+		}
+		if _, ok := relroLevels[policy.RELRO]; policy.RELRO != "" && !ok {
+			return fmt.Errorf("%s: unknown RELRO level %q", *cauditPolicy, policy.RELRO)
+		}
+	}
+	path, err := binaryPath(*cauditTarget)
+	if err != nil {
+		return err
+	}
+	r, err := readAuditReport(path)
+	if err != nil {
+		return err
+	}
+	r.Binary = *cauditTarget
+	if policy != nil {
+		r.Deviations = policy.check(r)
+	}
+	if *cauditJSON {
+		if err := printJSON(r); err != nil {
+			return err
+		}
+	} else {
+		r.print(os.Stdout)
+	}
+	if len(r.Deviations) > 0 {
+		return fmt.Errorf("%s: %d policy deviations", r.Binary, len(r.Deviations))
+	}
+	return nil
+}
+
+// readAuditReport audits the Go binary at path.
+func readAuditReport(path string) (*auditReport, error) {
+	b, err := readBuildReport(path)
+	if err != nil {
+		return nil, err
+	}
+	f, err := objfile.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	h, err := f.Hardening()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	r := &auditReport{
+		Binary:    path,
+		GoVersion: b.GoVersion,
+		Format:    h.Format,
+		PIE:       h.PIE,
+		RELRO:     h.RELRO,
+		NX:        h.NX,
+		Stripped:  h.Stripped,
+		DWARF:     h.DWARF,
+		Race:      b.Race,
+		Trimpath:  b.Trimpath,
+		Cgo:       b.CgoEnabled,
+		Libraries: h.Libraries,
+	}
+	if r.Libraries == nil {
+		r.Libraries = []string{}
+	}
+	return r, nil
+}
+
+// check returns the deviations of r from the policy.
+func (p *auditPolicy) check(r *auditReport) []string {
+	var deviations []string
+	flag := func(name string, want *bool, got bool) {
+		if want != nil && *want != got {
+			deviations = append(deviations, fmt.Sprintf("%s is %s, policy requires %s", name, yesNo(got), yesNo(*want)))
+		}
+	}
+	flag("PIE", p.PIE, r.PIE)
+	if p.RELRO != "" && r.RELRO != "" && relroLevels[r.RELRO] < relroLevels[p.RELRO] {
+		deviations = append(deviations, fmt.Sprintf("RELRO is %s, policy requires %s", r.RELRO, p.RELRO))
+	}
+	flag("NX", p.NX, r.NX)
+	flag("stripped", p.Stripped, r.Stripped)
+	flag("DWARF", p.DWARF, r.DWARF)
+	flag("race", p.Race, r.Race)
+	flag("trimpath", p.Trimpath, r.Trimpath)
+	flag("cgo", p.Cgo, r.Cgo)
+	if p.Libraries != nil {
+		for _, lib := range r.Libraries {
+			if !matchAny(p.Libraries, lib) {
+				deviations = append(deviations, fmt.Sprintf("library %s is not allowed", lib))
+			}
+		}
+	}
+	return deviations
+}
+
+// matchAny reports whether name matches any of the shell patterns.
+func matchAny(patterns []string, name string) bool {
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+func (r *auditReport) print(w io.Writer) {
+	tw := tabwriter.NewWriter(w, 0, 8, 1, ' ', 0)
+	fmt.Fprintf(tw, "Binary:\t%s\n", r.Binary)
+	fmt.Fprintf(tw, "Go version:\t%s\n", r.GoVersion)
+	fmt.Fprintf(tw, "Format:\t%s\n", r.Format)
+	fmt.Fprintf(tw, "PIE:\t%s\n", yesNo(r.PIE))
+	if r.RELRO != "" {
+		fmt.Fprintf(tw, "RELRO:\t%s\n", r.RELRO)
+	}
+	fmt.Fprintf(tw, "NX:\t%s\n", yesNo(r.NX))
+	fmt.Fprintf(tw, "Stripped:\t%s\n", yesNo(r.Stripped))
+	fmt.Fprintf(tw, "DWARF:\t%s\n", yesNo(r.DWARF))
+	fmt.Fprintf(tw, "Race:\t%s\n", yesNo(r.Race))
+	fmt.Fprintf(tw, "Trimpath:\t%s\n", yesNo(r.Trimpath))
+	fmt.Fprintf(tw, "Cgo:\t%s\n", yesNo(r.Cgo))
+	libs := strings.Join(r.Libraries, ", ")
+	if libs == "" {
+		libs = "none (static)"
+	}
+	fmt.Fprintf(tw, "Libraries:\t%s\n", libs)
+	tw.Flush()
+	if len(r.Deviations) > 0 {
+		fmt.Fprintf(w, "\nPolicy deviations (%d):\n", len(r.Deviations))
+		for _, d := range r.Deviations {
+			fmt.Fprintf(w, "  %s\n", d)
+		}
+	}
+}
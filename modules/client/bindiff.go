@@ -0,0 +1,222 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/wgliang/opengacm/modules/client/internal/objfile"
+)
+
+var (
+	cbindiffOld   = cbindiff.Arg("old", "Older Go binary.").Required().String()
+	cbindiffNew   = cbindiff.Arg("new", "Newer Go binary.").Required().String()
+	cbindiffFuncs = cbindiff.Flag("funcs", "Only compare the functions matching this regular expression.").String()
+)
+
+// funcChange is a function added, removed or changed between two binaries.
+type funcChange struct {
+	Name    string
+	OldSize int64
+	NewSize int64
+}
+
+// depChange is a dependency added, removed or updated between two binaries.
+type depChange struct {
+	Path string
+	Old  string // empty if added
+	New  string // empty if removed
+}
+
+// bindiff prints the dependencies and the functions that differ between
+// two builds of a Go binary. Functions are matched by name and compared by
+// their machine code, with the addresses that only depend on the layout of
+// the binary normalized away.
+func bindiff() error {
+	var filter *regexp.Regexp
+	if *cbindiffFuncs != "" {
+		var err error
+		if filter, err = regexp.Compile(*cbindiffFuncs); err != nil {
+			return err
+		}
+	}
+	oldReport, err := readBuildReport(*cbindiffOld)
+	if err != nil {
+		return err
+	}
+	newReport, err := readBuildReport(*cbindiffNew)
+	if err != nil {
+		return err
+	}
+	added, removed, changed, same, err := diffFuncs(*cbindiffOld, *cbindiffNew, filter)
+	if err != nil {
+		return err
+	}
+
+	w := os.Stdout
+	tw := tabwriter.NewWriter(w, 0, 8, 1, ' ', 0)
+	fmt.Fprintf(tw, "Go version:\t%s\n", versionChange(oldReport.GoVersion, newReport.GoVersion))
+	if oldReport.Main != nil || newReport.Main != nil {
+		fmt.Fprintf(tw, "Main module:\t%s\n", versionChange(moduleVersion(oldReport.Main), moduleVersion(newReport.Main)))
+	}
+	fmt.Fprintf(tw, "Build ID:\t%s\n", versionChange(oldReport.BuildID, newReport.BuildID))
+	tw.Flush()
+
+	deps := diffDeps(oldReport.Deps, newReport.Deps)
+	fmt.Fprintf(w, "\nDependencies (%d changed):\n", len(deps))
+	tw = tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	for _, d := range deps {
+		switch {
+		case d.Old == "":
+			fmt.Fprintf(tw, "+ %s\t%s\n", d.Path, d.New)
+		case d.New == "":
+			fmt.Fprintf(tw, "- %s\t%s\n", d.Path, d.Old)
+		default:
+			fmt.Fprintf(tw, "~ %s\t%s => %s\n", d.Path, d.Old, d.New)
+		}
+	}
+	tw.Flush()
+
+	fmt.Fprintf(w, "\nFunctions: %d added, %d removed, %d changed, %d unchanged\n",
+		len(added), len(removed), len(changed), same)
+	printFuncChanges(w, "Added", added)
+	printFuncChanges(w, "Removed", removed)
+	printFuncChanges(w, "Changed", changed)
+	return nil
+}
+
+func versionChange(base, cur string) string {
+	if base == cur {
+		return base
+	}
+	return base + " => " + cur
+}
+
+// moduleVersion returns the version of m, with its replacement if any.
+func moduleVersion(m *module) string {
+	if m == nil {
+		return ""
+	}
+	s := m.Version
+	if m.Replace != nil {
+		s += " => " + m.Replace.String()
+	}
+	return s
+}
+
+// diffDeps returns the dependencies that differ between base and cur, sorted
+// by path. Replaced dependencies are compared by their replacement.
+func diffDeps(base, cur []*module) []depChange {
+	versions := make(map[string]string)
+	for _, m := range base {
+		versions[m.Path] = moduleVersion(m)
+	}
+	var changes []depChange
+	for _, m := range cur {
+		v := moduleVersion(m)
+		if o, ok := versions[m.Path]; !ok {
+			changes = append(changes, depChange{Path: m.Path, New: v})
+		} else if o != v {
+			changes = append(changes, depChange{Path: m.Path, Old: o, New: v})
+		}
+		delete(versions, m.Path)
+	}
+	for path, v := range versions {
+		changes = append(changes, depChange{Path: path, Old: v})
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes
+}
+
+// diffFuncs compares the functions of the binaries base and cur whose names
+// match filter, if not nil. It returns the functions added, removed and
+// changed, sorted by name, and the number of functions left unchanged.
+func diffFuncs(base, cur string, filter *regexp.Regexp) (added, removed, changed []funcChange, same int, err error) {
+	oldFile, oldDis, err := openDisasm(base)
+	if err != nil {
+		return nil, nil, nil, 0, err
+	}
+	defer oldFile.Close()
+	newFile, newDis, err := openDisasm(cur)
+	if err != nil {
+		return nil, nil, nil, 0, err
+	}
+	defer newFile.Close()
+
+	oldFuncs := funcsByName(oldDis, filter)
+	for name, fn := range funcsByName(newDis, filter) {
+		o, ok := oldFuncs[name]
+		delete(oldFuncs, name)
+		switch {
+		case !ok:
+			added = append(added, funcChange{Name: name, NewSize: fn.Size})
+		case bytes.Equal(oldDis.Code(o), newDis.Code(fn)),
+			o.Size == fn.Size && oldDis.CodeHash(o) == newDis.CodeHash(fn):
+			same++
+		default:
+			changed = append(changed, funcChange{Name: name, OldSize: o.Size, NewSize: fn.Size})
+		}
+	}
+	for name, fn := range oldFuncs {
+		removed = append(removed, funcChange{Name: name, OldSize: fn.Size})
+	}
+	for _, changes := range [][]funcChange{added, removed, changed} {
+		sort.Slice(changes, func(i, j int) bool { return changes[i].Name < changes[j].Name })
+	}
+	return added, removed, changed, same, nil
+}
+
+func openDisasm(path string) (*objfile.File, *objfile.Disasm, error) {
+	f, err := objfile.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	d, err := f.Disasm()
+	if err != nil {
+		f.Close()
+		return nil, nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return f, d, nil
+}
+
+// funcsByName returns the functions of d matching filter, by name. Of the
+// functions sharing a name, such as the ones of C code, the first is kept.
+// The ABI suffix of assembly functions is dropped, since the pclntab of
+// stripped binaries does not record it.
+func funcsByName(d *objfile.Disasm, filter *regexp.Regexp) map[string]objfile.Sym {
+	funcs := make(map[string]objfile.Sym)
+	for _, fn := range d.Funcs() {
+		fn.Name = strings.TrimSuffix(fn.Name, ".abi0")
+		if filter != nil && !filter.MatchString(fn.Name) {
+			continue
+		}
+		if _, ok := funcs[fn.Name]; !ok {
+			funcs[fn.Name] = fn
+		}
+	}
+	return funcs
+}
+
+func printFuncChanges(w io.Writer, title string, changes []funcChange) {
+	if len(changes) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\n%s:\n", title)
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', tabwriter.AlignRight)
+	for _, c := range changes {
+		size := sizeString(c.NewSize)
+		switch {
+		case c.NewSize == 0:
+			size = sizeString(c.OldSize)
+		case c.OldSize != 0 && c.OldSize != c.NewSize:
+			size = fmt.Sprintf("%s => %s", sizeString(c.OldSize), sizeString(c.NewSize))
+		}
+		fmt.Fprintf(tw, "%s\t\t%s\n", size, c.Name)
+	}
+	tw.Flush()
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/wgliang/opengacm/modules/client/internal/objfile"
+	"github.com/wgliang/opengacm/modules/client/signal"
+)
+
+var (
+	cbugreportOutput     = cbugreport.Flag("output", "Archive to write, defaults to a name made of the target and the time.").Short('o').String()
+	cbugreportCPUSeconds = cbugreport.Flag("cpu-seconds", "Duration of the CPU profile in seconds.").Default("5").Uint8()
+)
+
+// manifestEntry describes one item of a diagnostic bundle.
+type manifestEntry struct {
+	Name   string `json:"name"`
+	Size   int    `json:"size,omitempty"`
+	SHA256 string `json:"sha256,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// manifest describes a diagnostic bundle.
+type manifest struct {
+	Target    string          `json:"target"`
+	PID       int             `json:"pid,omitempty"`
+	Collected time.Time       `json:"collected"`
+	Client    string          `json:"client"`
+	Files     []manifestEntry `json:"files"`
+}
+
+// bundle accumulates the files of a diagnostic bundle in memory.
+type bundle struct {
+	manifest manifest
+	files    map[string][]byte
+}
+
+// add records the result of collecting one item. Failed items are kept in
+// the manifest with their error so that the bundle shows what is missing.
+func (b *bundle) add(name string, data []byte, err error) {
+	if err == nil && len(data) == 0 {
+		err = errors.New("no data")
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", name, err)
+		b.manifest.Files = append(b.manifest.Files, manifestEntry{Name: name, Error: err.Error()})
+		return
+	}
+	sum := sha256.Sum256(data)
+	b.manifest.Files = append(b.manifest.Files, manifestEntry{
+		Name:   name,
+		Size:   len(data),
+		SHA256: hex.EncodeToString(sum[:]),
+	})
+	b.files[name] = data
+}
+
+// writeTo writes the bundle as a gzipped tar archive whose files are
+// stored under dir.
+func (b *bundle) writeTo(path, dir string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	gw := gzip.NewWriter(f)
+	tw := tar.NewWriter(gw)
+
+	m, err := json.MarshalIndent(b.manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	write := func(name string, data []byte) error {
+		hdr := &tar.Header{
+			Name:    dir + "/" + name,
+			Mode:    0644,
+			Size:    int64(len(data)),
+			ModTime: b.manifest.Collected,
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		_, err := tw.Write(data)
+		return err
+	}
+	if err := write("manifest.json", m); err != nil {
+		return err
+	}
+	for _, e := range b.manifest.Files {
+		if data, ok := b.files[e.Name]; ok {
+			if err := write(e.Name, data); err != nil {
+				return err
+			}
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := gw.Close(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// bugreport collects everything support usually asks for about a process
+// into a single archive: runtime state and profiles from the agent, the
+// running binary with its build information, and the process information
+// exposed by /proc when the target is a local PID.
+func bugreport(addr net.TCPAddr) error {
+	stamp := time.Now()
+	b := &bundle{
+		manifest: manifest{
+			Target:    target,
+			Collected: stamp,
+			Client:    fmt.Sprintf("%s %s (%s)", Name, Version, Commit),
+		},
+		files: make(map[string][]byte),
+	}
+	if b.manifest.Target == "" {
+		b.manifest.Target = addr.String()
+	}
+
+	out, err := cmd(addr, signal.StackTrace)
+	b.add("goroutines.txt", out, err)
+	out, err = cmd(addr, signal.MemStats)
+	b.add("memstats.txt", out, err)
+	out, err = cmd(addr, signal.Stats)
+	b.add("stats.txt", out, err)
+	out, err = cmd(addr, signal.Version)
+	b.add("goversion.txt", out, err)
+	out, err = cmd(addr, signal.HeapProfile)
+	b.add("heap.pb.gz", out, err)
+
+	fmt.Printf("Profiling CPU now, will take %d secs...\n", *cbugreportCPUSeconds)
+	out, err = cmd(addr, signal.CPUProfileFor, *cbugreportCPUSeconds)
+	b.add("cpu.pb.gz", out, err)
+	fmt.Println("Tracing now, will take 5 secs...")
+	out, err = cmd(addr, signal.Trace)
+	b.add("trace.out", out, err)
+
+	bin, err := cmd(addr, signal.BinaryDump)
+	b.add("binary", bin, err)
+	if err == nil {
+		info, err := readBuildInfo(bin)
+		b.add("buildinfo.txt", info, err)
+	}
+
+	if pid, err := strconv.Atoi(target); err == nil {
+		b.manifest.PID = pid
+		for _, name := range []string{"status", "limits", "maps"} {
+			out, err := ioutil.ReadFile(fmt.Sprintf("/proc/%d/%s", pid, name))
+			b.add("proc/"+name, out, err)
+		}
+	}
+
+	dir := artifactName(agentName(addr), cbugreport.FullCommand(), "", stamp)
+	path := *cbugreportOutput
+	if path == "" {
+		path = dir + ".tar.gz"
+	}
+	if err := b.writeTo(path, dir); err != nil {
+		return err
+	}
+	fmt.Printf("Diagnostic bundle saved to: %s\n", path)
+	return nil
+}
+
+// readBuildInfo returns the build information embedded in a Go binary.
+func readBuildInfo(bin []byte) ([]byte, error) {
+	tmp, err := ioutil.TempFile("", "bugreport")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(bin)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return nil, err
+	}
+	f, err := objfile.Open(tmp.Name())
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.BuildInfo()
+	if err != nil {
+		return nil, err
+	}
+	return []byte(info.String()), nil
+}
@@ -1,8 +1,27 @@
 package main
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/buaazp/fasthttprouter"
+	"github.com/valyala/fasthttp"
+	"github.com/wgliang/opengacm/modules/client/internal/goroutine"
+	"github.com/wgliang/opengacm/modules/client/internal/objfile"
+	"github.com/wgliang/opengacm/modules/client/internal/proc"
+	"github.com/wgliang/opengacm/modules/client/internal/symbols"
 )
 
 func TestClientDaemon(t *testing.T) {
@@ -36,3 +55,348 @@ func TestPprofCPU(t *testing.T) {
 func TestProcesses(t *testing.T) {
 	processes()
 }
+
+func TestArtifactName(t *testing.T) {
+	stamp := time.Date(2017, 9, 1, 13, 4, 5, 0, time.UTC)
+	addr := net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 6060}
+	if got, want := artifactName(addr.String(), "pprof-heap", ".pb.gz", stamp), "127.0.0.1_6060-pprof-heap-20170901T130405.000.pb.gz"; got != want {
+		t.Errorf("artifactName() = %q; want %q", got, want)
+	}
+
+	dir, err := ioutil.TempDir("", "artifacts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defer func(dir string) { outputDir = dir }(outputDir)
+	outputDir = dir
+	first, _, err := saveArtifact([]byte("1"), addr.String(), "pprof-heap", ".pb.gz", stamp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, _, err := saveArtifact([]byte("2"), addr.String(), "pprof-heap", ".pb.gz", stamp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Base(second) != "127.0.0.1_6060-pprof-heap-20170901T130405.000-2.pb.gz" {
+		t.Errorf("second artifact of the same time saved to %s", second)
+	}
+	if b, _ := ioutil.ReadFile(first); string(b) != "1" {
+		t.Errorf("first artifact overwritten with %q", b)
+	}
+}
+
+func TestParseFields(t *testing.T) {
+	fields := parseFields([]byte("alloc: 1.50KB (1536 bytes)\nnum-gc: 3\n"))
+	if got := byteField(fields["alloc"]); got != 1536 {
+		t.Errorf("alloc = %d; want 1536", got)
+	}
+	if fields["num-gc"] != "3" {
+		t.Errorf("num-gc = %q; want 3", fields["num-gc"])
+	}
+}
+
+func TestPrintProcessTree(t *testing.T) {
+	var buf bytes.Buffer
+	printProcessTree(&buf, []goProcess{
+		{PID: 10, PPID: 1, Exe: "server", GoVersion: "go1.10", AgentPort: "4000"},
+		{PID: 11, PPID: 10, Exe: "worker"},
+		{PID: 12, PPID: 10, Exe: "worker", Managed: "jobs"},
+		{PID: 13, PPID: 11, Exe: "helper"},
+		{PID: 20, PPID: 1, Exe: "other", AgentUnreachable: true},
+	})
+	want := `10 server (go1.10) agent:4000
+├─ 11 worker
+│  └─ 13 helper
+└─ 12 worker managed:jobs
+20 other agent:unreachable
+`
+	if buf.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
+
+func TestDiffDeps(t *testing.T) {
+	base := []*module{
+		{Path: "a", Version: "v1.0.0"},
+		{Path: "b", Version: "v1.0.0"},
+		{Path: "c", Version: "v1.0.0"},
+	}
+	cur := []*module{
+		{Path: "a", Version: "v1.0.0"},
+		{Path: "b", Version: "v1.0.0", Replace: &module{Path: "../b"}},
+		{Path: "d", Version: "v0.1.0"},
+	}
+	want := []depChange{
+		{Path: "b", Old: "v1.0.0", New: "v1.0.0 => ../b"},
+		{Path: "c", Old: "v1.0.0"},
+		{Path: "d", New: "v0.1.0"},
+	}
+	got := diffDeps(base, cur)
+	if len(got) != len(want) {
+		t.Fatalf("diffDeps = %v; want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("diffDeps[%d] = %v; want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestLoadOffset(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("no /proc file system")
+	}
+	pid := os.Getpid()
+	f, err := objfile.Open(proc.Path(pid, "exe"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	offset, err := loadOffset(pid, f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tab, err := f.PCLineTable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pc := reflect.ValueOf(TestLoadOffset).Pointer()
+	if _, _, fn := tab.PCToLine(uint64(pc) - offset); fn == nil || !strings.HasSuffix(fn.Name, ".TestLoadOffset") {
+		t.Errorf("PCToLine(%#x - %#x) = %v; want TestLoadOffset", pc, offset, fn)
+	}
+	for _, s := range []string{"0x4a2f", "4a2f", "0X4A2F"} {
+		if addr, err := parseAddr(s); addr != 0x4a2f || err != nil {
+			t.Errorf("parseAddr(%s) = %#x, %v", s, addr, err)
+		}
+	}
+}
+
+func TestAuditPolicy(t *testing.T) {
+	var p auditPolicy
+	const policy = `
+pie = true
+relro = "full"
+race = false
+libraries = ["libc.so.*"]
+`
+	if _, err := toml.Decode(policy, &p); err != nil {
+		t.Fatal(err)
+	}
+	r := &auditReport{PIE: true, RELRO: "full", Libraries: []string{"libc.so.6"}}
+	if d := p.check(r); len(d) != 0 {
+		t.Errorf("check(compliant) = %q", d)
+	}
+	r = &auditReport{PIE: false, RELRO: "partial", Race: true, Stripped: true, Libraries: []string{"libc.so.6", "libssl.so.3"}}
+	want := []string{
+		"PIE is no, policy requires yes",
+		"RELRO is partial, policy requires full",
+		"race is yes, policy requires no",
+		"library libssl.so.3 is not allowed",
+	}
+	if d := p.check(r); strings.Join(d, "\n") != strings.Join(want, "\n") {
+		t.Errorf("check = %q; want %q", d, want)
+	}
+	// RELRO does not apply to Mach-O and PE binaries.
+	if d := p.check(&auditReport{PIE: true}); len(d) != 0 {
+		t.Errorf("check(no RELRO) = %q", d)
+	}
+}
+
+// mainArgsEnv holds the arguments to run the client with when the test
+// binary is started by runClient.
+const mainArgsEnv = "OPENGACM_CLIENT_ARGS"
+
+func TestMain(m *testing.M) {
+	if args := os.Getenv(mainArgsEnv); args != "" {
+		os.Args = append(os.Args[:1], strings.Split(args, "\n")...)
+		main()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// runClient runs the client with args in a new process and returns its
+// output and exit status.
+func runClient(t *testing.T, args ...string) (string, int) {
+	c := exec.Command(os.Args[0])
+	c.Env = append(os.Environ(), mainArgsEnv+"="+strings.Join(args, "\n"))
+	out, err := c.CombinedOutput()
+	if err, ok := err.(*exec.ExitError); ok {
+		return string(out), err.ExitCode()
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(out), 0
+}
+
+func TestAuditExit(t *testing.T) {
+	r, err := readAuditReport(os.Args[0])
+	if err != nil {
+		t.Skip(err)
+	}
+	dir, err := ioutil.TempDir("", "audit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for _, race := range []bool{r.Race, !r.Race} {
+		policy := filepath.Join(dir, "policy.toml")
+		if err := ioutil.WriteFile(policy, []byte(fmt.Sprintf("race = %v\n", race)), 0644); err != nil {
+			t.Fatal(err)
+		}
+		out, code := runClient(t, "audit-binary", "--policy", policy, os.Args[0])
+		if want := map[bool]int{true: 0, false: 1}[race == r.Race]; code != want {
+			t.Errorf("audit-binary with race = %v exited with %d; want %d\n%s", race, code, want, out)
+		}
+	}
+}
+
+func TestVulncheckExit(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vulncheck")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	// Every version of the standard library, which the test binary links,
+	// or none of a module it doesn't.
+	const entry = `{"id": "GO-0000-0001", "affected": [{
+		"package": {"name": %q, "ecosystem": "Go"},
+		"ranges": [{"type": "SEMVER", "events": [{"introduced": "0"}]}]
+	}]}`
+	for module, want := range map[string]int{"stdlib": 1, "example.com/none": 0} {
+		db := filepath.Join(dir, module)
+		if err := os.MkdirAll(db, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(db, "GO-0000-0001.json"), []byte(fmt.Sprintf(entry, module)), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if out, code := runClient(t, "vulncheck", "--db", db, os.Args[0]); code != want {
+			t.Errorf("vulncheck with an entry of %s exited with %d; want %d\n%s", module, code, want, out)
+		}
+	}
+}
+
+func TestSymbolServer(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("no /proc file system")
+	}
+	dir, err := ioutil.TempDir("", "symbols")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	store, err := symbols.Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cd := &ClientDaemon{Symbols: store}
+	r := fasthttprouter.New()
+	cd.RegHandler(r)
+	do := func(method, uri, body string) (int, string) {
+		var ctx fasthttp.RequestCtx
+		ctx.Request.Header.SetMethod(method)
+		ctx.Request.SetRequestURI(uri)
+		ctx.Request.SetBodyString(body)
+		r.Handler(&ctx)
+		return ctx.Response.StatusCode(), string(ctx.Response.Body())
+	}
+
+	exe, err := ioutil.ReadFile(os.Args[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, body := do("POST", "/gacmclient/symbols?name=test", string(exe))
+	var b symbols.Binary
+	if err := json.Unmarshal([]byte(body), &b); code != 200 || err != nil || b.BuildID == "" {
+		t.Fatalf("upload: %d %s", code, body)
+	}
+	if code, body := do("GET", "/gacmclient/symbols", ""); code != 200 || !strings.Contains(body, b.BuildID) {
+		t.Errorf("list: %d %s", code, body)
+	}
+	if code, _ := do("GET", "/gacmclient/symbols/"+b.BuildID, ""); code != 200 {
+		t.Errorf("download: %d", code)
+	}
+	if code, _ := do("GET", "/gacmclient/symbols/unknown", ""); code != 404 {
+		t.Errorf("download of an unknown build ID: %d", code)
+	}
+	// The daemon only fetches the binaries of local agents.
+	if code, body := do("POST", "/gacmclient/symbols?agent=example.com:80", ""); code != 400 {
+		t.Errorf("upload from a remote agent: %d %s", code, body)
+	}
+
+	pc := uint64(reflect.ValueOf(TestSymbolServer).Pointer())
+	maps, err := proc.Maps(os.Getpid())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range maps {
+		if m.Start <= pc && pc < m.End {
+			uri := fmt.Sprintf("/gacmclient/symbolize/%s?start=%#x&offset=%#x", b.GNUBuildID, m.Start, m.Offset)
+			// Only the address in the binary is answered.
+			code, body := do("POST", uri, fmt.Sprintf("%#x+0x1", pc+1))
+			if want := fmt.Sprintf("%#x ", pc+1); code != 200 || !strings.HasPrefix(body, want) || !strings.HasSuffix(body, ".TestSymbolServer\n") {
+				t.Errorf("symbolize: %d %q", code, body)
+			}
+		}
+	}
+}
+
+func TestRequestConfig(t *testing.T) {
+	for _, tt := range []struct {
+		method, uri string
+		want        int
+	}{
+		{"POST", "/gacmclient/symbols?name=server", maxBinarySize},
+		{"POST", "/gacmclient/symbols", maxBinarySize},
+		{"GET", "/gacmclient/symbols", fasthttp.DefaultMaxRequestBodySize},
+		{"POST", "/gacmclient/symbolize/abc", fasthttp.DefaultMaxRequestBodySize},
+		{"POST", "/gacmclient/deploy", fasthttp.DefaultMaxRequestBodySize},
+	} {
+		var h fasthttp.RequestHeader
+		h.SetMethod(tt.method)
+		h.SetRequestURI(tt.uri)
+		if got := requestConfig(&h).MaxRequestBodySize; got != tt.want {
+			t.Errorf("%s %s: MaxRequestBodySize = %d; want %d", tt.method, tt.uri, got, tt.want)
+		}
+	}
+}
+
+func TestReadBuildInfo(t *testing.T) {
+	bin, err := ioutil.ReadFile(os.Args[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := readBuildInfo(bin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "go\t" + runtime.Version() + "\n"; !strings.HasPrefix(string(info), want) {
+		t.Errorf("readBuildInfo = %q; want it to start with %q", info, want)
+	}
+	if _, err := readBuildInfo([]byte("#!/bin/sh\n")); err == nil {
+		t.Error("readBuildInfo of a script succeeded")
+	}
+}
+
+func TestRenderDetail(t *testing.T) {
+	v := &topView{}
+	r := &topRow{proc: goProcess{PID: 10, Exe: "server", Agent: true}}
+	render := func() string {
+		var buf bytes.Buffer
+		v.renderDetail(&buf, r)
+		return buf.String()
+	}
+	// The view doesn't wait for the agent.
+	if out := render(); !strings.Contains(out, "Reading from the agent") {
+		t.Errorf("detail before the agent answered:\n%s", out)
+	}
+	r.detail = &topDetail{pid: 10, memStats: []byte("alloc: 1KB\n"), goroutines: 3, groups: []*goroutine.Group{
+		{Count: 3, Frames: []goroutine.Frame{{Func: "main.worker"}}},
+	}}
+	if out := render(); !strings.Contains(out, "alloc: 1KB") || !strings.Contains(out, "3 in 1 groups") || !strings.Contains(out, "main.worker") {
+		t.Errorf("detail:\n%s", out)
+	}
+}
@@ -25,6 +25,7 @@ type ApplicationContainer interface {
 	NotifyStopped()
 	SetStatus(status string)
 	GetPid() int
+	GetCmd() string
 	GetStatus() *ApplicationStatus
 	Watch() (*os.ProcessState, error)
 	release()
@@ -175,6 +176,11 @@ func (application *Application) GetPid() int {
 	return application.Pid
 }
 
+// Return the path of the binary the application runs
+func (application *Application) GetCmd() string {
+	return application.Cmd
+}
+
 // Return application current status
 func (application *Application) GetStatus() *ApplicationStatus {
 	return application.Status
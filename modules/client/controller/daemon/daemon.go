@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"os"
 	"path"
+	"sort"
 	"sync"
 	"time"
 
@@ -18,6 +19,9 @@ import (
 	"github.com/wgliang/opengacm/modules/client/controller/preparable"
 	"github.com/wgliang/opengacm/modules/client/controller/utils"
 	"github.com/wgliang/opengacm/modules/client/controller/watcher"
+	"github.com/wgliang/opengacm/modules/client/internal/proc"
+	"github.com/wgliang/opengacm/modules/client/internal/symbols"
+	"github.com/wgliang/opengacm/modules/client/internal/vuln"
 )
 
 // Daemon is the main module that keeps everything in place and execute
@@ -31,6 +35,8 @@ type Daemon struct {
 	ErrFile   string           // ErrFile is the APM err log file path.
 	Watcher   *watcher.Watcher // Watcher is a watcher instance.
 
+	Symbols *symbols.Store `toml:"-"` // Symbols stores the binaries of the applications by build ID, nil if it couldn't be opened.
+
 	Applications map[string]application.ApplicationContainer // Applications is a map containing all procs started on APM.
 }
 
@@ -79,6 +85,10 @@ func InitMaster(configFile string) *Daemon {
 		daemon.SysFolder = path.Dir(configFile) + "/"
 	}
 	daemon.Watcher = watcher
+	daemon.Symbols, err = symbols.Open(path.Join(daemon.SysFolder, "symbols"))
+	if err != nil {
+		log.Warnf("Symbol store disabled due to %s.", err)
+	}
 	daemon.Revive()
 	log.Infof("All applications revived...")
 	go daemon.WatchApplications()
@@ -142,6 +152,7 @@ func (daemon *Daemon) RunPreparable(applicationPreparable preparable.ProcPrepara
 	daemon.saveApplicationsWrapper()
 	daemon.Watcher.AddApplicationWatcher(application)
 	application.SetStatus("running")
+	daemon.addSymbols(application)
 	return nil
 }
 
@@ -154,6 +165,68 @@ func (daemon *Daemon) ListApplications() []application.ApplicationContainer {
 	return procsList
 }
 
+// VulncheckResult is the result of checking the binary of an application
+// against a vulnerability database.
+type VulncheckResult struct {
+	Name   string       `json:"name"`             // Name is the application name.
+	Result *vuln.Result `json:"result,omitempty"` // Result is nil if the binary couldn't be checked.
+	Error  string       `json:"error,omitempty"`  // Error is the reason the binary couldn't be checked.
+}
+
+// Vulncheck will check the binaries of all applications against the OSV
+// database in the directory dbDir.
+func (daemon *Daemon) Vulncheck(dbDir string) ([]*VulncheckResult, error) {
+	db, err := vuln.Load(dbDir)
+	if err != nil {
+		return nil, err
+	}
+	daemon.Lock()
+	binaries := make(map[string]string)
+	for _, application := range daemon.ListApplications() {
+		binaries[application.Identifier()] = binaryPath(application)
+	}
+	daemon.Unlock()
+
+	results := []*VulncheckResult{}
+	for name, binary := range binaries {
+		result := &VulncheckResult{Name: name}
+		if r, err := db.CheckBinary(binary); err != nil {
+			result.Error = err.Error()
+		} else {
+			result.Result = r
+		}
+		results = append(results, result)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Name < results[j].Name
+	})
+	return results, nil
+}
+
+// binaryPath returns the path of the binary of application. The binary of a
+// running application is read through its process, so that a binary replaced
+// since it was started isn't mistaken for the one running.
+func binaryPath(application application.ApplicationContainer) string {
+	if application.IsAlive() {
+		return proc.Path(application.GetPid(), "exe")
+	}
+	return application.GetCmd()
+}
+
+// addSymbols will add the binary of application to the symbol store in the
+// background, so that its profiles can still be symbolized once it was rebuilt.
+func (daemon *Daemon) addSymbols(application application.ApplicationContainer) {
+	if daemon.Symbols == nil {
+		return
+	}
+	name, binary := application.Identifier(), binaryPath(application)
+	go func() {
+		if _, err := daemon.Symbols.AddFile(name, binary); err != nil {
+			log.Warnf("Could not add the binary of application %s to the symbol store due to %s.", name, err)
+		}
+	}()
+}
+
 // RestartProcess will restart a application.
 func (daemon *Daemon) RestartApplications(name string) error {
 	err := daemon.StopApplications(name)
@@ -234,6 +307,7 @@ func (daemon *Daemon) start(application application.ApplicationContainer) error
 		}
 		daemon.Watcher.AddApplicationWatcher(application)
 		application.SetStatus("running")
+		daemon.addSymbols(application)
 	}
 	return nil
 }
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/wgliang/opengacm/modules/client/controller/application"
+	"github.com/wgliang/opengacm/modules/client/internal/symbols"
 )
 
 // RemoteDaemon is a struct that holds the daemon instance.
@@ -109,6 +110,18 @@ func (rd *RemoteDaemon) MonitStatus(req string, response *ApplicationResponse) e
 	return nil
 }
 
+// Vulncheck will check the binaries of all applications against the vulnerability database
+// in the directory dbDir of the daemon's host and bind the results to response.
+// It returns an error in case there's any.
+func (rd *RemoteDaemon) Vulncheck(dbDir string, response *[]*VulncheckResult) error {
+	results, err := rd.daemon.Vulncheck(dbDir)
+	if err != nil {
+		return err
+	}
+	*response = results
+	return nil
+}
+
 // DeleteProcess will delete a application with name applicationName.
 // It returns an error in case there's any.
 func (rd *RemoteDaemon) DeleteApplications(applicationName string, ack *bool) error {
@@ -116,6 +129,12 @@ func (rd *RemoteDaemon) DeleteApplications(applicationName string, ack *bool) er
 	return rd.daemon.DeleteApplications(applicationName)
 }
 
+// Symbols returns the symbol store of the daemon, nil if it couldn't be opened.
+// It is served over HTTP rather than RPC.
+func (rd *RemoteDaemon) Symbols() *symbols.Store {
+	return rd.daemon.Symbols
+}
+
 // Stop will stop APM remote server.
 // It returns an error in case there's any.
 func (rd *RemoteDaemon) Stop() error {
@@ -204,6 +223,23 @@ func (client *RemoteClient) DeleteApplications(applicationName string) error {
 // It returns a tuple with a list of application and an error in case there's any.
 func (client *RemoteClient) MonitStatus() (ApplicationResponse, error) {
 	var response *ApplicationResponse
-	err := client.conn.Call("RemoteDaemon.MonitStatus", "", &response)
-	return *response, err
+	if err := client.conn.Call("RemoteDaemon.MonitStatus", "", &response); err != nil {
+		return ApplicationResponse{}, err
+	}
+	return *response, nil
+}
+
+// Vulncheck is a wrapper that calls the remote Vulncheck.
+// It returns the results of each application and an error in case there's any.
+func (client *RemoteClient) Vulncheck(dbDir string) ([]*VulncheckResult, error) {
+	var response []*VulncheckResult
+	if err := client.conn.Call("RemoteDaemon.Vulncheck", dbDir, &response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// Close closes the connection to the remote server.
+func (client *RemoteClient) Close() error {
+	return client.conn.Close()
 }
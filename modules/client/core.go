@@ -0,0 +1,124 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"text/tabwriter"
+	"time"
+
+	"github.com/wgliang/opengacm/modules/client/internal/core"
+	"github.com/wgliang/opengacm/modules/client/internal/goroutine"
+)
+
+var (
+	ccoreBinary = ccore.Arg("binary", "Go binary that the crashed process ran.").Required().String()
+	ccoreFile   = ccore.Arg("corefile", "ELF core dump of the process, such as written with GOTRACEBACK=crash.").Required().String()
+	ccoreGroup  = ccore.Flag("group", "Group the goroutines by identical stacks.").Bool()
+	ccoreJSON   = ccore.Flag("json", "Print the heap statistics and the goroutines as JSON.").Bool()
+	ccoreFunc   = ccore.Flag("func", "Only show goroutines with a function matching the regexp.").String()
+	ccoreState  = ccore.Flag("state", `Only show goroutines whose state contains the string, e.g. "chan receive".`).String()
+)
+
+// coreDump prints the heap statistics and the goroutines of a crashed Go
+// process from its core dump.
+func coreDump() error {
+	p, err := core.Open(*ccoreBinary, *ccoreFile)
+	if err != nil {
+		return err
+	}
+	defer p.Close()
+	gs, err := p.Goroutines()
+	if err != nil {
+		return err
+	}
+	filter := &goroutine.Filter{State: *ccoreState}
+	if *ccoreFunc != "" {
+		re, err := regexp.Compile(*ccoreFunc)
+		if err != nil {
+			return fmt.Errorf("invalid function filter: %v", err)
+		}
+		filter.Func = re
+	}
+	total := len(gs)
+	gs = filter.Apply(gs)
+	heap := p.Heap()
+
+	if *ccoreJSON {
+		out := struct {
+			Threads    int                    `json:"threads"`
+			Heap       *core.Heap             `json:"heap"`
+			Goroutines []*goroutine.Goroutine `json:"goroutines,omitempty"`
+			Groups     []*goroutine.Group     `json:"groups,omitempty"`
+		}{Threads: len(p.Threads), Heap: heap}
+		if *ccoreGroup {
+			out.Groups = goroutine.GroupBy(gs)
+		} else {
+			out.Goroutines = gs
+		}
+		return printJSON(out)
+	}
+	fmt.Printf("%d threads, %d goroutines\n\n", len(p.Threads), total)
+	printHeap(os.Stdout, heap)
+	fmt.Println()
+	if *ccoreGroup {
+		goroutine.Print(os.Stdout, goroutine.GroupBy(gs))
+		return nil
+	}
+	for i, g := range gs {
+		if i > 0 {
+			fmt.Println()
+		}
+		printGoroutine(os.Stdout, g)
+	}
+	return nil
+}
+
+func printHeap(w io.Writer, h *core.Heap) {
+	tw := tabwriter.NewWriter(w, 0, 8, 1, ' ', 0)
+	fmt.Fprintln(tw, "Heap:")
+	for _, f := range []struct {
+		name string
+		n    uint64
+	}{
+		{"in use", h.InUse},
+		{"free", h.Free},
+		{"released", h.Released},
+		{"live", h.Live},
+		{"marked by last GC", h.Marked},
+		{"next GC goal", h.Goal},
+		{"allocated in total", h.TotalAlloc},
+		{"freed in total", h.TotalFree},
+	} {
+		if f.n != 0 {
+			fmt.Fprintf(tw, "  %s:\t%s\n", f.name, sizeString(int64(f.n)))
+		}
+	}
+	fmt.Fprintf(tw, "  GC cycles:\t%d\n", h.NumGC)
+	if h.NumGC > 0 {
+		fmt.Fprintf(tw, "  GC pauses:\t%v\n", h.PauseTotal)
+		fmt.Fprintf(tw, "  last GC:\t%s\n", h.LastGC.Format(time.RFC3339))
+	}
+	tw.Flush()
+}
+
+func printGoroutine(w io.Writer, g *goroutine.Goroutine) {
+	state := g.State
+	if g.Locked {
+		state += ", locked to thread"
+	}
+	fmt.Fprintf(w, "goroutine %d [%s]:\n", g.ID, state)
+	if len(g.Frames) == 0 {
+		fmt.Fprintln(w, "    (stack unavailable)")
+	}
+	for _, f := range g.Frames {
+		fmt.Fprintf(w, "    %s\n", f.Func)
+		if f.File != "" {
+			fmt.Fprintf(w, "        %s:%d\n", f.File, f.Line)
+		}
+	}
+	if g.CreatedBy != nil {
+		fmt.Fprintf(w, "    created by %s\n        %s:%d\n", g.CreatedBy.Func, g.CreatedBy.File, g.CreatedBy.Line)
+	}
+}
@@ -1,7 +1,6 @@
 package main
 
 import (
-	"bytes"
 	"errors"
 	"fmt"
 	"io"
@@ -9,18 +8,31 @@ import (
 	"net"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strconv"
 	"strings"
-	"sync"
+	"time"
 
-	"github.com/wgliang/opengacm/modules/client/internal"
-	"github.com/wgliang/opengacm/modules/client/internal/objfile"
+	"github.com/wgliang/opengacm/modules/client/internal/discovery"
 	"github.com/wgliang/opengacm/modules/client/signal"
 
-	log "github.com/Sirupsen/logrus"
-	ps "github.com/keybase/go-ps"
+	"gopkg.in/alecthomas/kingpin.v2"
 )
 
+var (
+	// outputDir is the directory captured profiles, traces and binaries are kept in.
+	outputDir string
+	// noLaunch disables launching "go tool pprof" or "go tool trace" on captured data.
+	noLaunch bool
+)
+
+func init() {
+	for _, c := range []*kingpin.CmdClause{cpprofHeap, cpprofCPU, ctrace, csample} {
+		c.Flag("output-dir", "Keep captured files in the directory.").Short('o').StringVar(&outputDir)
+		c.Flag("no-launch", "Only capture, do not launch the Go tool.").BoolVar(&noLaunch)
+	}
+}
+
 func command(args []string, fn func(addr net.TCPAddr) error) {
 	if len(args) < 3 {
 		usage("missing PID or address")
@@ -36,10 +48,6 @@ func command(args []string, fn func(addr net.TCPAddr) error) {
 		return
 	}
 }
-func stackTrace(addr net.TCPAddr) error {
-	return cmdWithPrint(addr, signal.StackTrace)
-}
-
 func gc(addr net.TCPAddr) error {
 	_, err := cmd(addr, signal.GC)
 	return err
@@ -54,16 +62,17 @@ func goVersion(addr net.TCPAddr) error {
 }
 
 func pprofHeap(addr net.TCPAddr) error {
-	return pprof(addr, signal.HeapProfile)
+	return pprof(addr, signal.HeapProfile, cpprofHeap.FullCommand())
 }
 
 func pprofCPU(addr net.TCPAddr) error {
 	fmt.Println("Profiling CPU now, will take 30 secs...")
-	return pprof(addr, signal.CPUProfile)
+	return pprof(addr, signal.CPUProfile, cpprofCPU.FullCommand())
 }
 
 func trace(addr net.TCPAddr) error {
 	fmt.Println("Tracing now, will take 5 secs...")
+	stamp := time.Now()
 	out, err := cmd(addr, signal.Trace)
 	if err != nil {
 		return err
@@ -71,16 +80,16 @@ func trace(addr net.TCPAddr) error {
 	if len(out) == 0 {
 		return errors.New("nothing has traced")
 	}
-	tmpfile, err := ioutil.TempFile("", "trace")
+	traceFile, cleanup, err := saveArtifact(out, agentName(addr), ctrace.FullCommand(), ".out", stamp)
 	if err != nil {
 		return err
 	}
-	defer os.Remove(tmpfile.Name())
-	if err := ioutil.WriteFile(tmpfile.Name(), out, 0); err != nil {
-		return err
+	defer cleanup()
+	fmt.Printf("Trace dump saved to: %s\n", traceFile)
+	if noLaunch {
+		return nil
 	}
-	fmt.Printf("Trace dump saved to: %s\n", tmpfile.Name())
-	cmd := exec.Command("go", "tool", "trace", tmpfile.Name())
+	cmd := exec.Command("go", "tool", "trace", traceFile)
 	cmd.Env = os.Environ()
 	cmd.Stdin = os.Stdin
 	cmd.Stdout = os.Stdout
@@ -88,45 +97,39 @@ func trace(addr net.TCPAddr) error {
 	return cmd.Run()
 }
 
-func pprof(addr net.TCPAddr, p byte) error {
-	tmpDumpFile, err := ioutil.TempFile("", "profile")
+func pprof(addr net.TCPAddr, p byte, name string) error {
+	stamp := time.Now()
+	out, err := cmd(addr, p)
 	if err != nil {
 		return err
 	}
-	{
-		out, err := cmd(addr, p)
-		if err != nil {
-			return err
-		}
-		if len(out) == 0 {
-			return errors.New("failed to read the profile")
-		}
-		defer os.Remove(tmpDumpFile.Name())
-		if err := ioutil.WriteFile(tmpDumpFile.Name(), out, 0); err != nil {
-			return err
-		}
+	if len(out) == 0 {
+		return errors.New("failed to read the profile")
+	}
+	dumpFile, cleanup, err := saveArtifact(out, agentName(addr), name, ".pb.gz", stamp)
+	if err != nil {
+		return err
 	}
+	defer cleanup()
 	// Download running binary
-	tmpBinFile, err := ioutil.TempFile("", "binary")
+	out, err = cmd(addr, signal.BinaryDump)
+	if err != nil {
+		return fmt.Errorf("failed to read the binary: %v", err)
+	}
+	if len(out) == 0 {
+		return errors.New("failed to read the binary")
+	}
+	binFile, cleanup, err := saveArtifact(out, agentName(addr), name, ".bin", stamp)
 	if err != nil {
 		return err
 	}
-	{
-		out, err := cmd(addr, signal.BinaryDump)
-		if err != nil {
-			return fmt.Errorf("failed to read the binary: %v", err)
-		}
-		if len(out) == 0 {
-			return errors.New("failed to read the binary")
-		}
-		defer os.Remove(tmpBinFile.Name())
-		if err := ioutil.WriteFile(tmpBinFile.Name(), out, 0); err != nil {
-			return err
-		}
+	defer cleanup()
+	fmt.Printf("Profiling dump saved to: %s\n", dumpFile)
+	fmt.Printf("Binary file saved to: %s\n", binFile)
+	if noLaunch {
+		return nil
 	}
-	fmt.Printf("Profiling dump saved to: %s\n", tmpDumpFile.Name())
-	fmt.Printf("Binary file saved to: %s\n", tmpBinFile.Name())
-	cmd := exec.Command("go", "tool", "pprof", tmpBinFile.Name(), tmpDumpFile.Name())
+	cmd := exec.Command("go", "tool", "pprof", binFile, dumpFile)
 	cmd.Env = os.Environ()
 	cmd.Stdin = os.Stdin
 	cmd.Stdout = os.Stdout
@@ -134,6 +137,75 @@ func pprof(addr net.TCPAddr, p byte) error {
 	return cmd.Run()
 }
 
+// agentName returns the name of the agent at addr in the names of the
+// artifacts: the target as given on the command line, or the address.
+func agentName(addr net.TCPAddr) string {
+	if target != "" {
+		return target
+	}
+	return addr.String()
+}
+
+// artifactName returns the file name of an artifact captured from source,
+// the agent or process it is about. It is made of the source, the command
+// and the capture time to the millisecond, so that repeated captures sort
+// in time order.
+func artifactName(source, name, ext string, stamp time.Time) string {
+	t := strings.NewReplacer(":", "_", "/", "_", "[", "", "]", "").Replace(source)
+	return fmt.Sprintf("%s-%s-%s%s", t, name, stamp.Format("20060102T150405.000"), ext)
+}
+
+// saveArtifact writes data captured by the command name. Artifacts are kept
+// in the output directory if one is given, or in the working directory if
+// the Go tool is not launched; otherwise they are written to temporary
+// files that are removed by the returned cleanup function. An artifact
+// never overwrites another one: a number is appended to its name if the
+// file exists.
+func saveArtifact(data []byte, source, name, ext string, stamp time.Time) (string, func(), error) {
+	if outputDir == "" && !noLaunch {
+		f, err := ioutil.TempFile("", name)
+		if err != nil {
+			return "", nil, err
+		}
+		defer f.Close()
+		if _, err := f.Write(data); err != nil {
+			os.Remove(f.Name())
+			return "", nil, err
+		}
+		return f.Name(), func() { os.Remove(f.Name()) }, nil
+	}
+	dir := outputDir
+	if dir == "" {
+		dir = "."
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", nil, err
+	}
+	base := strings.TrimSuffix(artifactName(source, name, ext, stamp), ext)
+	for n := 1; ; n++ {
+		path := filepath.Join(dir, base+ext)
+		if n > 1 {
+			path = filepath.Join(dir, fmt.Sprintf("%s-%d%s", base, n, ext))
+		}
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if os.IsExist(err) {
+			continue
+		}
+		if err != nil {
+			return "", nil, err
+		}
+		_, err = f.Write(data)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			os.Remove(path)
+			return "", nil, err
+		}
+		return path, func() {}, nil
+	}
+}
+
 func stats(addr net.TCPAddr) error {
 	return cmdWithPrint(addr, signal.Stats)
 }
@@ -164,13 +236,19 @@ func targetToAddr(target string) (*net.TCPAddr, error) {
 	if err != nil {
 		return nil, fmt.Errorf("couldn't parse PID: %v", err)
 	}
-	port, err := internal.GetPort(pid)
-	addr, _ := net.ResolveTCPAddr("tcp", "127.0.0.1:"+port)
+	port, err := discovery.AgentPort(pid)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get the agent port of PID %d: %v", pid, err)
+	}
+	addr, err := net.ResolveTCPAddr("tcp", "127.0.0.1:"+port)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse dst address: %v", err)
+	}
 	return addr, nil
 }
 
-func cmd(addr net.TCPAddr, c byte) ([]byte, error) {
-	conn, err := cmdLazy(addr, c)
+func cmd(addr net.TCPAddr, c byte, params ...byte) ([]byte, error) {
+	conn, err := cmdLazy(addr, c, params...)
 	if err != nil {
 		return nil, fmt.Errorf("couldn't get port by PID: %v", err)
 	}
@@ -182,86 +260,17 @@ func cmd(addr net.TCPAddr, c byte) ([]byte, error) {
 	return all, nil
 }
 
-func cmdLazy(addr net.TCPAddr, c byte) (io.Reader, error) {
+func cmdLazy(addr net.TCPAddr, c byte, params ...byte) (io.Reader, error) {
 	conn, err := net.DialTCP("tcp", nil, &addr)
 	if err != nil {
 		return nil, err
 	}
-	if _, err := conn.Write([]byte{c}); err != nil {
+	if _, err := conn.Write(append([]byte{c}, params...)); err != nil {
 		return nil, err
 	}
 	return conn, nil
 }
 
-func processes() {
-	pss, err := ps.Processes()
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	var wg sync.WaitGroup
-	wg.Add(len(pss))
-
-	for _, pr := range pss {
-		pr := pr
-		go func() {
-			defer wg.Done()
-
-			printIfGo(pr)
-		}()
-	}
-	wg.Wait()
-}
-
-// printIfGo looks up the runtime.buildVersion symbol
-// in the process' binary and determines if the process
-// if a Go process or not. If the process is a Go process,
-// it reports PID, binary name and full path of the binary.
-func printIfGo(pr ps.Process) {
-	if pr.Pid() == 0 {
-		// ignore system process
-		return
-	}
-	path, err := pr.Path()
-	if err != nil {
-		return
-	}
-	obj, err := objfile.Open(path)
-	if err != nil {
-		return
-	}
-	defer obj.Close()
-
-	symbols, err := obj.Symbols()
-	if err != nil {
-		return
-	}
-
-	var ok bool
-	for _, s := range symbols {
-		if s.Name == "runtime.buildVersion" {
-			ok = true
-		}
-	}
-
-	var agent bool
-	pidfile, err := internal.PIDFile(pr.Pid())
-	if err == nil {
-		_, err := os.Stat(pidfile)
-		agent = err == nil
-	}
-
-	if ok {
-		buf := bytes.NewBuffer(nil)
-		fmt.Fprintf(buf, "%d", pr.Pid())
-		if agent {
-			fmt.Fprint(buf, "*")
-		}
-		fmt.Fprintf(buf, "\t%v\t(%v)\n", pr.Executable(), path)
-		buf.WriteTo(os.Stdout)
-	}
-}
-
 func usage(msg string) {
 	if msg != "" {
 		fmt.Printf("gops: %v\n", msg)
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"go/build"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+
+	"github.com/wgliang/opengacm/modules/client/internal/objfile"
+	"github.com/wgliang/opengacm/modules/client/signal"
+)
+
+var (
+	cdisasmTarget    = cdisasm.Arg("target", "Go binary, or PID or host:port of an agent to fetch the binary from.").Required().String()
+	cdisasmFunc      = cdisasm.Flag("func", "Only disassemble the functions matching this regular expression.").Short('s').String()
+	cdisasmSource    = cdisasm.Flag("source", "Interleave the instructions with their source lines, when the sources are available.").Short('S').Bool()
+	cdisasmSourceDir = cdisasm.Flag("source-dir", "Directory to look the sources of -trimpath binaries up in, may be repeated.").Strings()
+)
+
+// disasm prints the annotated assembly of the functions of a Go binary,
+// either a local file or the binary of a running process.
+func disasm() error {
+	var filter *regexp.Regexp
+	if *cdisasmFunc != "" {
+		var err error
+		if filter, err = regexp.Compile(*cdisasmFunc); err != nil {
+			return err
+		}
+	}
+	path, cleanup, err := fetchBinary(*cdisasmTarget)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	f, d, err := openDisasm(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	var src *objfile.SourceCache
+	if *cdisasmSource {
+		src = objfile.NewSourceCache(sourceDirs(*cdisasmSourceDir)...)
+	}
+	d.Print(os.Stdout, filter, 0, ^uint64(0), src)
+	return nil
+}
+
+// fetchBinary returns the path of the binary named by target. A target that
+// is not a file is the PID or address of an agent, the binary is then
+// fetched from the agent into a temporary file removed by cleanup. Without
+// an agent, the binary of a local process is read from /proc.
+func fetchBinary(target string) (path string, cleanup func(), err error) {
+	cleanup = func() {}
+	if _, err := os.Stat(target); err == nil {
+		return target, cleanup, nil
+	}
+	bin, err := dumpBinary(target)
+	if err != nil {
+		if _, perr := strconv.Atoi(target); perr == nil {
+			if path, perr := binaryPath(target); perr == nil {
+				return path, cleanup, nil
+			}
+		}
+		return "", nil, err
+	}
+	tmp, err := ioutil.TempFile("", "opengacm-binary")
+	if err != nil {
+		return "", nil, err
+	}
+	defer tmp.Close()
+	if _, err := tmp.Write(bin); err != nil {
+		os.Remove(tmp.Name())
+		return "", nil, err
+	}
+	return tmp.Name(), func() { os.Remove(tmp.Name()) }, nil
+}
+
+// dumpBinary fetches the binary of the agent at target.
+func dumpBinary(target string) ([]byte, error) {
+	addr, err := targetToAddr(target)
+	if err != nil {
+		return nil, err
+	}
+	bin, err := cmd(*addr, signal.BinaryDump)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the binary: %v", err)
+	}
+	if len(bin) == 0 {
+		return nil, errors.New("failed to read the binary")
+	}
+	return bin, nil
+}
+
+// sourceDirs returns the directories the relative file names of -trimpath
+// binaries are looked up in: the given ones, then the standard library,
+// the GOPATH source trees and the module cache.
+func sourceDirs(dirs []string) []string {
+	dirs = append(dirs, filepath.Join(runtime.GOROOT(), "src"))
+	for _, dir := range filepath.SplitList(build.Default.GOPATH) {
+		dirs = append(dirs, filepath.Join(dir, "src"), filepath.Join(dir, "pkg", "mod"))
+	}
+	return dirs
+}
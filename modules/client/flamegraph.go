@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/wgliang/opengacm/modules/client/internal/objfile"
+	"github.com/wgliang/opengacm/modules/client/internal/report"
+)
+
+var (
+	cflameProfile = cflame.Arg("profile", "CPU or heap profile.").Required().String()
+	cflameBinary  = cflame.Flag("binary", "Profiled binary, used to symbolize addresses the profile does not resolve.").String()
+	cflameOutput  = cflame.Flag("output", "File to write, .svg for a bare SVG, anything else for HTML.").Short('o').String()
+	cflameSample  = cflame.Flag("sample", "Sample type to render, e.g. alloc_space; defaults to the profile default.").String()
+	cflameTitle   = cflame.Flag("title", "Title of the flame graph.").String()
+)
+
+// flameGraph renders a captured profile as a standalone flame graph that
+// can be opened without a Go toolchain or network access.
+func flameGraph() error {
+	p, err := readProfile(*cflameProfile)
+	if err != nil {
+		return err
+	}
+	if *cflameBinary != "" {
+		f, err := objfile.Open(*cflameBinary)
+		if err != nil {
+			return err
+		}
+		err = report.Symbolize(p, f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("failed to symbolize the profile: %v", err)
+		}
+	}
+	i, err := report.SampleIndex(p, *cflameSample)
+	if err != nil {
+		return err
+	}
+	st := p.SampleType[i]
+
+	title := *cflameTitle
+	if title == "" {
+		title = fmt.Sprintf("%s (%s)", filepath.Base(*cflameProfile), st.Type)
+	}
+	output := *cflameOutput
+	if output == "" {
+		base := strings.TrimSuffix(filepath.Base(*cflameProfile), ".gz")
+		output = strings.TrimSuffix(base, filepath.Ext(base)) + ".html"
+	}
+	out, err := os.Create(output)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	root := report.FlameTree(p, i)
+	if strings.HasSuffix(output, ".svg") {
+		err = report.WriteFlameSVG(out, root, title, st.Unit)
+	} else {
+		err = report.WriteFlameHTML(out, root, title, st.Unit)
+	}
+	if err != nil {
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	fmt.Printf("Flame graph saved to: %s\n", output)
+	return nil
+}
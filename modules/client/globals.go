@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/wgliang/opengacm/modules/client/internal/debuginfo"
+)
+
+var (
+	cglobalsTarget = cglobals.Arg("binary", "Go binary, or PID of a running Go process.").Required().String()
+	cglobalsRegexp = cglobals.Arg("regexp", "Only list the variables whose name matches this regular expression.").String()
+)
+
+// globals prints the package-level variables of a Go binary with their
+// address, size and type.
+func globals() error {
+	re, err := compileFilter(*cglobalsRegexp)
+	if err != nil {
+		return err
+	}
+	path, err := binaryPath(*cglobalsTarget)
+	if err != nil {
+		return err
+	}
+	d, err := openDWARF(path)
+	if err != nil {
+		return err
+	}
+	vars, err := debuginfo.Globals(d, re)
+	if err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "ADDRESS\tSIZE\tNAME\tTYPE")
+	for _, v := range vars {
+		fmt.Fprintf(tw, "%#x\t%d\t%s\t%s\n", v.Addr, v.Type.Size(), v.Name, debuginfo.TypeName(v.Type))
+	}
+	return tw.Flush()
+}
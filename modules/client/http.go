@@ -10,6 +10,7 @@ import (
 
 	"github.com/wgliang/opengacm/modules/client/controller/application"
 	gapmdaemon "github.com/wgliang/opengacm/modules/client/controller/daemon"
+	"github.com/wgliang/opengacm/modules/client/internal/symbols"
 
 	log "github.com/Sirupsen/logrus"
 	"github.com/buaazp/fasthttprouter"
@@ -33,6 +34,7 @@ type ClientDaemon struct {
 	TransferAddr string
 	AllowedIps   []string
 	Applications []application.Application
+	Symbols      *symbols.Store // Symbols is the symbol store of the daemon, set once it started.
 }
 
 // NewClientDaemon provides the implement of return a ClientDaemon struct.
@@ -47,6 +49,10 @@ func (cd *ClientDaemon) RegHandler(router *fasthttprouter.Router) {
 	router.POST("/gacmclient/policy", cd.HandlePolicy)
 	router.POST("/gacmclient/update", cd.HandleUpdate)
 	router.POST("/gacmclient/action", cd.HandleAction)
+	router.GET(symbolsPath, cd.HandleSymbols)
+	router.POST(symbolsPath, cd.HandleAddSymbols)
+	router.GET(symbolsPath+"/*buildid", cd.HandleBinary)
+	router.POST("/gacmclient/symbolize/*buildid", cd.HandleSymbolize)
 }
 
 // HandleHello is a handler of testing service.
@@ -135,6 +141,7 @@ func (cd *ClientDaemon) startDaemon() {
 
 	log.Info("Starting remote master server...")
 	remoteMaster := gapmdaemon.StartRemoteMasterServer(ln, *startConfigFile)
+	cd.Symbols = remoteMaster.Symbols()
 
 	go func(allowedIPs []string) {
 		IPAllowHandler := func(ctx *fasthttp.RequestCtx) {
@@ -153,7 +160,11 @@ func (cd *ClientDaemon) startDaemon() {
 			}
 			router.Handler(ctx)
 		}
-		if err := fasthttp.Serve(ln, IPAllowHandler); err != nil {
+		server := &fasthttp.Server{
+			Handler:        IPAllowHandler,
+			HeaderReceived: requestConfig,
+		}
+		if err := server.Serve(ln); err != nil {
 			log.Println("gacmc error:", err)
 		}
 	}(cd.AllowedIps)
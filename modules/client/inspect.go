@@ -0,0 +1,232 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/wgliang/opengacm/modules/client/internal/objfile"
+	"github.com/wgliang/opengacm/modules/client/internal/proc"
+)
+
+var (
+	cinspectTarget = cinspect.Arg("binary", "Go binary, or PID of a running Go process.").Required().String()
+	cinspectJSON   = cinspect.Flag("json", "Print the build information as JSON.").Bool()
+)
+
+// module is a module recorded in a Go binary.
+type module struct {
+	Path    string  `json:"path"`
+	Version string  `json:"version,omitempty"`
+	Sum     string  `json:"sum,omitempty"`
+	Replace *module `json:"replace,omitempty"`
+}
+
+// vcsInfo is the version control state of the main module at build time.
+type vcsInfo struct {
+	System   string `json:"system"`
+	Revision string `json:"revision,omitempty"`
+	Time     string `json:"time,omitempty"`
+	Modified bool   `json:"modified"`
+}
+
+// buildReport is the build information of a Go binary.
+type buildReport struct {
+	Binary     string    `json:"binary"`
+	GoVersion  string    `json:"go_version"`
+	BuildID    string    `json:"build_id,omitempty"`
+	Path       string    `json:"path,omitempty"`
+	Main       *module   `json:"main,omitempty"`
+	Deps       []*module `json:"deps"`
+	VCS        *vcsInfo  `json:"vcs,omitempty"`
+	GOOS       string    `json:"goos,omitempty"`
+	GOARCH     string    `json:"goarch,omitempty"`
+	CgoEnabled bool      `json:"cgo_enabled"`
+	Race       bool      `json:"race"`
+	Trimpath   bool      `json:"trimpath"`
+	Tags       []string  `json:"tags,omitempty"`
+	Settings   []setting `json:"settings"`
+}
+
+// setting is a build setting, such as "-trimpath" or "GOOS".
+type setting struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// inspect prints the build information of a Go binary, read from the
+// binary only, without the help of an agent.
+func inspect() error {
+	path, err := binaryPath(*cinspectTarget)
+	if err != nil {
+		return err
+	}
+	r, err := readBuildReport(path)
+	if err != nil {
+		return err
+	}
+	r.Binary = *cinspectTarget
+	if *cinspectJSON {
+		return printJSON(r)
+	}
+	r.print(os.Stdout)
+	return nil
+}
+
+// binaryPath returns the path of the binary named by target, which is
+// either a path or the PID of a running process. The binary of a process
+// is opened through /proc/<pid>/exe, which also works for containerized
+// processes and deleted binaries.
+func binaryPath(target string) (string, error) {
+	if _, err := os.Stat(target); err == nil {
+		return target, nil
+	}
+	pid, err := strconv.Atoi(target)
+	if err != nil {
+		return "", fmt.Errorf("%s is neither a file nor a PID", target)
+	}
+	path := proc.Path(pid, "exe")
+	if _, err := os.Stat(path); err != nil {
+		return "", fmt.Errorf("couldn't find the binary of process %d: %v", pid, err)
+	}
+	return path, nil
+}
+
+// readBuildReport reads the build information of the Go binary at path.
+func readBuildReport(path string) (*buildReport, error) {
+	f, err := objfile.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	bi, err := f.BuildInfo()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	r := &buildReport{
+		Binary:    path,
+		GoVersion: bi.GoVersion,
+		Path:      bi.Path,
+		Deps:      []*module{},
+		Settings:  []setting{},
+		GOARCH:    f.GOARCH(),
+	}
+	r.BuildID, _ = f.BuildID()
+	if bi.Main.Path != "" {
+		r.Main = newModule(&bi.Main)
+	}
+	for _, dep := range bi.Deps {
+		r.Deps = append(r.Deps, newModule(dep))
+	}
+	for _, s := range bi.Settings {
+		r.Settings = append(r.Settings, setting{s.Key, s.Value})
+		switch s.Key {
+		case "GOOS":
+			r.GOOS = s.Value
+		case "GOARCH":
+			r.GOARCH = s.Value
+		case "CGO_ENABLED":
+			r.CgoEnabled = s.Value == "1"
+		case "-race":
+			r.Race = s.Value == "true"
+		case "-trimpath":
+			r.Trimpath = s.Value == "true"
+		case "-tags":
+			r.Tags = strings.Split(s.Value, ",")
+		case "vcs":
+			r.vcs().System = s.Value
+		case "vcs.revision":
+			r.vcs().Revision = s.Value
+		case "vcs.time":
+			r.vcs().Time = s.Value
+		case "vcs.modified":
+			r.vcs().Modified = s.Value == "true"
+		}
+	}
+	return r, nil
+}
+
+func (r *buildReport) vcs() *vcsInfo {
+	if r.VCS == nil {
+		r.VCS = &vcsInfo{}
+	}
+	return r.VCS
+}
+
+func newModule(m *debug.Module) *module {
+	mod := &module{Path: m.Path, Version: m.Version, Sum: m.Sum}
+	if m.Replace != nil {
+		mod.Replace = newModule(m.Replace)
+	}
+	return mod
+}
+
+func (m *module) String() string {
+	s := m.Path
+	if m.Version != "" {
+		s += " " + m.Version
+	}
+	if m.Replace != nil {
+		s += " => " + m.Replace.String()
+	}
+	return s
+}
+
+func (r *buildReport) print(w io.Writer) {
+	tw := tabwriter.NewWriter(w, 0, 8, 1, ' ', 0)
+	fmt.Fprintf(tw, "Binary:\t%s\n", r.Binary)
+	fmt.Fprintf(tw, "Go version:\t%s\n", r.GoVersion)
+	if r.BuildID != "" {
+		fmt.Fprintf(tw, "Build ID:\t%s\n", r.BuildID)
+	}
+	if r.Path != "" {
+		fmt.Fprintf(tw, "Path:\t%s\n", r.Path)
+	}
+	if r.Main != nil {
+		main := r.Main.String()
+		if r.Main.Sum != "" {
+			main += " " + r.Main.Sum
+		}
+		fmt.Fprintf(tw, "Main module:\t%s\n", main)
+	}
+	if r.VCS != nil {
+		vcs := r.VCS.System
+		if r.VCS.Revision != "" {
+			vcs += " " + r.VCS.Revision
+		}
+		if r.VCS.Time != "" {
+			vcs += " " + r.VCS.Time
+		}
+		if r.VCS.Modified {
+			vcs += " (modified)"
+		}
+		fmt.Fprintf(tw, "VCS:\t%s\n", vcs)
+	}
+	fmt.Fprintf(tw, "Platform:\t%s/%s\n", r.GOOS, r.GOARCH)
+	fmt.Fprintf(tw, "Cgo:\t%v\n", r.CgoEnabled)
+	fmt.Fprintf(tw, "Trimpath:\t%v\n", r.Trimpath)
+	if len(r.Tags) > 0 {
+		fmt.Fprintf(tw, "Tags:\t%s\n", strings.Join(r.Tags, ","))
+	}
+	tw.Flush()
+
+	if len(r.Settings) > 0 {
+		fmt.Fprintln(w, "\nBuild settings:")
+		for _, s := range r.Settings {
+			fmt.Fprintf(w, "  %s=%s\n", s.Key, s.Value)
+		}
+	}
+	fmt.Fprintf(w, "\nDependencies (%d):\n", len(r.Deps))
+	tw = tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	for _, dep := range r.Deps {
+		fmt.Fprintf(tw, "  %s\t%s\t%s\n", dep.Path, dep.Version, dep.Sum)
+		if dep.Replace != nil {
+			fmt.Fprintf(tw, "  => %s\t%s\t%s\n", dep.Replace.Path, dep.Replace.Version, dep.Replace.Sum)
+		}
+	}
+	tw.Flush()
+}
@@ -0,0 +1,375 @@
+// Package core reads the goroutines and the heap statistics of a crashed
+// Go process from its ELF core dump and the binary that it ran, such as
+// the core dumps written with GOTRACEBACK=crash.
+package core
+
+import (
+	"bytes"
+	"debug/dwarf"
+	"debug/elf"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/wgliang/opengacm/modules/client/internal/debuginfo"
+	"github.com/wgliang/opengacm/modules/client/internal/objfile"
+)
+
+// Note types of the core dumps.
+const (
+	ntPRStatus = 1 // registers and ID of a thread
+	ntAuxv     = 6 // auxiliary vector of the process
+	atEntry    = 9 // auxiliary vector entry of the entry point
+)
+
+// prRegOffset is the offset of the registers in the NT_PRSTATUS notes of
+// 64-bit Linux, after the signal information, the IDs and the CPU times.
+const prRegOffset = 112
+
+// Thread is a thread of a core dump.
+type Thread struct {
+	TID        int
+	PC, SP, FP uint64
+}
+
+// Process is a Go process restored from its core dump.
+type Process struct {
+	Threads []Thread
+
+	exe     *objfile.File
+	pcln    objfile.Liner
+	d       *dwarf.Data
+	mem     memory
+	offset  uint64 // difference between runtime and link-time addresses
+	globals map[string]debuginfo.Global
+	files   []io.Closer
+}
+
+// runtimeVars are the variables of the runtime that the process is
+// restored from.
+var runtimeVars = regexp.MustCompile(`^runtime\.(allgs|gcController|memstats|mheap_|waitReasonStrings)$`)
+
+// Open restores the process of the core dump core of the binary exe. The
+// binary needs its DWARF information.
+func Open(exe, core string) (*Process, error) {
+	p := &Process{}
+	if err := p.open(exe, core); err != nil {
+		p.Close()
+		return nil, err
+	}
+	return p, nil
+}
+
+func (p *Process) open(exe, core string) error {
+	cf, err := elf.Open(core)
+	if err != nil {
+		return err
+	}
+	p.files = append(p.files, cf)
+	if cf.Type != elf.ET_CORE {
+		return fmt.Errorf("%s is not a core dump", core)
+	}
+	regs, err := registers(cf.Machine)
+	if err != nil {
+		return err
+	}
+	ef, err := elf.Open(exe)
+	if err != nil {
+		return err
+	}
+	p.files = append(p.files, ef)
+	if ef.Machine != cf.Machine {
+		return fmt.Errorf("%s is for %v, %s for %v", exe, ef.Machine, core, cf.Machine)
+	}
+	if p.exe, err = objfile.Open(exe); err != nil {
+		return err
+	}
+	p.files = append(p.files, p.exe)
+	if p.pcln, err = p.exe.PCLineTable(); err != nil {
+		return err
+	}
+	if p.d, err = p.exe.DWARF(); err != nil {
+		return fmt.Errorf("%s has no DWARF information: %v", exe, err)
+	}
+	globals, err := debuginfo.Globals(p.d, runtimeVars)
+	if err != nil {
+		return err
+	}
+	p.globals = make(map[string]debuginfo.Global)
+	for _, g := range globals {
+		p.globals[g.Name] = g
+	}
+	if _, ok := p.globals["runtime.allgs"]; !ok {
+		return fmt.Errorf("%s has no DWARF information about runtime.allgs", exe)
+	}
+
+	// The memory missing from the core, such as the text, is the one
+	// of the binary.
+	for _, prog := range cf.Progs {
+		if prog.Type == elf.PT_LOAD && prog.Filesz > 0 {
+			p.mem = append(p.mem, segment{prog.Vaddr, prog.Filesz, prog})
+		}
+	}
+	for _, prog := range cf.Progs {
+		if prog.Type != elf.PT_NOTE {
+			continue
+		}
+		b := make([]byte, prog.Filesz)
+		if _, err := prog.ReadAt(b, 0); err != nil {
+			return fmt.Errorf("%s: notes: %v", core, err)
+		}
+		if err := p.readNotes(b, regs, ef.Entry); err != nil {
+			return fmt.Errorf("%s: %v", core, err)
+		}
+	}
+	if err := p.checkBinary(ef); err != nil {
+		return fmt.Errorf("%s is not the binary of %s: %v", exe, core, err)
+	}
+	for _, prog := range ef.Progs {
+		if prog.Type == elf.PT_LOAD && prog.Filesz > 0 {
+			p.mem = append(p.mem, segment{prog.Vaddr + p.offset, prog.Filesz, prog})
+		}
+	}
+	return nil
+}
+
+// checkBinary compares the first page of the binary ef, which holds its
+// headers and build ID, with the one of the core dump. Linux dumps it
+// unless told otherwise by /proc/<pid>/coredump_filter.
+func (p *Process) checkBinary(ef *elf.File) error {
+	if p.offset%4096 != 0 || (ef.Type == elf.ET_EXEC && p.offset != 0) {
+		return fmt.Errorf("entry points differ")
+	}
+	for _, prog := range ef.Progs {
+		if prog.Type != elf.PT_LOAD || prog.Off != 0 {
+			continue
+		}
+		n := prog.Filesz
+		if n > 4096 {
+			n = 4096
+		}
+		want, got := make([]byte, n), make([]byte, n)
+		if _, err := prog.ReadAt(want, 0); err != nil {
+			return err
+		}
+		if _, err := p.mem.ReadAt(got, int64(prog.Vaddr+p.offset)); err != nil {
+			// Not dumped.
+			return nil
+		}
+		if !bytes.Equal(got, want) {
+			return fmt.Errorf("headers differ")
+		}
+	}
+	return nil
+}
+
+// registers returns the indices of the program counter, the stack pointer
+// and the frame pointer in the registers of the NT_PRSTATUS notes of
+// machine.
+func registers(machine elf.Machine) ([3]int, error) {
+	switch machine {
+	case elf.EM_X86_64:
+		// rip, rsp and rbp of user_regs_struct.
+		return [3]int{16, 19, 4}, nil
+	case elf.EM_AARCH64:
+		// pc, sp and x29 of user_pt_regs.
+		return [3]int{32, 31, 29}, nil
+	}
+	return [3]int{}, fmt.Errorf("core dumps of %v are not supported", machine)
+}
+
+// readNotes reads the threads and the load offset of the process from
+// the notes b.
+func (p *Process) readNotes(b []byte, regs [3]int, entry uint64) error {
+	order := binary.LittleEndian
+	for len(b) >= 12 {
+		namesz, descsz, typ := order.Uint32(b), order.Uint32(b[4:]), order.Uint32(b[8:])
+		start := 12 + align4(int(namesz))
+		end := start + int(descsz)
+		if end > len(b) {
+			return fmt.Errorf("malformed note")
+		}
+		desc := b[start:end]
+		if next := align4(end); next < len(b) {
+			b = b[next:]
+		} else {
+			b = nil
+		}
+		switch typ {
+		case ntPRStatus:
+			for _, i := range regs {
+				if len(desc) < prRegOffset+8*(i+1) {
+					return fmt.Errorf("malformed NT_PRSTATUS note")
+				}
+			}
+			reg := func(i int) uint64 { return order.Uint64(desc[prRegOffset+8*i:]) }
+			p.Threads = append(p.Threads, Thread{
+				TID: int(int32(order.Uint32(desc[32:]))),
+				PC:  reg(regs[0]),
+				SP:  reg(regs[1]),
+				FP:  reg(regs[2]),
+			})
+		case ntAuxv:
+			for i := 0; i+16 <= len(desc); i += 16 {
+				if order.Uint64(desc[i:]) == atEntry {
+					p.offset = order.Uint64(desc[i+8:]) - entry
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func align4(n int) int {
+	return (n + 3) &^ 3
+}
+
+// Close closes the files of the process.
+func (p *Process) Close() error {
+	for _, f := range p.files {
+		f.Close()
+	}
+	p.files = nil
+	return nil
+}
+
+// Memory returns the memory of the process.
+func (p *Process) Memory() io.ReaderAt {
+	return p.mem
+}
+
+// Offset returns the difference between the runtime addresses of the
+// process and the link-time addresses of its binary, which is not zero
+// for position independent executables.
+func (p *Process) Offset() uint64 {
+	return p.offset
+}
+
+// segment is a part of the memory of the process.
+type segment struct {
+	addr, size uint64
+	r          io.ReaderAt
+}
+
+// memory is the memory of the process, made of the segments of the core
+// dump, then of the binary.
+type memory []segment
+
+func (m memory) ReadAt(b []byte, off int64) (int, error) {
+	addr, n := uint64(off), uint64(len(b))
+	for _, s := range m {
+		if s.addr <= addr && addr+n <= s.addr+s.size {
+			return s.r.ReadAt(b, int64(addr-s.addr))
+		}
+	}
+	return 0, fmt.Errorf("address %#x is not in the core dump", addr)
+}
+
+// global returns the runtime address and the type of the runtime variable
+// name.
+func (p *Process) global(name string) (uint64, dwarf.Type, error) {
+	g, ok := p.globals[name]
+	if !ok {
+		return 0, nil, fmt.Errorf("no DWARF information about %s", name)
+	}
+	return g.Addr + p.offset, g.Type, nil
+}
+
+// field returns the address and the type of the field path, such as
+// "sched.pc", of the struct of type t at addr.
+func field(t dwarf.Type, addr uint64, path string) (uint64, dwarf.Type, error) {
+	for _, name := range strings.Split(path, ".") {
+		st, ok := underlying(t).(*dwarf.StructType)
+		if !ok {
+			return 0, nil, fmt.Errorf("%s is not a struct", t)
+		}
+		var f *dwarf.StructField
+		for _, sf := range st.Field {
+			if sf.Name == name {
+				f = sf
+				break
+			}
+		}
+		if f == nil {
+			return 0, nil, fmt.Errorf("%s has no field %s", debuginfo.TypeName(t), name)
+		}
+		addr += uint64(f.ByteOffset)
+		t = f.Type
+	}
+	return addr, t, nil
+}
+
+// underlying returns the type t refers to through typedefs.
+func underlying(t dwarf.Type) dwarf.Type {
+	for {
+		td, ok := t.(*dwarf.TypedefType)
+		if !ok {
+			return t
+		}
+		t = td.Type
+	}
+}
+
+// uint reads the integer or pointer of type t at addr. Structs wrapping a
+// single value, such as the types of sync/atomic, read as the value.
+func (p *Process) uint(t dwarf.Type, addr uint64) (uint64, error) {
+	t = underlying(t)
+	if st, ok := t.(*dwarf.StructType); ok {
+		// The value is the last field, after the zero-size markers
+		// such as noCopy.
+		for i := len(st.Field) - 1; i >= 0; i-- {
+			if f := st.Field[i]; f.Type.Size() > 0 {
+				return p.uint(f.Type, addr+uint64(f.ByteOffset))
+			}
+		}
+		return 0, fmt.Errorf("%s holds no value", debuginfo.TypeName(t))
+	}
+	var b [8]byte
+	size := t.Size()
+	switch size {
+	case 1, 2, 4, 8:
+	default:
+		return 0, fmt.Errorf("%s is not an integer", debuginfo.TypeName(t))
+	}
+	if _, err := p.mem.ReadAt(b[:size], int64(addr)); err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint64(b[:]), nil
+}
+
+// fieldUint reads the integer field path of the struct of type t at addr.
+func (p *Process) fieldUint(t dwarf.Type, addr uint64, path string) (uint64, error) {
+	addr, t, err := field(t, addr, path)
+	if err != nil {
+		return 0, err
+	}
+	return p.uint(t, addr)
+}
+
+// varUint reads the integer field path of the runtime variable name.
+func (p *Process) varUint(name, path string) (uint64, error) {
+	addr, t, err := p.global(name)
+	if err != nil {
+		return 0, err
+	}
+	return p.fieldUint(t, addr, path)
+}
+
+// str reads the Go string at addr.
+func (p *Process) str(addr uint64) (string, error) {
+	var b [16]byte
+	if _, err := p.mem.ReadAt(b[:], int64(addr)); err != nil {
+		return "", err
+	}
+	data, n := binary.LittleEndian.Uint64(b[:]), binary.LittleEndian.Uint64(b[8:])
+	if n > 1<<10 {
+		return "", fmt.Errorf("string at %#x too long", addr)
+	}
+	s := make([]byte, n)
+	if _, err := p.mem.ReadAt(s, int64(data)); err != nil {
+		return "", err
+	}
+	return string(s), nil
+}
@@ -0,0 +1,129 @@
+package core
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+const testProgram = `package main
+
+import (
+	"runtime"
+	"time"
+)
+
+//go:noinline
+func worker(ch chan int) {
+	<-ch
+}
+
+func main() {
+	ch := make(chan int)
+	for i := 0; i < 3; i++ {
+		go worker(ch)
+	}
+	runtime.GC()
+	time.Sleep(100 * time.Millisecond)
+	var m map[string]int
+	m["crash"] = 1
+}
+`
+
+// crashTestProgram builds and crashes testProgram, and returns the paths
+// of its binary and of its core dump.
+func crashTestProgram(t *testing.T) (string, string) {
+	if runtime.GOOS != "linux" || (runtime.GOARCH != "amd64" && runtime.GOARCH != "arm64") {
+		t.Skip("reads Linux core dumps of amd64 and arm64")
+	}
+	gotool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not found")
+	}
+	dir, err := ioutil.TempDir("", "core")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	if err := ioutil.WriteFile(filepath.Join(dir, "main.go"), []byte(testProgram), 0644); err != nil {
+		t.Fatal(err)
+	}
+	exe := filepath.Join(dir, "prog")
+	cmd := exec.Command(gotool, "build", "-o", exe, "main.go")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GO111MODULE=off", "GOFLAGS=")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Skipf("go build: %v\n%s", err, out)
+	}
+
+	// The crashing process inherits the limit of the size of its core.
+	var limit syscall.Rlimit
+	if err := syscall.Getrlimit(syscall.RLIMIT_CORE, &limit); err != nil {
+		t.Fatal(err)
+	}
+	defer syscall.Setrlimit(syscall.RLIMIT_CORE, &limit)
+	unlimited := syscall.Rlimit{Cur: limit.Max, Max: limit.Max}
+	if err := syscall.Setrlimit(syscall.RLIMIT_CORE, &unlimited); err != nil || limit.Max == 0 {
+		t.Skip("core dumps are disabled")
+	}
+	cmd = exec.Command(exe)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOTRACEBACK=crash")
+	cmd.Run()
+	matches, _ := filepath.Glob(filepath.Join(dir, "core*"))
+	if len(matches) == 0 {
+		t.Skip("no core dump in the working directory, see /proc/sys/kernel/core_pattern")
+	}
+	return exe, matches[0]
+}
+
+func TestCore(t *testing.T) {
+	exe, corefile := crashTestProgram(t)
+	p, err := Open(exe, corefile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+	if len(p.Threads) == 0 {
+		t.Error("no threads")
+	}
+	gs, err := p.Goroutines()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var main, workers int
+	for _, g := range gs {
+		var funcs []string
+		for _, f := range g.Frames {
+			funcs = append(funcs, f.Func)
+		}
+		stack := strings.Join(funcs, " ")
+		switch {
+		case g.ID == 1:
+			if g.State != "running" || !strings.Contains(stack, "runtime.gopanic") || !strings.HasSuffix(stack, "main.main runtime.main") {
+				t.Errorf("goroutine 1 [%s]: %s", g.State, stack)
+			}
+			main++
+		case strings.Contains(stack, "main.worker"):
+			if g.State != "chan receive" || g.CreatedBy == nil || g.CreatedBy.Func != "main.main" {
+				t.Errorf("worker goroutine %d [%s] created by %+v", g.ID, g.State, g.CreatedBy)
+			}
+			workers++
+		}
+	}
+	if main != 1 || workers != 3 {
+		t.Errorf("%d main and %d worker goroutines; want 1 and 3", main, workers)
+	}
+	if h := p.Heap(); h.NumGC == 0 || h.InUse == 0 {
+		t.Errorf("heap = %+v; want GC cycles and memory in use", h)
+	}
+
+	if _, err := Open(exe, exe); err == nil {
+		t.Error("Open of a binary as core dump succeeded")
+	}
+}
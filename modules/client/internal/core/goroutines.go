@@ -0,0 +1,260 @@
+package core
+
+import (
+	"debug/dwarf"
+	"fmt"
+	"time"
+
+	"github.com/wgliang/opengacm/modules/client/internal/goroutine"
+	"github.com/wgliang/opengacm/modules/client/internal/unwind"
+)
+
+// Statuses of the goroutines, from runtime/runtime2.go.
+const (
+	statusRunning = 2
+	statusSyscall = 3
+	statusWaiting = 4
+	statusDead    = 6
+	statusScan    = 0x1000 // bit set while the GC scans the stack
+)
+
+// statusNames are the names of the goroutine statuses in tracebacks.
+var statusNames = []string{
+	"idle", "runnable", "running", "syscall", "waiting",
+	"moribund", "dead", "enqueue", "copystack", "preempted",
+}
+
+// maxGoroutines bounds the length of runtime.allgs read from a corrupt
+// core dump.
+const maxGoroutines = 1 << 24
+
+// Goroutines returns the goroutines of the process that are not dead, in
+// creation order.
+func (p *Process) Goroutines() ([]*goroutine.Goroutine, error) {
+	addr, t, err := p.global("runtime.allgs")
+	if err != nil {
+		return nil, err
+	}
+	array, err := p.fieldUint(t, addr, "array")
+	if err != nil {
+		return nil, err
+	}
+	n, err := p.fieldUint(t, addr, "len")
+	if err != nil {
+		return nil, err
+	}
+	if n > maxGoroutines {
+		return nil, fmt.Errorf("runtime.allgs holds %d goroutines", n)
+	}
+	_, at, err := field(t, addr, "array")
+	if err != nil {
+		return nil, err
+	}
+	gptr, ok := underlying(at).(*dwarf.PtrType)
+	if !ok {
+		return nil, fmt.Errorf("runtime.allgs is not a slice of pointers")
+	}
+	gp, ok := underlying(gptr.Type).(*dwarf.PtrType)
+	if !ok {
+		return nil, fmt.Errorf("runtime.allgs is not a slice of pointers")
+	}
+	reasons := p.waitReasons()
+
+	var gs []*goroutine.Goroutine
+	for i := uint64(0); i < n; i++ {
+		ga, err := p.uint(gptr.Type, array+8*i)
+		if err != nil {
+			return nil, err
+		}
+		g, err := p.goroutine(gp.Type, ga, reasons)
+		if err != nil {
+			return nil, fmt.Errorf("goroutine at %#x: %v", ga, err)
+		}
+		if g != nil {
+			gs = append(gs, g)
+		}
+	}
+	return gs, nil
+}
+
+// goroutine reads the goroutine of struct type t at addr, or returns nil
+// if it is dead.
+func (p *Process) goroutine(t dwarf.Type, addr uint64, reasons []string) (*goroutine.Goroutine, error) {
+	status, err := p.fieldUint(t, addr, "atomicstatus")
+	if err != nil {
+		return nil, err
+	}
+	status &^= statusScan
+	if status == statusDead {
+		return nil, nil
+	}
+	id, err := p.fieldUint(t, addr, "goid")
+	if err != nil {
+		return nil, err
+	}
+	g := &goroutine.Goroutine{ID: int(id), State: fmt.Sprintf("status %d", status)}
+	if status < uint64(len(statusNames)) {
+		g.State = statusNames[status]
+	}
+	if status == statusWaiting {
+		if r, err := p.fieldUint(t, addr, "waitreason"); err == nil && r > 0 && r < uint64(len(reasons)) {
+			g.State = reasons[r]
+		}
+	}
+	if m, err := p.fieldUint(t, addr, "lockedm"); err == nil && m != 0 {
+		g.Locked = true
+	}
+
+	// The registers saved when the goroutine was descheduled are the
+	// ones of a call, like the return addresses.
+	pc, _ := p.fieldUint(t, addr, "sched.pc")
+	fp, _ := p.fieldUint(t, addr, "sched.bp")
+	call := true
+	switch status {
+	case statusSyscall:
+		if spc, err := p.fieldUint(t, addr, "syscallpc"); err == nil && spc != 0 {
+			pc = spc
+			if sbp, err := p.fieldUint(t, addr, "syscallbp"); err == nil {
+				fp = sbp
+			}
+		}
+	case statusRunning:
+		// The frames of the signal handler and of the system stack of
+		// the thread link to the ones of the goroutine.
+		if th := p.thread(t, addr); th != nil {
+			pc, fp, call = th.PC, th.FP, false
+		}
+	}
+	if pc != 0 {
+		// Goroutines that never ran are descheduled at the entry of
+		// their function.
+		if _, _, fn := p.pcln.PCToLine(pc - p.offset); fn != nil && fn.Entry == pc-p.offset {
+			call = false
+		}
+		for i, pc := range unwind.FramePointers(p.mem, pc, fp, true) {
+			if i > 0 || call {
+				pc--
+			}
+			f := p.frame(pc)
+			if f.Func == "runtime.goexit" {
+				break
+			}
+			g.Frames = append(g.Frames, f)
+		}
+	}
+	// The main goroutine is created by the bootstrap code.
+	if gopc, err := p.fieldUint(t, addr, "gopc"); err == nil && gopc != 0 && id != 1 {
+		f := p.frame(gopc - 1)
+		g.CreatedBy = &f
+	}
+	return g, nil
+}
+
+// thread returns the thread running the goroutine of type t at addr, if
+// the core dump has it.
+func (p *Process) thread(t dwarf.Type, addr uint64) *Thread {
+	maddr, mt, err := field(t, addr, "m")
+	if err != nil {
+		return nil
+	}
+	m, err := p.uint(mt, maddr)
+	mp, ok := underlying(mt).(*dwarf.PtrType)
+	if err != nil || m == 0 || !ok {
+		return nil
+	}
+	tid, err := p.fieldUint(mp.Type, m, "procid")
+	if err != nil {
+		return nil
+	}
+	for i := range p.Threads {
+		if uint64(p.Threads[i].TID) == tid {
+			return &p.Threads[i]
+		}
+	}
+	return nil
+}
+
+// frame returns the function, file and line of the runtime address pc.
+func (p *Process) frame(pc uint64) goroutine.Frame {
+	file, line, fn := p.pcln.PCToLine(pc - p.offset)
+	if fn == nil {
+		return goroutine.Frame{Func: fmt.Sprintf("%#x", pc)}
+	}
+	return goroutine.Frame{Func: fn.Name, File: file, Line: line}
+}
+
+// waitReasons returns the strings of the reasons goroutines wait for,
+// indexed by the waitreason field of the goroutines.
+func (p *Process) waitReasons() []string {
+	addr, t, err := p.global("runtime.waitReasonStrings")
+	if err != nil {
+		return nil
+	}
+	at, ok := underlying(t).(*dwarf.ArrayType)
+	if !ok || at.Count <= 0 {
+		return nil
+	}
+	size := uint64(at.Type.Size())
+	reasons := make([]string, at.Count)
+	for i := range reasons {
+		reasons[i], _ = p.str(addr + uint64(i)*size)
+	}
+	return reasons
+}
+
+// Heap is the heap statistics of a process, in bytes. Those the runtime
+// of the process lacks are zero.
+type Heap struct {
+	InUse      uint64        `json:"in_use"`   // in the spans in use
+	Free       uint64        `json:"free"`     // free and not released to the OS
+	Released   uint64        `json:"released"` // released to the OS
+	Live       uint64        `json:"live"`     // marked by the last GC and allocated since
+	Marked     uint64        `json:"marked"`   // marked by the last GC
+	Goal       uint64        `json:"goal"`     // size that triggers the next GC
+	TotalAlloc uint64        `json:"total_alloc"`
+	TotalFree  uint64        `json:"total_free"`
+	NumGC      uint64        `json:"num_gc"`
+	PauseTotal time.Duration `json:"pause_total"`
+	LastGC     time.Time     `json:"last_gc"`
+}
+
+// heapFigures locate the statistics of Heap, in the runtime variables of
+// recent Go versions first.
+var heapFigures = []struct {
+	paths [][2]string
+	set   func(h *Heap, v uint64)
+}{
+	{[][2]string{{"runtime.gcController", "heapInUse"}, {"runtime.memstats", "heap_inuse"}}, func(h *Heap, v uint64) { h.InUse = v }},
+	{[][2]string{{"runtime.gcController", "heapFree"}}, func(h *Heap, v uint64) { h.Free = v }},
+	{[][2]string{{"runtime.gcController", "heapReleased"}, {"runtime.memstats", "heap_released"}}, func(h *Heap, v uint64) { h.Released = v }},
+	{[][2]string{{"runtime.gcController", "heapLive"}, {"runtime.memstats", "heap_live"}}, func(h *Heap, v uint64) { h.Live = v }},
+	{[][2]string{{"runtime.gcController", "heapMarked"}, {"runtime.memstats", "heap_marked"}}, func(h *Heap, v uint64) { h.Marked = v }},
+	{[][2]string{{"runtime.gcController", "gcPercentHeapGoal"}, {"runtime.gcController", "heapGoal"}, {"runtime.memstats", "next_gc"}}, func(h *Heap, v uint64) {
+		if v != ^uint64(0) {
+			h.Goal = v
+		}
+	}},
+	{[][2]string{{"runtime.gcController", "totalAlloc"}}, func(h *Heap, v uint64) { h.TotalAlloc = v }},
+	{[][2]string{{"runtime.gcController", "totalFree"}}, func(h *Heap, v uint64) { h.TotalFree = v }},
+	{[][2]string{{"runtime.memstats", "numgc"}}, func(h *Heap, v uint64) { h.NumGC = v }},
+	{[][2]string{{"runtime.memstats", "pause_total_ns"}}, func(h *Heap, v uint64) { h.PauseTotal = time.Duration(v) }},
+	{[][2]string{{"runtime.memstats", "last_gc_unix"}}, func(h *Heap, v uint64) {
+		if v != 0 {
+			h.LastGC = time.Unix(0, int64(v))
+		}
+	}},
+}
+
+// Heap returns the heap statistics of the process.
+func (p *Process) Heap() *Heap {
+	h := &Heap{}
+	for _, f := range heapFigures {
+		for _, path := range f.paths {
+			if v, err := p.varUint(path[0], path[1]); err == nil {
+				f.set(h, v)
+				break
+			}
+		}
+	}
+	return h
+}
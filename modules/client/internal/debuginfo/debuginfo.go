@@ -0,0 +1,244 @@
+// Package debuginfo reads the types and the package-level variables of Go
+// binaries from their DWARF debugging information.
+package debuginfo
+
+import (
+	"debug/dwarf"
+	"regexp"
+	"sort"
+)
+
+// attrGoKind is the DW_AT_go_kind attribute, the reflect.Kind of the
+// types written by the Go linker.
+const attrGoKind dwarf.Attr = 0x2900
+
+// opAddr is the DW_OP_addr operation locating the variables.
+const opAddr = 0x03
+
+// Global is a package-level variable.
+type Global struct {
+	Name string
+	Addr uint64 // link-time address
+	Type dwarf.Type
+}
+
+// Globals returns the package-level variables whose name matches re,
+// sorted by address. A nil re matches all the variables.
+func Globals(d *dwarf.Data, re *regexp.Regexp) ([]Global, error) {
+	var globals []Global
+	r := d.Reader()
+	for {
+		e, err := r.Next()
+		if err != nil {
+			return nil, err
+		}
+		if e == nil {
+			break
+		}
+		switch e.Tag {
+		case dwarf.TagCompileUnit:
+			continue
+		case dwarf.TagVariable:
+		default:
+			// Skip the locals of the functions.
+			r.SkipChildren()
+			continue
+		}
+		name, _ := e.Val(dwarf.AttrName).(string)
+		loc, _ := e.Val(dwarf.AttrLocation).([]byte)
+		off, ok := e.Val(dwarf.AttrType).(dwarf.Offset)
+		if name == "" || !ok || (re != nil && !re.MatchString(name)) {
+			continue
+		}
+		size := r.AddressSize()
+		if len(loc) != 1+size || loc[0] != opAddr {
+			continue
+		}
+		var addr uint64
+		if size == 4 {
+			addr = uint64(r.ByteOrder().Uint32(loc[1:]))
+		} else {
+			addr = r.ByteOrder().Uint64(loc[1:])
+		}
+		t, err := d.Type(off)
+		if err != nil {
+			return nil, err
+		}
+		globals = append(globals, Global{name, addr, t})
+	}
+	sort.Slice(globals, func(i, j int) bool {
+		return globals[i].Addr < globals[j].Addr
+	})
+	return globals, nil
+}
+
+// Structs returns the Go struct types whose name matches re, sorted by
+// name. A nil re matches all the structs.
+func Structs(d *dwarf.Data, re *regexp.Regexp) ([]*dwarf.StructType, error) {
+	var structs []*dwarf.StructType
+	seen := make(map[string]bool)
+	r := d.Reader()
+	for {
+		e, err := r.Next()
+		if err != nil {
+			return nil, err
+		}
+		if e == nil {
+			break
+		}
+		if e.Tag != dwarf.TagStructType {
+			if e.Tag != dwarf.TagCompileUnit {
+				r.SkipChildren()
+			}
+			continue
+		}
+		r.SkipChildren()
+		name, _ := e.Val(dwarf.AttrName).(string)
+		// DWARF also describes slices, strings and interfaces as
+		// structures, the kind of the Go linker tells them apart.
+		if kind, ok := e.Val(attrGoKind).(int64); ok && kind != kindStruct {
+			continue
+		}
+		if name == "" || seen[name] || (re != nil && !re.MatchString(name)) {
+			continue
+		}
+		seen[name] = true
+		t, err := d.Type(e.Offset)
+		if err != nil {
+			return nil, err
+		}
+		if st, ok := t.(*dwarf.StructType); ok && !st.Incomplete {
+			structs = append(structs, st)
+		}
+	}
+	sort.Slice(structs, func(i, j int) bool {
+		return structs[i].StructName < structs[j].StructName
+	})
+	return structs, nil
+}
+
+// TypeName returns the name of t as written in Go.
+func TypeName(t dwarf.Type) string {
+	if st, ok := t.(*dwarf.StructType); ok && st.StructName != "" {
+		return st.StructName
+	}
+	if name := t.Common().Name; name != "" {
+		return name
+	}
+	return t.String()
+}
+
+// Align returns the alignment of t on a platform with pointers of
+// ptrSize bytes.
+func Align(t dwarf.Type, ptrSize int64) int64 {
+	switch t := t.(type) {
+	case *dwarf.TypedefType:
+		return Align(t.Type, ptrSize)
+	case *dwarf.StructType:
+		align := int64(1)
+		for _, f := range t.Field {
+			if a := Align(f.Type, ptrSize); a > align {
+				align = a
+			}
+		}
+		return align
+	case *dwarf.ArrayType:
+		return Align(t.Type, ptrSize)
+	case *dwarf.ComplexType:
+		return clampAlign(t.ByteSize/2, ptrSize)
+	}
+	return clampAlign(t.Size(), ptrSize)
+}
+
+func clampAlign(size, ptrSize int64) int64 {
+	switch {
+	case size < 1:
+		return 1
+	case size > ptrSize:
+		return ptrSize
+	}
+	return size
+}
+
+// Field is a field of a struct layout.
+type Field struct {
+	Name    string
+	Type    string
+	Offset  int64
+	Size    int64
+	Padding int64 // bytes between the field and the next one, or the end
+}
+
+// Layout is the memory layout of a struct.
+type Layout struct {
+	Name    string
+	Size    int64
+	Align   int64
+	Fields  []Field
+	Padding int64 // total padding
+	Optimal int64 // size with the fields sorted by decreasing alignment
+}
+
+// StructLayout returns the layout of st on a platform with pointers of
+// ptrSize bytes.
+func StructLayout(st *dwarf.StructType, ptrSize int64) *Layout {
+	l := &Layout{
+		Name:  st.StructName,
+		Size:  st.ByteSize,
+		Align: Align(st, ptrSize),
+	}
+	for i, f := range st.Field {
+		end := st.ByteSize
+		if i+1 < len(st.Field) {
+			end = st.Field[i+1].ByteOffset
+		}
+		size := f.Type.Size()
+		field := Field{
+			Name:    f.Name,
+			Type:    TypeName(f.Type),
+			Offset:  f.ByteOffset,
+			Size:    size,
+			Padding: end - f.ByteOffset - size,
+		}
+		if field.Padding < 0 {
+			field.Padding = 0
+		}
+		l.Padding += field.Padding
+		l.Fields = append(l.Fields, field)
+	}
+	if len(st.Field) == 0 {
+		l.Padding = st.ByteSize
+	}
+
+	// Sorting by decreasing alignment leaves no padding between the
+	// fields. The zero-size fields go first, since the compiler pads a
+	// final one so that its address doesn't point past the struct.
+	fields := make([]*dwarf.StructField, len(st.Field))
+	copy(fields, st.Field)
+	sort.SliceStable(fields, func(i, j int) bool {
+		si, sj := fields[i].Type.Size(), fields[j].Type.Size()
+		if (si == 0) != (sj == 0) {
+			return si == 0
+		}
+		return Align(fields[i].Type, ptrSize) > Align(fields[j].Type, ptrSize)
+	})
+	var size int64
+	for _, f := range fields {
+		a := Align(f.Type, ptrSize)
+		size = (size+a-1)/a*a + f.Type.Size()
+	}
+	l.Optimal = (size + l.Align - 1) / l.Align * l.Align
+	if l.Optimal > l.Size {
+		l.Optimal = l.Size
+	}
+	return l
+}
+
+// PtrSize returns the size of the pointers of the binary of d.
+func PtrSize(d *dwarf.Data) (int64, error) {
+	r := d.Reader()
+	if _, err := r.Next(); err != nil {
+		return 0, err
+	}
+	return int64(r.AddressSize()), nil
+}
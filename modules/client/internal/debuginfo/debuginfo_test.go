@@ -0,0 +1,217 @@
+package debuginfo
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/wgliang/opengacm/modules/client/internal/objfile"
+)
+
+const testProgram = `package main
+
+import (
+	"fmt"
+	"time"
+)
+
+type Padded struct {
+	A bool
+	B int64
+	C bool
+	D int32
+	E struct{}
+}
+
+type Config struct {
+	Name   string
+	Tags   []string
+	Limits map[string]int
+	Next   *Config
+	Any    interface{}
+}
+
+var (
+	Global Padded
+	Cfg    *Config
+	Big    map[int]int
+)
+
+func main() {
+	Global.B = -7
+	Cfg = &Config{Name: "demo", Tags: []string{"a", "b"}, Limits: map[string]int{"x": 1}, Any: 42}
+	Cfg.Next = Cfg
+	Big = make(map[int]int)
+	for i := 0; i < 5000; i++ {
+		Big[i] = i
+	}
+	fmt.Println("ready")
+	time.Sleep(time.Minute)
+}
+`
+
+// buildTestProgram builds testProgram with its DWARF information, which
+// test binaries lack.
+func buildTestProgram(t *testing.T) string {
+	gotool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not found")
+	}
+	dir, err := ioutil.TempDir("", "debuginfo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	if err := ioutil.WriteFile(filepath.Join(dir, "main.go"), []byte(testProgram), 0644); err != nil {
+		t.Fatal(err)
+	}
+	exe := filepath.Join(dir, "prog")
+	cmd := exec.Command(gotool, "build", "-buildmode=exe", "-o", exe, "main.go")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GO111MODULE=off", "GOFLAGS=")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Skipf("go build: %v\n%s", err, out)
+	}
+	return exe
+}
+
+func TestDebugInfo(t *testing.T) {
+	f, err := objfile.Open(buildTestProgram(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	d, err := f.DWARF()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ptrSize, err := PtrSize(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	structs, err := Structs(d, regexp.MustCompile(`^main\.P`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(structs) != 1 || structs[0].StructName != "main.Padded" {
+		t.Fatalf("Structs(^main.P) = %v; want main.Padded", structs)
+	}
+	l := StructLayout(structs[0], ptrSize)
+	if ptrSize == 8 {
+		// A at 0, B at 8, C at 16, D at 20 and E at 24, padded to 32.
+		// Reordered as E, B, D, A and C, it fits in 16 bytes.
+		if l.Size != 32 || l.Align != 8 || l.Padding != 18 || l.Optimal != 16 {
+			t.Errorf("layout of main.Padded: size %d, align %d, padding %d, optimal %d; want 32, 8, 18, 16",
+				l.Size, l.Align, l.Padding, l.Optimal)
+		}
+	}
+	var names []string
+	for _, f := range l.Fields {
+		names = append(names, f.Name+" "+f.Type)
+	}
+	if want := []string{"A bool", "B int64", "C bool", "D int32", "E struct {}"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("fields of main.Padded = %q; want %q", names, want)
+	}
+
+	globals, err := Globals(d, regexp.MustCompile(`^main\.Global$`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(globals) != 1 || globals[0].Addr == 0 || TypeName(globals[0].Type) != "main.Padded" {
+		t.Fatalf("Globals(main.Global) = %+v; want one main.Padded variable", globals)
+	}
+	syms, err := f.Symbols()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range syms {
+		if s.Name == "main.Global" && s.Addr != globals[0].Addr {
+			t.Errorf("main.Global at %#x; symbol at %#x", globals[0].Addr, s.Addr)
+		}
+	}
+}
+
+func TestPrinter(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("reads the memory of processes from /proc")
+	}
+	exe := buildTestProgram(t)
+	cmd := exec.Command(exe)
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer cmd.Wait()
+	defer cmd.Process.Kill()
+	if _, err := bufio.NewReader(out).ReadString('\n'); err != nil {
+		t.Fatal(err)
+	}
+	mem, err := os.Open(fmt.Sprintf("/proc/%d/mem", cmd.Process.Pid))
+	if err != nil {
+		t.Skip(err)
+	}
+	defer mem.Close()
+
+	f, err := objfile.Open(exe)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	d, err := f.DWARF()
+	if err != nil {
+		t.Fatal(err)
+	}
+	syms, err := f.Symbols()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var types uint64
+	for _, s := range syms {
+		if s.Name == "runtime.types" {
+			types = s.Addr
+		}
+	}
+	globals, err := Globals(d, regexp.MustCompile(`^main\.(Global|Cfg|Big)$`))
+	if err != nil || len(globals) != 3 {
+		t.Fatalf("Globals(main.Global|main.Cfg|main.Big) = %v, %v", globals, err)
+	}
+	p, err := NewPrinter(d, mem, types)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.MaxDepth = 2
+	p.MaxElems = 4
+	var b strings.Builder
+	for _, g := range globals {
+		if err := p.Fprint(&b, g.Type, g.Addr); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, want := range []string{
+		"B: -7,",
+		`Name: "demo",`,
+		`Tags: []string{"a", "b"},`,
+		`Limits: map[string]int{"x": 1},`,
+		"Next: (*main.Config)(0x",
+		"Any: int(42),",
+		"Any: int(at 0x",
+		// The map of several tables is cut after MaxElems entries.
+		"...+4996 more}",
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("printed values lack %q:\n%s", want, b.String())
+		}
+	}
+}
@@ -0,0 +1,804 @@
+package debuginfo
+
+import (
+	"bytes"
+	"debug/dwarf"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Attributes of the Go types written by the Go linker, besides
+// attrGoKind.
+const (
+	attrGoKey         dwarf.Attr = 0x2901
+	attrGoElem        dwarf.Attr = 0x2902
+	attrGoRuntimeType dwarf.Attr = 0x2904
+)
+
+// Values of attrGoKind, from reflect.Kind.
+const (
+	kindBool = 1 + iota
+	kindInt
+	kindInt8
+	kindInt16
+	kindInt32
+	kindInt64
+	kindUint
+	kindUint8
+	kindUint16
+	kindUint32
+	kindUint64
+	kindUintptr
+	kindFloat32
+	kindFloat64
+	kindComplex64
+	kindComplex128
+	kindArray
+	kindChan
+	kindFunc
+	kindInterface
+	kindMap
+	kindPtr
+	kindSlice
+	kindString
+	kindStruct
+	kindUnsafePointer
+)
+
+// Layout of the maps of Go 1.24 and later, from internal/runtime/maps.
+const (
+	mapGroupSlots       = 8
+	mapMaxTableCapacity = 1024
+	mapMaxKeySize       = 128 // larger keys and elements are stored indirectly
+	mapCtrlEmpty        = 0x80
+	mapGroupPrefix      = "noalg.map.group["
+)
+
+// goType is what the Go linker records about a type, beside its DWARF
+// description.
+type goType struct {
+	kind      int64
+	key, elem dwarf.Offset
+}
+
+// Printer prints the values in the memory of a Go process, following
+// their pointers to a bounded depth. The memory is read while the
+// process runs, so values being modified may be printed inconsistently.
+type Printer struct {
+	MaxDepth  int // number of pointers followed
+	MaxElems  int // number of elements printed of arrays, slices and maps
+	MaxString int // number of bytes printed of strings
+
+	// FuncName returns the name of the function at pc in the process,
+	// or "" if it is unknown. It may be nil.
+	FuncName func(pc uint64) string
+
+	d       *dwarf.Data
+	mem     io.ReaderAt
+	order   binary.ByteOrder
+	ptrSize int64
+	types   uint64 // address of the type descriptors in the process
+
+	// Indexes of the types of d, built on first use.
+	goTypes map[dwarf.Type]goType
+	rtypes  map[uint64]dwarf.Type // by offset of their type descriptor
+	groups  map[string]*dwarf.StructType
+}
+
+// NewPrinter returns a printer of the values of mem, the memory of a
+// process running the binary of d. types is the address of the type
+// descriptors of the binary in the process, that of the runtime.types
+// symbol, used to find the dynamic types of the interfaces.
+func NewPrinter(d *dwarf.Data, mem io.ReaderAt, types uint64) (*Printer, error) {
+	r := d.Reader()
+	if _, err := r.Next(); err != nil {
+		return nil, err
+	}
+	return &Printer{
+		MaxDepth:  3,
+		MaxElems:  32,
+		MaxString: 256,
+		d:         d,
+		mem:       mem,
+		order:     r.ByteOrder(),
+		ptrSize:   int64(r.AddressSize()),
+		types:     types,
+	}, nil
+}
+
+// Fprint prints the value of type t at addr to w.
+func (p *Printer) Fprint(w io.Writer, t dwarf.Type, addr uint64) error {
+	if size := t.Size(); size > 0 {
+		if _, err := p.read(addr, size); err != nil {
+			return err
+		}
+	}
+	var b bytes.Buffer
+	p.value(&b, t, addr, p.MaxDepth, "")
+	b.WriteByte('\n')
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+func (p *Printer) read(addr uint64, n int64) ([]byte, error) {
+	b := make([]byte, n)
+	if _, err := p.mem.ReadAt(b, int64(addr)); err != nil {
+		return nil, fmt.Errorf("reading %d bytes at %#x: %v", n, addr, err)
+	}
+	return b, nil
+}
+
+func (p *Printer) readUint(addr uint64, size int64) (uint64, error) {
+	b, err := p.read(addr, size)
+	if err != nil {
+		return 0, err
+	}
+	switch size {
+	case 1:
+		return uint64(b[0]), nil
+	case 2:
+		return uint64(p.order.Uint16(b)), nil
+	case 4:
+		return uint64(p.order.Uint32(b)), nil
+	case 8:
+		return p.order.Uint64(b), nil
+	}
+	return 0, fmt.Errorf("unsupported integer size %d", size)
+}
+
+func (p *Printer) readPtr(addr uint64) (uint64, error) {
+	return p.readUint(addr, p.ptrSize)
+}
+
+// index indexes the types written by the Go linker. The types returned
+// by d are cached, so that the types of the fields and elements of the
+// values printed are the ones indexed.
+func (p *Printer) index() {
+	if p.goTypes != nil {
+		return
+	}
+	p.goTypes = make(map[dwarf.Type]goType)
+	p.rtypes = make(map[uint64]dwarf.Type)
+	p.groups = make(map[string]*dwarf.StructType)
+	r := p.d.Reader()
+	for {
+		e, err := r.Next()
+		if err != nil || e == nil {
+			break
+		}
+		if e.Tag != dwarf.TagCompileUnit {
+			r.SkipChildren()
+		}
+		kind, ok := e.Val(attrGoKind).(int64)
+		if !ok {
+			continue
+		}
+		t, err := p.d.Type(e.Offset)
+		if err != nil {
+			continue
+		}
+		gt := goType{kind: kind}
+		gt.key, _ = e.Val(attrGoKey).(dwarf.Offset)
+		gt.elem, _ = e.Val(attrGoElem).(dwarf.Offset)
+		p.goTypes[t] = gt
+		if off, ok := e.Val(attrGoRuntimeType).(uint64); ok && off != 0 {
+			if _, dup := p.rtypes[off]; !dup {
+				p.rtypes[off] = t
+			}
+		}
+		if st, ok := t.(*dwarf.StructType); ok && strings.HasPrefix(st.StructName, mapGroupPrefix) {
+			p.groups[st.StructName] = st
+		}
+	}
+}
+
+// goType returns what the Go linker recorded about t, looking through
+// the typedefs naming struct types.
+func (p *Printer) goType(t dwarf.Type) goType {
+	p.index()
+	for {
+		if gt, ok := p.goTypes[t]; ok {
+			return gt
+		}
+		td, ok := t.(*dwarf.TypedefType)
+		if !ok {
+			return goType{}
+		}
+		t = td.Type
+	}
+}
+
+// kind returns the reflect.Kind of t. Types not written by the Go
+// linker, such as the ones of C code, get the closest Go kind.
+func (p *Printer) kind(t dwarf.Type) int64 {
+	if k := p.goType(t).kind; k != 0 {
+		return k
+	}
+	switch t := underlying(t).(type) {
+	case *dwarf.BoolType:
+		return kindBool
+	case *dwarf.IntType, *dwarf.CharType:
+		return kindInt
+	case *dwarf.UintType, *dwarf.UcharType:
+		return kindUint
+	case *dwarf.FloatType:
+		if t.ByteSize == 4 {
+			return kindFloat32
+		}
+		return kindFloat64
+	case *dwarf.PtrType:
+		if _, ok := t.Type.(*dwarf.VoidType); ok || t.Type == nil {
+			return kindUnsafePointer
+		}
+		return kindPtr
+	case *dwarf.ArrayType:
+		return kindArray
+	case *dwarf.StructType:
+		return kindStruct
+	}
+	return 0
+}
+
+// underlying returns t without its typedefs.
+func underlying(t dwarf.Type) dwarf.Type {
+	for {
+		td, ok := t.(*dwarf.TypedefType)
+		if !ok {
+			return t
+		}
+		t = td.Type
+	}
+}
+
+// field returns the field name of the struct type t.
+func field(t dwarf.Type, name string) *dwarf.StructField {
+	if st, ok := underlying(t).(*dwarf.StructType); ok {
+		for _, f := range st.Field {
+			if f.Name == name {
+				return f
+			}
+		}
+	}
+	return nil
+}
+
+// elemType returns the type pointed to by the pointer type t.
+func elemType(t dwarf.Type) dwarf.Type {
+	if pt, ok := underlying(t).(*dwarf.PtrType); ok {
+		return pt.Type
+	}
+	return nil
+}
+
+func unreadable(b *bytes.Buffer, err error) {
+	fmt.Fprintf(b, "<%v>", err)
+}
+
+func (p *Printer) value(b *bytes.Buffer, t dwarf.Type, addr uint64, depth int, indent string) {
+	size := t.Size()
+	switch p.kind(t) {
+	case kindBool:
+		v, err := p.readUint(addr, 1)
+		if err != nil {
+			unreadable(b, err)
+			return
+		}
+		b.WriteString(strconv.FormatBool(v != 0))
+	case kindInt, kindInt8, kindInt16, kindInt32, kindInt64:
+		v, err := p.readUint(addr, size)
+		if err != nil {
+			unreadable(b, err)
+			return
+		}
+		shift := uint(64 - 8*size)
+		b.WriteString(strconv.FormatInt(int64(v<<shift)>>shift, 10))
+	case kindUint, kindUint8, kindUint16, kindUint32, kindUint64:
+		v, err := p.readUint(addr, size)
+		if err != nil {
+			unreadable(b, err)
+			return
+		}
+		b.WriteString(strconv.FormatUint(v, 10))
+	case kindUintptr, kindUnsafePointer:
+		v, err := p.readUint(addr, size)
+		if err != nil {
+			unreadable(b, err)
+			return
+		}
+		fmt.Fprintf(b, "%#x", v)
+	case kindFloat32, kindFloat64:
+		b.WriteString(p.float(addr, size))
+	case kindComplex64, kindComplex128:
+		fmt.Fprintf(b, "(%s+%si)", p.float(addr, size/2), p.float(addr+uint64(size/2), size/2))
+	case kindString:
+		p.str(b, addr)
+	case kindSlice:
+		p.slice(b, t, addr, depth, indent)
+	case kindArray:
+		at, ok := underlying(t).(*dwarf.ArrayType)
+		if !ok {
+			fmt.Fprintf(b, "%s{?}", TypeName(t))
+			return
+		}
+		stride := at.Type.Size()
+		p.elems(b, TypeName(t), at.Count, at.Count, at.Type, func(i int64) uint64 {
+			return addr + uint64(i*stride)
+		}, depth, indent)
+	case kindStruct:
+		p.fields(b, t, addr, depth, indent)
+	case kindPtr:
+		ptr, err := p.readPtr(addr)
+		switch {
+		case err != nil:
+			unreadable(b, err)
+		case ptr == 0:
+			b.WriteString("nil")
+		case depth <= 0 || elemType(t) == nil:
+			fmt.Fprintf(b, "(%s)(%#x)", TypeName(t), ptr)
+		default:
+			b.WriteByte('&')
+			p.value(b, elemType(t), ptr, depth-1, indent)
+		}
+	case kindMap:
+		p.goMap(b, t, addr, depth, indent)
+	case kindChan:
+		p.channel(b, t, addr)
+	case kindFunc:
+		p.funcValue(b, t, addr)
+	case kindInterface:
+		p.iface(b, t, addr, depth, indent)
+	default:
+		data, err := p.read(addr, size)
+		if err != nil {
+			unreadable(b, err)
+			return
+		}
+		fmt.Fprintf(b, "%s(%x)", TypeName(t), data)
+	}
+}
+
+func (p *Printer) float(addr uint64, size int64) string {
+	v, err := p.readUint(addr, size)
+	if err != nil {
+		return fmt.Sprintf("<%v>", err)
+	}
+	if size == 4 {
+		return strconv.FormatFloat(float64(math.Float32frombits(uint32(v))), 'g', -1, 32)
+	}
+	return strconv.FormatFloat(math.Float64frombits(v), 'g', -1, 64)
+}
+
+func (p *Printer) str(b *bytes.Buffer, addr uint64) {
+	ptr, err := p.readPtr(addr)
+	if err != nil {
+		unreadable(b, err)
+		return
+	}
+	n, err := p.readPtr(addr + uint64(p.ptrSize))
+	if err != nil {
+		unreadable(b, err)
+		return
+	}
+	if n == 0 {
+		b.WriteString(`""`)
+		return
+	}
+	max := uint64(p.MaxString)
+	if n < max {
+		max = n
+	}
+	data, err := p.read(ptr, int64(max))
+	if err != nil {
+		unreadable(b, err)
+		return
+	}
+	b.WriteString(strconv.Quote(string(data)))
+	if max < n {
+		fmt.Fprintf(b, "...(%d bytes)", n)
+	}
+}
+
+func (p *Printer) slice(b *bytes.Buffer, t dwarf.Type, addr uint64, depth int, indent string) {
+	array := field(t, "array")
+	if array == nil || elemType(array.Type) == nil {
+		fmt.Fprintf(b, "%s{?}", TypeName(t))
+		return
+	}
+	ptr, err := p.readPtr(addr)
+	if err != nil {
+		unreadable(b, err)
+		return
+	}
+	n, err := p.readPtr(addr + uint64(p.ptrSize))
+	if err != nil {
+		unreadable(b, err)
+		return
+	}
+	c, err := p.readPtr(addr + uint64(2*p.ptrSize))
+	if err != nil {
+		unreadable(b, err)
+		return
+	}
+	if ptr == 0 {
+		b.WriteString("nil")
+		return
+	}
+	if depth <= 0 && n > 0 {
+		fmt.Fprintf(b, "%s(len %d, cap %d)", TypeName(t), n, c)
+		return
+	}
+	elem := elemType(array.Type)
+	stride := elem.Size()
+	p.elems(b, TypeName(t), int64(n), int64(n), elem, func(i int64) uint64 {
+		return ptr + uint64(i*stride)
+	}, depth-1, indent)
+}
+
+// elems prints the first elements of the n elements of a composite
+// value, followed by the number of elements omitted out of total.
+func (p *Printer) elems(b *bytes.Buffer, name string, n, total int64, elem dwarf.Type, addr func(i int64) uint64, depth int, indent string) {
+	b.WriteString(name)
+	b.WriteByte('{')
+	shown := n
+	if shown > int64(p.MaxElems) {
+		shown = int64(p.MaxElems)
+	}
+	inline := p.scalar(elem)
+	for i := int64(0); i < shown; i++ {
+		if inline {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+		} else {
+			b.WriteString("\n" + indent + "\t")
+		}
+		p.value(b, elem, addr(i), depth, indent+"\t")
+		if !inline {
+			b.WriteByte(',')
+		}
+	}
+	p.more(b, shown, total-shown, inline, indent)
+	b.WriteByte('}')
+}
+
+// more closes the elements of a composite value of which shown were
+// printed, noting the omitted ones.
+func (p *Printer) more(b *bytes.Buffer, shown, omitted int64, inline bool, indent string) {
+	if omitted > 0 {
+		switch {
+		case !inline:
+			b.WriteString("\n" + indent + "\t")
+		case shown > 0:
+			b.WriteString(", ")
+		}
+		fmt.Fprintf(b, "...+%d more", omitted)
+	}
+	if !inline && shown+omitted > 0 {
+		b.WriteString("\n" + indent)
+	}
+}
+
+// scalar reports whether the values of t are printed on a single line.
+func (p *Printer) scalar(t dwarf.Type) bool {
+	switch p.kind(t) {
+	case kindArray, kindSlice, kindStruct, kindMap, kindInterface, kindPtr, 0:
+		return false
+	}
+	return true
+}
+
+func (p *Printer) fields(b *bytes.Buffer, t dwarf.Type, addr uint64, depth int, indent string) {
+	st, ok := underlying(t).(*dwarf.StructType)
+	if !ok {
+		fmt.Fprintf(b, "%s{?}", TypeName(t))
+		return
+	}
+	b.WriteString(TypeName(t))
+	b.WriteByte('{')
+	n := 0
+	for _, f := range st.Field {
+		if f.Name == "_" && f.Type.Size() == 0 {
+			continue
+		}
+		fmt.Fprintf(b, "\n%s\t%s: ", indent, f.Name)
+		p.value(b, f.Type, addr+uint64(f.ByteOffset), depth, indent+"\t")
+		b.WriteByte(',')
+		n++
+	}
+	if n > 0 {
+		b.WriteString("\n" + indent)
+	}
+	b.WriteByte('}')
+}
+
+// goMap prints a map of Go 1.24 and later. Only the number of entries of
+// the maps of older releases is printed.
+func (p *Printer) goMap(b *bytes.Buffer, t dwarf.Type, addr uint64, depth int, indent string) {
+	m, err := p.readPtr(addr)
+	if err != nil {
+		unreadable(b, err)
+		return
+	}
+	if m == 0 {
+		b.WriteString("nil")
+		return
+	}
+	name := TypeName(t)
+	hdr := elemType(t)
+	used, dirPtr, dirLen := field(hdr, "used"), field(hdr, "dirPtr"), field(hdr, "dirLen")
+	if used == nil {
+		// The hmap of the maps of Go 1.23 and earlier.
+		if count := field(hdr, "count"); count != nil {
+			if n, err := p.readPtr(m + uint64(count.ByteOffset)); err == nil {
+				fmt.Fprintf(b, "%s(len %d)", name, n)
+				return
+			}
+		}
+		fmt.Fprintf(b, "(%s)(%#x)", name, m)
+		return
+	}
+	n, err := p.readUint(m+uint64(used.ByteOffset), used.Type.Size())
+	if err != nil {
+		unreadable(b, err)
+		return
+	}
+	gt := p.goType(t)
+	key, kerr := p.d.Type(gt.key)
+	elem, eerr := p.d.Type(gt.elem)
+	if depth <= 0 || dirPtr == nil || dirLen == nil || kerr != nil || eerr != nil {
+		fmt.Fprintf(b, "%s(len %d)", name, n)
+		return
+	}
+	group := p.group(mapGroupPrefix + TypeName(key) + "]" + TypeName(elem))
+	slots := field(group, "slots")
+	if group == nil || slots == nil {
+		fmt.Fprintf(b, "%s(len %d)", name, n)
+		return
+	}
+	slot, ok := underlying(slots.Type).(*dwarf.ArrayType)
+	if !ok {
+		fmt.Fprintf(b, "%s(len %d)", name, n)
+		return
+	}
+	slotKey, slotElem := field(slot.Type, "key"), field(slot.Type, "elem")
+	if slotKey == nil || slotElem == nil {
+		fmt.Fprintf(b, "%s(len %d)", name, n)
+		return
+	}
+
+	dir, err := p.readPtr(m + uint64(dirPtr.ByteOffset))
+	if err != nil {
+		unreadable(b, err)
+		return
+	}
+	ntables, err := p.readPtr(m + uint64(dirLen.ByteOffset))
+	if err != nil {
+		unreadable(b, err)
+		return
+	}
+
+	entry := func(slot uint64, f *dwarf.StructField, t dwarf.Type) {
+		a := slot + uint64(f.ByteOffset)
+		if t.Size() > mapMaxKeySize {
+			ptr, err := p.readPtr(a)
+			if err != nil {
+				unreadable(b, err)
+				return
+			}
+			a = ptr
+		}
+		p.value(b, t, a, depth-1, indent+"\t")
+	}
+	inline := p.scalar(key) && p.scalar(elem)
+	b.WriteString(name)
+	b.WriteByte('{')
+	shown := int64(0)
+	stride := uint64(slot.Type.Size())
+	// printGroup prints the entries of the group at g, and reports
+	// whether more entries are to be shown.
+	printGroup := func(g uint64) bool {
+		ctrl, err := p.read(g, mapGroupSlots)
+		if err != nil {
+			return true
+		}
+		for i := uint64(0); i < mapGroupSlots && shown < int64(p.MaxElems); i++ {
+			if ctrl[i]&mapCtrlEmpty != 0 {
+				continue
+			}
+			s := g + uint64(slots.ByteOffset) + i*stride
+			if inline {
+				if shown > 0 {
+					b.WriteString(", ")
+				}
+			} else {
+				b.WriteString("\n" + indent + "\t")
+			}
+			entry(s, slotKey, key)
+			b.WriteString(": ")
+			entry(s, slotElem, elem)
+			if !inline {
+				b.WriteByte(',')
+			}
+			shown++
+		}
+		return shown < int64(p.MaxElems)
+	}
+
+	// The groups are the single group of a small map, or those of the
+	// tables of the directory, some of which may appear more than once.
+	// They are read as they are printed, until enough entries are shown.
+	if ntables == 0 && dir != 0 {
+		printGroup(dir)
+	}
+	table := elemType(elemType(dirPtr.Type))
+	tableGroups, tableCap := field(table, "groups"), field(table, "capacity")
+	seen := make(map[uint64]bool)
+	more := true
+	for i := uint64(0); i < ntables && tableGroups != nil && more; i++ {
+		tab, err := p.readPtr(dir + i*uint64(p.ptrSize))
+		if err != nil || tab == 0 || seen[tab] {
+			continue
+		}
+		seen[tab] = true
+		data, err := p.readPtr(tab + uint64(tableGroups.ByteOffset))
+		if err != nil {
+			continue
+		}
+		mask, err := p.readUint(tab+uint64(tableGroups.ByteOffset+p.ptrSize), 8)
+		if err != nil {
+			continue
+		}
+		// The mask is bounded by the capacity of the table, so that
+		// a corrupt or changing table is not read past its groups.
+		ngroups := uint64(mapMaxTableCapacity / mapGroupSlots)
+		if tableCap != nil {
+			if c, err := p.readUint(tab+uint64(tableCap.ByteOffset), tableCap.Type.Size()); err == nil && c/mapGroupSlots < ngroups {
+				ngroups = c / mapGroupSlots
+			}
+		}
+		if ngroups == 0 {
+			continue
+		}
+		if mask >= ngroups {
+			mask = ngroups - 1
+		}
+		for g := uint64(0); g <= mask && more; g++ {
+			more = printGroup(data + g*uint64(group.ByteSize))
+		}
+	}
+	p.more(b, shown, int64(n)-shown, inline, indent)
+	b.WriteByte('}')
+}
+
+// group returns the struct type of the groups of a map, by name.
+func (p *Printer) group(name string) *dwarf.StructType {
+	p.index()
+	return p.groups[name]
+}
+
+func (p *Printer) channel(b *bytes.Buffer, t dwarf.Type, addr uint64) {
+	c, err := p.readPtr(addr)
+	if err != nil {
+		unreadable(b, err)
+		return
+	}
+	if c == 0 {
+		b.WriteString("nil")
+		return
+	}
+	fmt.Fprintf(b, "(%s)(%#x", TypeName(t), c)
+	hchan := elemType(t)
+	qcount, dataqsiz := field(hchan, "qcount"), field(hchan, "dataqsiz")
+	if qcount != nil && dataqsiz != nil {
+		n, err1 := p.readPtr(c + uint64(qcount.ByteOffset))
+		size, err2 := p.readPtr(c + uint64(dataqsiz.ByteOffset))
+		if err1 == nil && err2 == nil {
+			fmt.Fprintf(b, ", len %d, cap %d", n, size)
+		}
+	}
+	b.WriteByte(')')
+}
+
+func (p *Printer) funcValue(b *bytes.Buffer, t dwarf.Type, addr uint64) {
+	fv, err := p.readPtr(addr)
+	if err != nil {
+		unreadable(b, err)
+		return
+	}
+	if fv == 0 {
+		b.WriteString("nil")
+		return
+	}
+	pc, err := p.readPtr(fv)
+	if err != nil {
+		unreadable(b, err)
+		return
+	}
+	name := ""
+	if p.FuncName != nil {
+		name = p.FuncName(pc)
+	}
+	if name == "" {
+		name = fmt.Sprintf("%#x", pc)
+	}
+	fmt.Fprintf(b, "(%s)(%s)", TypeName(t), name)
+}
+
+func (p *Printer) iface(b *bytes.Buffer, t dwarf.Type, addr uint64, depth int, indent string) {
+	typ, err := p.readPtr(addr)
+	if err != nil {
+		unreadable(b, err)
+		return
+	}
+	if field(t, "tab") != nil && typ != 0 {
+		// The dynamic type of non-empty interfaces is in their itab,
+		// after the interface type.
+		if typ, err = p.readPtr(typ + uint64(p.ptrSize)); err != nil {
+			unreadable(b, err)
+			return
+		}
+	}
+	if typ == 0 {
+		b.WriteString("nil")
+		return
+	}
+	data := addr + uint64(p.ptrSize)
+	dyn := p.rtype(typ)
+	if dyn == nil {
+		ptr, _ := p.readPtr(data)
+		fmt.Fprintf(b, "%s(type %#x, data %#x)", TypeName(t), typ, ptr)
+		return
+	}
+	if p.kind(dyn) == kindPtr {
+		// Pointers print their type.
+		p.value(b, dyn, data, depth, indent)
+		return
+	}
+	b.WriteString(TypeName(dyn))
+	b.WriteByte('(')
+	switch {
+	case p.direct(dyn):
+		// The data word is the value itself.
+		p.value(b, dyn, data, depth, indent)
+	case depth <= 0:
+		ptr, _ := p.readPtr(data)
+		fmt.Fprintf(b, "at %#x", ptr)
+	default:
+		ptr, err := p.readPtr(data)
+		if err != nil {
+			unreadable(b, err)
+		} else {
+			p.value(b, dyn, ptr, depth-1, indent)
+		}
+	}
+	b.WriteByte(')')
+}
+
+// direct reports whether the values of t are stored in the data word of
+// interfaces rather than pointed to by it.
+func (p *Printer) direct(t dwarf.Type) bool {
+	switch p.kind(t) {
+	case kindPtr, kindMap, kindChan, kindFunc, kindUnsafePointer:
+		return true
+	case kindStruct:
+		st, ok := underlying(t).(*dwarf.StructType)
+		return ok && len(st.Field) == 1 && p.direct(st.Field[0].Type)
+	case kindArray:
+		at, ok := underlying(t).(*dwarf.ArrayType)
+		return ok && at.Count == 1 && p.direct(at.Type)
+	}
+	return false
+}
+
+// rtype returns the DWARF type of the type descriptor at addr, or nil.
+func (p *Printer) rtype(addr uint64) dwarf.Type {
+	p.index()
+	if addr < p.types {
+		return nil
+	}
+	return p.rtypes[addr-p.types]
+}
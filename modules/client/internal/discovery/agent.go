@@ -0,0 +1,108 @@
+package discovery
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/wgliang/opengacm/modules/client/internal"
+	"github.com/wgliang/opengacm/modules/client/internal/proc"
+)
+
+// ErrUnreachable is returned by AgentPort for the processes whose agent
+// listens in another network namespace than the caller's, such as the
+// ones of containers with their own network.
+var ErrUnreachable = errors.New("agent present, unreachable from this network namespace")
+
+// AgentPort returns the port of the agent of the process pid. The port
+// file is looked up in the configuration directory of the caller, then
+// in the one of the process' user within the process' view of the file
+// system, where the agents of containerized processes write it under
+// their PID in the container. As agents listen on the loopback interface,
+// the port is only returned if the process is in the caller's network
+// namespace; else the error is ErrUnreachable.
+func AgentPort(pid int) (string, error) {
+	port, err := internal.GetPort(pid)
+	if err == nil {
+		if same, nerr := sameNetNS(pid); nerr == nil && !same {
+			return "", ErrUnreachable
+		}
+		return parsePort(port)
+	}
+	info, perr := proc.Read(pid)
+	if perr != nil {
+		return "", err
+	}
+	// Like internal.ConfigDir, prefer the home directory
+	// of the passwd file over the environment.
+	root := proc.Path(pid, "root")
+	homes := []string{passwdHome(filepath.Join(root, "etc", "passwd"), info.UID)}
+	if home, _ := proc.Getenv(pid, "HOME"); home != "" {
+		homes = append(homes, home)
+	}
+	for _, home := range homes {
+		if home == "" {
+			continue
+		}
+		portfile := filepath.Join(root, internal.HomeConfigDir(home), strconv.Itoa(info.NSPID))
+		b, ferr := ioutil.ReadFile(portfile)
+		if ferr != nil {
+			continue
+		}
+		// The file is the container's, it is only trusted
+		// if the namespace is known to be the caller's.
+		if same, nerr := sameNetNS(pid); nerr != nil || !same {
+			return "", ErrUnreachable
+		}
+		return parsePort(string(b))
+	}
+	return "", err
+}
+
+// sameNetNS reports whether the process pid is in the network namespace
+// of the caller.
+func sameNetNS(pid int) (bool, error) {
+	ns, err := proc.NetNS(pid)
+	if err != nil {
+		return false, err
+	}
+	self, err := proc.NetNS(os.Getpid())
+	if err != nil {
+		return false, err
+	}
+	return ns == self, nil
+}
+
+// parsePort returns the port of the contents of a port file, which must
+// be a decimal TCP port.
+func parsePort(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if n, err := strconv.ParseUint(s, 10, 16); err != nil || n == 0 {
+		return "", fmt.Errorf("invalid agent port %q", s)
+	}
+	return s, nil
+}
+
+// passwdHome returns the home directory of the user uid
+// in the passwd file at path, or an empty string.
+func passwdHome(path string, uid int) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		// name:password:uid:gid:gecos:home:shell
+		fields := strings.Split(s.Text(), ":")
+		if len(fields) >= 6 && fields[2] == strconv.Itoa(uid) {
+			return fields[5]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,143 @@
+// Package discovery recognizes the Go binaries run by the processes of
+// the host, and caches what it learns about each executable so that
+// repeated scans only read the binaries that changed.
+package discovery
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/wgliang/opengacm/modules/client/internal/objfile"
+)
+
+// Binary is what is known about an executable.
+type Binary struct {
+	Go        bool   `json:"go"`
+	GoVersion string `json:"go_version,omitempty"`
+}
+
+// Inspect reads the executable at path.
+func Inspect(path string) (Binary, error) {
+	f, err := objfile.Open(path)
+	if err != nil {
+		// Not an executable the objfile package knows, such as a script.
+		return Binary{}, nil
+	}
+	defer f.Close()
+	if !f.IsGo() {
+		return Binary{}, nil
+	}
+	b := Binary{Go: true}
+	if info, err := f.BuildInfo(); err == nil {
+		b.GoVersion = info.GoVersion
+	}
+	return b, nil
+}
+
+// Cache caches the result of Inspect by device, inode and modification
+// time of the executables. It is safe for concurrent use.
+type Cache struct {
+	path string
+
+	mu      sync.Mutex
+	entries map[string]Binary
+	used    map[string]bool
+	dirty   bool
+}
+
+// DefaultCachePath returns the path of the cache file shared by the
+// commands of the client.
+func DefaultCachePath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "opengacm", "discovery.json"), nil
+}
+
+// OpenCache returns the cache stored in the file at path. A missing or
+// unreadable file results in an empty cache. An empty path results in a
+// cache that is only kept in memory.
+func OpenCache(path string) *Cache {
+	c := &Cache{
+		path:    path,
+		entries: make(map[string]Binary),
+		used:    make(map[string]bool),
+	}
+	if path == "" {
+		return c
+	}
+	if b, err := ioutil.ReadFile(path); err == nil {
+		if err := json.Unmarshal(b, &c.entries); err != nil {
+			c.entries = make(map[string]Binary)
+		}
+	}
+	return c
+}
+
+// Lookup returns what is known about the executable at path,
+// inspecting it if it is not cached or has changed.
+func (c *Cache) Lookup(path string) (Binary, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return Binary{}, err
+	}
+	k := key(fi)
+
+	c.mu.Lock()
+	b, ok := c.entries[k]
+	c.used[k] = true
+	c.mu.Unlock()
+	if ok {
+		return b, nil
+	}
+
+	b, err = Inspect(path)
+	if err != nil {
+		return Binary{}, err
+	}
+	c.mu.Lock()
+	c.entries[k] = b
+	c.dirty = true
+	c.mu.Unlock()
+	return b, nil
+}
+
+// Save writes the entries looked up since the previous save to the cache
+// file, dropping the executables that are no longer run.
+func (c *Cache) Save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for k := range c.entries {
+		if !c.used[k] {
+			delete(c.entries, k)
+			c.dirty = true
+		}
+	}
+	c.used = make(map[string]bool)
+	if c.path == "" || !c.dirty {
+		return nil
+	}
+	b, err := json.Marshal(c.entries)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return err
+	}
+	// Write and rename, concurrent clients must not read a partial file.
+	tmp := fmt.Sprintf("%s.%d", c.path, os.Getpid())
+	if err := ioutil.WriteFile(tmp, b, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, c.path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	c.dirty = false
+	return nil
+}
@@ -0,0 +1,119 @@
+package discovery
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestInspect(t *testing.T) {
+	b, err := Inspect(os.Args[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !b.Go || b.GoVersion == "" {
+		t.Errorf("Inspect(test binary) = %+v; want a Go binary with its version", b)
+	}
+}
+
+func TestCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "discovery")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	script := filepath.Join(dir, "script")
+	if err := ioutil.WriteFile(script, []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "cache", "discovery.json")
+
+	c := OpenCache(path)
+	for _, exe := range []string{os.Args[0], script} {
+		if _, err := c.Lookup(exe); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := c.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	c = OpenCache(path)
+	if len(c.entries) != 2 {
+		t.Fatalf("reopened cache has %d entries; want 2", len(c.entries))
+	}
+	b, err := c.Lookup(os.Args[0])
+	if err != nil || !b.Go {
+		t.Errorf("cached Lookup(test binary) = %+v, %v", b, err)
+	}
+	if c.dirty {
+		t.Error("a cache hit modified the cache")
+	}
+
+	// A modified executable is inspected again, and the stale entry
+	// of the script is dropped along with it on save.
+	mtime := time.Now().Add(time.Hour)
+	if err := os.Chtimes(script, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Lookup(script); err != nil {
+		t.Fatal(err)
+	}
+	if !c.dirty {
+		t.Error("Lookup of a modified executable was a cache hit")
+	}
+	if err := c.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if len(c.entries) != 2 {
+		t.Errorf("saved cache has %d entries; want 2", len(c.entries))
+	}
+}
+
+func TestPasswdHome(t *testing.T) {
+	dir, err := ioutil.TempDir("", "discovery")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	passwd := filepath.Join(dir, "passwd")
+	data := "root:x:0:0:root:/root:/bin/sh\napp:x:1000:1000::/home/app:/bin/sh\n"
+	if err := ioutil.WriteFile(passwd, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for uid, want := range map[int]string{0: "/root", 1000: "/home/app", 1001: ""} {
+		if got := passwdHome(passwd, uid); got != want {
+			t.Errorf("passwdHome(%d) = %q; want %q", uid, got, want)
+		}
+	}
+}
+
+func TestParsePort(t *testing.T) {
+	for s, want := range map[string]string{
+		"4000\n":  "4000",
+		"65535":   "65535",
+		"0":       "",
+		"65536":   "",
+		"-1":      "",
+		"+80":     "",
+		"80 81":   "",
+		"1.2.3.4": "",
+		"":        "",
+	} {
+		got, err := parsePort(s)
+		if got != want || (err == nil) != (want != "") {
+			t.Errorf("parsePort(%q) = %q, %v; want %q", s, got, err, want)
+		}
+	}
+}
+
+func TestSameNetNS(t *testing.T) {
+	if _, err := os.Stat("/proc/self/ns/net"); err != nil {
+		t.Skip("no network namespaces")
+	}
+	if same, err := sameNetNS(os.Getpid()); err != nil || !same {
+		t.Errorf("sameNetNS(self) = %v, %v; want true", same, err)
+	}
+}
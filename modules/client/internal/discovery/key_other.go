@@ -0,0 +1,15 @@
+//go:build windows || plan9
+// +build windows plan9
+
+package discovery
+
+import (
+	"fmt"
+	"os"
+)
+
+// key identifies the content of a file by its name, size and
+// modification time, the file system has no inode numbers.
+func key(fi os.FileInfo) string {
+	return fmt.Sprintf("%s:%d:%d", fi.Name(), fi.Size(), fi.ModTime().UnixNano())
+}
@@ -0,0 +1,19 @@
+//go:build !windows && !plan9
+// +build !windows,!plan9
+
+package discovery
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// key identifies the content of a file by its device, inode
+// and modification time.
+func key(fi os.FileInfo) string {
+	if st, ok := fi.Sys().(*syscall.Stat_t); ok {
+		return fmt.Sprintf("%d:%d:%d", uint64(st.Dev), uint64(st.Ino), fi.ModTime().UnixNano())
+	}
+	return fmt.Sprintf("%s:%d:%d", fi.Name(), fi.Size(), fi.ModTime().UnixNano())
+}
@@ -0,0 +1,1115 @@
+package goobj
+
+// go124Builtins are the runtime symbols referred to by index rather than by
+// name in Go 1.24 object files, as listed by cmd/internal/goobj/builtinlist.go.
+var go124Builtins = [...]string{
+	"runtime.newobject",
+	"runtime.mallocgc",
+	"runtime.panicdivide",
+	"runtime.panicshift",
+	"runtime.panicmakeslicelen",
+	"runtime.panicmakeslicecap",
+	"runtime.throwinit",
+	"runtime.panicwrap",
+	"runtime.gopanic",
+	"runtime.gorecover",
+	"runtime.goschedguarded",
+	"runtime.goPanicIndex",
+	"runtime.goPanicIndexU",
+	"runtime.goPanicSliceAlen",
+	"runtime.goPanicSliceAlenU",
+	"runtime.goPanicSliceAcap",
+	"runtime.goPanicSliceAcapU",
+	"runtime.goPanicSliceB",
+	"runtime.goPanicSliceBU",
+	"runtime.goPanicSlice3Alen",
+	"runtime.goPanicSlice3AlenU",
+	"runtime.goPanicSlice3Acap",
+	"runtime.goPanicSlice3AcapU",
+	"runtime.goPanicSlice3B",
+	"runtime.goPanicSlice3BU",
+	"runtime.goPanicSlice3C",
+	"runtime.goPanicSlice3CU",
+	"runtime.goPanicSliceConvert",
+	"runtime.printbool",
+	"runtime.printfloat",
+	"runtime.printint",
+	"runtime.printhex",
+	"runtime.printuint",
+	"runtime.printcomplex",
+	"runtime.printstring",
+	"runtime.printpointer",
+	"runtime.printuintptr",
+	"runtime.printiface",
+	"runtime.printeface",
+	"runtime.printslice",
+	"runtime.printnl",
+	"runtime.printsp",
+	"runtime.printlock",
+	"runtime.printunlock",
+	"runtime.concatstring2",
+	"runtime.concatstring3",
+	"runtime.concatstring4",
+	"runtime.concatstring5",
+	"runtime.concatstrings",
+	"runtime.concatbyte2",
+	"runtime.concatbyte3",
+	"runtime.concatbyte4",
+	"runtime.concatbyte5",
+	"runtime.concatbytes",
+	"runtime.cmpstring",
+	"runtime.intstring",
+	"runtime.slicebytetostring",
+	"runtime.slicebytetostringtmp",
+	"runtime.slicerunetostring",
+	"runtime.stringtoslicebyte",
+	"runtime.stringtoslicerune",
+	"runtime.slicecopy",
+	"runtime.decoderune",
+	"runtime.countrunes",
+	"runtime.convT",
+	"runtime.convTnoptr",
+	"runtime.convT16",
+	"runtime.convT32",
+	"runtime.convT64",
+	"runtime.convTstring",
+	"runtime.convTslice",
+	"runtime.assertE2I",
+	"runtime.assertE2I2",
+	"runtime.panicdottypeE",
+	"runtime.panicdottypeI",
+	"runtime.panicnildottype",
+	"runtime.typeAssert",
+	"runtime.interfaceSwitch",
+	"runtime.ifaceeq",
+	"runtime.efaceeq",
+	"runtime.panicrangestate",
+	"runtime.deferrangefunc",
+	"runtime.rand",
+	"runtime.rand32",
+	"runtime.makemap64",
+	"runtime.makemap",
+	"runtime.makemap_small",
+	"runtime.mapaccess1",
+	"runtime.mapaccess1_fast32",
+	"runtime.mapaccess1_fast64",
+	"runtime.mapaccess1_faststr",
+	"runtime.mapaccess1_fat",
+	"runtime.mapaccess2",
+	"runtime.mapaccess2_fast32",
+	"runtime.mapaccess2_fast64",
+	"runtime.mapaccess2_faststr",
+	"runtime.mapaccess2_fat",
+	"runtime.mapassign",
+	"runtime.mapassign_fast32",
+	"runtime.mapassign_fast32ptr",
+	"runtime.mapassign_fast64",
+	"runtime.mapassign_fast64ptr",
+	"runtime.mapassign_faststr",
+	"runtime.mapiterinit",
+	"runtime.mapIterStart",
+	"runtime.mapdelete",
+	"runtime.mapdelete_fast32",
+	"runtime.mapdelete_fast64",
+	"runtime.mapdelete_faststr",
+	"runtime.mapiternext",
+	"runtime.mapIterNext",
+	"runtime.mapclear",
+	"runtime.makechan64",
+	"runtime.makechan",
+	"runtime.chanrecv1",
+	"runtime.chanrecv2",
+	"runtime.chansend1",
+	"runtime.closechan",
+	"runtime.chanlen",
+	"runtime.chancap",
+	"runtime.writeBarrier",
+	"runtime.typedmemmove",
+	"runtime.typedmemclr",
+	"runtime.typedslicecopy",
+	"runtime.selectnbsend",
+	"runtime.selectnbrecv",
+	"runtime.selectsetpc",
+	"runtime.selectgo",
+	"runtime.block",
+	"runtime.makeslice",
+	"runtime.makeslice64",
+	"runtime.makeslicecopy",
+	"runtime.growslice",
+	"runtime.unsafeslicecheckptr",
+	"runtime.panicunsafeslicelen",
+	"runtime.panicunsafeslicenilptr",
+	"runtime.unsafestringcheckptr",
+	"runtime.panicunsafestringlen",
+	"runtime.panicunsafestringnilptr",
+	"runtime.memmove",
+	"runtime.memclrNoHeapPointers",
+	"runtime.memclrHasPointers",
+	"runtime.memequal",
+	"runtime.memequal0",
+	"runtime.memequal8",
+	"runtime.memequal16",
+	"runtime.memequal32",
+	"runtime.memequal64",
+	"runtime.memequal128",
+	"runtime.f32equal",
+	"runtime.f64equal",
+	"runtime.c64equal",
+	"runtime.c128equal",
+	"runtime.strequal",
+	"runtime.interequal",
+	"runtime.nilinterequal",
+	"runtime.memhash",
+	"runtime.memhash0",
+	"runtime.memhash8",
+	"runtime.memhash16",
+	"runtime.memhash32",
+	"runtime.memhash64",
+	"runtime.memhash128",
+	"runtime.f32hash",
+	"runtime.f64hash",
+	"runtime.c64hash",
+	"runtime.c128hash",
+	"runtime.strhash",
+	"runtime.interhash",
+	"runtime.nilinterhash",
+	"runtime.int64div",
+	"runtime.uint64div",
+	"runtime.int64mod",
+	"runtime.uint64mod",
+	"runtime.float64toint64",
+	"runtime.float64touint64",
+	"runtime.float64touint32",
+	"runtime.int64tofloat64",
+	"runtime.int64tofloat32",
+	"runtime.uint64tofloat64",
+	"runtime.uint64tofloat32",
+	"runtime.uint32tofloat64",
+	"runtime.complex128div",
+	"runtime.racefuncenter",
+	"runtime.racefuncexit",
+	"runtime.raceread",
+	"runtime.racewrite",
+	"runtime.racereadrange",
+	"runtime.racewriterange",
+	"runtime.msanread",
+	"runtime.msanwrite",
+	"runtime.msanmove",
+	"runtime.asanread",
+	"runtime.asanwrite",
+	"runtime.checkptrAlignment",
+	"runtime.checkptrArithmetic",
+	"runtime.libfuzzerTraceCmp1",
+	"runtime.libfuzzerTraceCmp2",
+	"runtime.libfuzzerTraceCmp4",
+	"runtime.libfuzzerTraceCmp8",
+	"runtime.libfuzzerTraceConstCmp1",
+	"runtime.libfuzzerTraceConstCmp2",
+	"runtime.libfuzzerTraceConstCmp4",
+	"runtime.libfuzzerTraceConstCmp8",
+	"runtime.libfuzzerHookStrCmp",
+	"runtime.libfuzzerHookEqualFold",
+	"runtime.addCovMeta",
+	"runtime.x86HasPOPCNT",
+	"runtime.x86HasSSE41",
+	"runtime.x86HasFMA",
+	"runtime.armHasVFPv4",
+	"runtime.arm64HasATOMICS",
+	"runtime.loong64HasLAMCAS",
+	"runtime.loong64HasLAM_BH",
+	"runtime.loong64HasLSX",
+	"runtime.asanregisterglobals",
+	"runtime.deferproc",
+	"runtime.deferprocStack",
+	"runtime.deferreturn",
+	"runtime.newproc",
+	"runtime.panicoverflow",
+	"runtime.sigpanic",
+	"runtime.gcWriteBarrier",
+	"runtime.duffzero",
+	"runtime.duffcopy",
+	"runtime.morestack",
+	"runtime.morestackc",
+	"runtime.morestack_noctxt",
+	"type:int8",
+	"type:*int8",
+	"type:uint8",
+	"type:*uint8",
+	"type:int16",
+	"type:*int16",
+	"type:uint16",
+	"type:*uint16",
+	"type:int32",
+	"type:*int32",
+	"type:uint32",
+	"type:*uint32",
+	"type:int64",
+	"type:*int64",
+	"type:uint64",
+	"type:*uint64",
+	"type:float32",
+	"type:*float32",
+	"type:float64",
+	"type:*float64",
+	"type:complex64",
+	"type:*complex64",
+	"type:complex128",
+	"type:*complex128",
+	"type:unsafe.Pointer",
+	"type:*unsafe.Pointer",
+	"type:uintptr",
+	"type:*uintptr",
+	"type:bool",
+	"type:*bool",
+	"type:string",
+	"type:*string",
+	"type:error",
+	"type:*error",
+	"type:func(error) string",
+	"type:*func(error) string",
+}
+
+// go125Builtins are the runtime symbols referred to by index rather than by
+// name in Go 1.25 object files, as listed by cmd/internal/goobj/builtinlist.go.
+var go125Builtins = [...]string{
+	"runtime.newobject",
+	"runtime.mallocgc",
+	"runtime.panicdivide",
+	"runtime.panicshift",
+	"runtime.panicmakeslicelen",
+	"runtime.panicmakeslicecap",
+	"runtime.throwinit",
+	"runtime.panicwrap",
+	"runtime.gopanic",
+	"runtime.gorecover",
+	"runtime.goschedguarded",
+	"runtime.goPanicIndex",
+	"runtime.goPanicIndexU",
+	"runtime.goPanicSliceAlen",
+	"runtime.goPanicSliceAlenU",
+	"runtime.goPanicSliceAcap",
+	"runtime.goPanicSliceAcapU",
+	"runtime.goPanicSliceB",
+	"runtime.goPanicSliceBU",
+	"runtime.goPanicSlice3Alen",
+	"runtime.goPanicSlice3AlenU",
+	"runtime.goPanicSlice3Acap",
+	"runtime.goPanicSlice3AcapU",
+	"runtime.goPanicSlice3B",
+	"runtime.goPanicSlice3BU",
+	"runtime.goPanicSlice3C",
+	"runtime.goPanicSlice3CU",
+	"runtime.goPanicSliceConvert",
+	"runtime.printbool",
+	"runtime.printfloat",
+	"runtime.printint",
+	"runtime.printhex",
+	"runtime.printuint",
+	"runtime.printcomplex",
+	"runtime.printstring",
+	"runtime.printpointer",
+	"runtime.printuintptr",
+	"runtime.printiface",
+	"runtime.printeface",
+	"runtime.printslice",
+	"runtime.printnl",
+	"runtime.printsp",
+	"runtime.printlock",
+	"runtime.printunlock",
+	"runtime.concatstring2",
+	"runtime.concatstring3",
+	"runtime.concatstring4",
+	"runtime.concatstring5",
+	"runtime.concatstrings",
+	"runtime.concatbyte2",
+	"runtime.concatbyte3",
+	"runtime.concatbyte4",
+	"runtime.concatbyte5",
+	"runtime.concatbytes",
+	"runtime.cmpstring",
+	"runtime.intstring",
+	"runtime.slicebytetostring",
+	"runtime.slicebytetostringtmp",
+	"runtime.slicerunetostring",
+	"runtime.stringtoslicebyte",
+	"runtime.stringtoslicerune",
+	"runtime.slicecopy",
+	"runtime.decoderune",
+	"runtime.countrunes",
+	"runtime.convT",
+	"runtime.convTnoptr",
+	"runtime.convT16",
+	"runtime.convT32",
+	"runtime.convT64",
+	"runtime.convTstring",
+	"runtime.convTslice",
+	"runtime.assertE2I",
+	"runtime.assertE2I2",
+	"runtime.panicdottypeE",
+	"runtime.panicdottypeI",
+	"runtime.panicnildottype",
+	"runtime.typeAssert",
+	"runtime.interfaceSwitch",
+	"runtime.ifaceeq",
+	"runtime.efaceeq",
+	"runtime.panicrangestate",
+	"runtime.deferrangefunc",
+	"runtime.rand",
+	"runtime.rand32",
+	"runtime.makemap64",
+	"runtime.makemap",
+	"runtime.makemap_small",
+	"runtime.mapaccess1",
+	"runtime.mapaccess1_fast32",
+	"runtime.mapaccess1_fast64",
+	"runtime.mapaccess1_faststr",
+	"runtime.mapaccess1_fat",
+	"runtime.mapaccess2",
+	"runtime.mapaccess2_fast32",
+	"runtime.mapaccess2_fast64",
+	"runtime.mapaccess2_faststr",
+	"runtime.mapaccess2_fat",
+	"runtime.mapassign",
+	"runtime.mapassign_fast32",
+	"runtime.mapassign_fast32ptr",
+	"runtime.mapassign_fast64",
+	"runtime.mapassign_fast64ptr",
+	"runtime.mapassign_faststr",
+	"runtime.mapiterinit",
+	"runtime.mapIterStart",
+	"runtime.mapdelete",
+	"runtime.mapdelete_fast32",
+	"runtime.mapdelete_fast64",
+	"runtime.mapdelete_faststr",
+	"runtime.mapiternext",
+	"runtime.mapIterNext",
+	"runtime.mapclear",
+	"runtime.makechan64",
+	"runtime.makechan",
+	"runtime.chanrecv1",
+	"runtime.chanrecv2",
+	"runtime.chansend1",
+	"runtime.closechan",
+	"runtime.chanlen",
+	"runtime.chancap",
+	"runtime.writeBarrier",
+	"runtime.typedmemmove",
+	"runtime.typedmemclr",
+	"runtime.typedslicecopy",
+	"runtime.selectnbsend",
+	"runtime.selectnbrecv",
+	"runtime.selectsetpc",
+	"runtime.selectgo",
+	"runtime.block",
+	"runtime.makeslice",
+	"runtime.makeslice64",
+	"runtime.makeslicecopy",
+	"runtime.growslice",
+	"runtime.unsafeslicecheckptr",
+	"runtime.panicunsafeslicelen",
+	"runtime.panicunsafeslicenilptr",
+	"runtime.unsafestringcheckptr",
+	"runtime.panicunsafestringlen",
+	"runtime.panicunsafestringnilptr",
+	"runtime.memmove",
+	"runtime.memclrNoHeapPointers",
+	"runtime.memclrHasPointers",
+	"runtime.memequal",
+	"runtime.memequal0",
+	"runtime.memequal8",
+	"runtime.memequal16",
+	"runtime.memequal32",
+	"runtime.memequal64",
+	"runtime.memequal128",
+	"runtime.f32equal",
+	"runtime.f64equal",
+	"runtime.c64equal",
+	"runtime.c128equal",
+	"runtime.strequal",
+	"runtime.interequal",
+	"runtime.nilinterequal",
+	"runtime.memhash",
+	"runtime.memhash0",
+	"runtime.memhash8",
+	"runtime.memhash16",
+	"runtime.memhash32",
+	"runtime.memhash64",
+	"runtime.memhash128",
+	"runtime.f32hash",
+	"runtime.f64hash",
+	"runtime.c64hash",
+	"runtime.c128hash",
+	"runtime.strhash",
+	"runtime.interhash",
+	"runtime.nilinterhash",
+	"runtime.int64div",
+	"runtime.uint64div",
+	"runtime.int64mod",
+	"runtime.uint64mod",
+	"runtime.float64toint64",
+	"runtime.float64touint64",
+	"runtime.float64touint32",
+	"runtime.int64tofloat64",
+	"runtime.int64tofloat32",
+	"runtime.uint64tofloat64",
+	"runtime.uint64tofloat32",
+	"runtime.uint32tofloat64",
+	"runtime.complex128div",
+	"runtime.racefuncenter",
+	"runtime.racefuncexit",
+	"runtime.raceread",
+	"runtime.racewrite",
+	"runtime.racereadrange",
+	"runtime.racewriterange",
+	"runtime.msanread",
+	"runtime.msanwrite",
+	"runtime.msanmove",
+	"runtime.asanread",
+	"runtime.asanwrite",
+	"runtime.checkptrAlignment",
+	"runtime.checkptrArithmetic",
+	"runtime.libfuzzerTraceCmp1",
+	"runtime.libfuzzerTraceCmp2",
+	"runtime.libfuzzerTraceCmp4",
+	"runtime.libfuzzerTraceCmp8",
+	"runtime.libfuzzerTraceConstCmp1",
+	"runtime.libfuzzerTraceConstCmp2",
+	"runtime.libfuzzerTraceConstCmp4",
+	"runtime.libfuzzerTraceConstCmp8",
+	"runtime.libfuzzerHookStrCmp",
+	"runtime.libfuzzerHookEqualFold",
+	"runtime.addCovMeta",
+	"runtime.x86HasPOPCNT",
+	"runtime.x86HasSSE41",
+	"runtime.x86HasFMA",
+	"runtime.armHasVFPv4",
+	"runtime.arm64HasATOMICS",
+	"runtime.loong64HasLAMCAS",
+	"runtime.loong64HasLAM_BH",
+	"runtime.loong64HasLSX",
+	"runtime.riscv64HasZbb",
+	"runtime.asanregisterglobals",
+	"runtime.deferproc",
+	"runtime.deferprocStack",
+	"runtime.deferreturn",
+	"runtime.newproc",
+	"runtime.panicoverflow",
+	"runtime.sigpanic",
+	"runtime.gcWriteBarrier",
+	"runtime.duffzero",
+	"runtime.duffcopy",
+	"runtime.morestack",
+	"runtime.morestackc",
+	"runtime.morestack_noctxt",
+	"type:int8",
+	"type:*int8",
+	"type:uint8",
+	"type:*uint8",
+	"type:int16",
+	"type:*int16",
+	"type:uint16",
+	"type:*uint16",
+	"type:int32",
+	"type:*int32",
+	"type:uint32",
+	"type:*uint32",
+	"type:int64",
+	"type:*int64",
+	"type:uint64",
+	"type:*uint64",
+	"type:float32",
+	"type:*float32",
+	"type:float64",
+	"type:*float64",
+	"type:complex64",
+	"type:*complex64",
+	"type:complex128",
+	"type:*complex128",
+	"type:unsafe.Pointer",
+	"type:*unsafe.Pointer",
+	"type:uintptr",
+	"type:*uintptr",
+	"type:bool",
+	"type:*bool",
+	"type:string",
+	"type:*string",
+	"type:error",
+	"type:*error",
+	"type:func(error) string",
+	"type:*func(error) string",
+}
+
+// go126Builtins are the runtime symbols referred to by index rather than by
+// name in Go 1.26 object files, as listed by cmd/internal/goobj/builtinlist.go.
+var go126Builtins = [...]string{
+	"runtime.newobject",
+	"runtime.mallocgc",
+	"runtime.panicdivide",
+	"runtime.panicshift",
+	"runtime.panicmakeslicelen",
+	"runtime.panicmakeslicecap",
+	"runtime.throwinit",
+	"runtime.panicwrap",
+	"runtime.gopanic",
+	"runtime.gorecover",
+	"runtime.goschedguarded",
+	"runtime.goPanicIndex",
+	"runtime.goPanicIndexU",
+	"runtime.goPanicSliceAlen",
+	"runtime.goPanicSliceAlenU",
+	"runtime.goPanicSliceAcap",
+	"runtime.goPanicSliceAcapU",
+	"runtime.goPanicSliceB",
+	"runtime.goPanicSliceBU",
+	"runtime.goPanicSlice3Alen",
+	"runtime.goPanicSlice3AlenU",
+	"runtime.goPanicSlice3Acap",
+	"runtime.goPanicSlice3AcapU",
+	"runtime.goPanicSlice3B",
+	"runtime.goPanicSlice3BU",
+	"runtime.goPanicSlice3C",
+	"runtime.goPanicSlice3CU",
+	"runtime.goPanicSliceConvert",
+	"runtime.printbool",
+	"runtime.printfloat64",
+	"runtime.printfloat32",
+	"runtime.printint",
+	"runtime.printhex",
+	"runtime.printuint",
+	"runtime.printcomplex128",
+	"runtime.printcomplex64",
+	"runtime.printstring",
+	"runtime.printquoted",
+	"runtime.printpointer",
+	"runtime.printuintptr",
+	"runtime.printiface",
+	"runtime.printeface",
+	"runtime.printslice",
+	"runtime.printnl",
+	"runtime.printsp",
+	"runtime.printlock",
+	"runtime.printunlock",
+	"runtime.concatstring2",
+	"runtime.concatstring3",
+	"runtime.concatstring4",
+	"runtime.concatstring5",
+	"runtime.concatstrings",
+	"runtime.concatbyte2",
+	"runtime.concatbyte3",
+	"runtime.concatbyte4",
+	"runtime.concatbyte5",
+	"runtime.concatbytes",
+	"runtime.cmpstring",
+	"runtime.intstring",
+	"runtime.slicebytetostring",
+	"runtime.slicebytetostringtmp",
+	"runtime.slicerunetostring",
+	"runtime.stringtoslicebyte",
+	"runtime.stringtoslicerune",
+	"runtime.slicecopy",
+	"runtime.decoderune",
+	"runtime.countrunes",
+	"runtime.convT",
+	"runtime.convTnoptr",
+	"runtime.convT16",
+	"runtime.convT32",
+	"runtime.convT64",
+	"runtime.convTstring",
+	"runtime.convTslice",
+	"runtime.assertE2I",
+	"runtime.assertE2I2",
+	"runtime.panicdottypeE",
+	"runtime.panicdottypeI",
+	"runtime.panicnildottype",
+	"runtime.typeAssert",
+	"runtime.interfaceSwitch",
+	"runtime.ifaceeq",
+	"runtime.efaceeq",
+	"runtime.panicrangestate",
+	"runtime.deferrangefunc",
+	"runtime.rand",
+	"runtime.rand32",
+	"runtime.makemap64",
+	"runtime.makemap",
+	"runtime.makemap_small",
+	"runtime.mapaccess1",
+	"runtime.mapaccess1_fast32",
+	"runtime.mapaccess1_fast64",
+	"runtime.mapaccess1_faststr",
+	"runtime.mapaccess1_fat",
+	"runtime.mapaccess2",
+	"runtime.mapaccess2_fast32",
+	"runtime.mapaccess2_fast64",
+	"runtime.mapaccess2_faststr",
+	"runtime.mapaccess2_fat",
+	"runtime.mapassign",
+	"runtime.mapassign_fast32",
+	"runtime.mapassign_fast32ptr",
+	"runtime.mapassign_fast64",
+	"runtime.mapassign_fast64ptr",
+	"runtime.mapassign_faststr",
+	"runtime.mapIterStart",
+	"runtime.mapdelete",
+	"runtime.mapdelete_fast32",
+	"runtime.mapdelete_fast64",
+	"runtime.mapdelete_faststr",
+	"runtime.mapIterNext",
+	"runtime.mapclear",
+	"runtime.makechan64",
+	"runtime.makechan",
+	"runtime.chanrecv1",
+	"runtime.chanrecv2",
+	"runtime.chansend1",
+	"runtime.closechan",
+	"runtime.chanlen",
+	"runtime.chancap",
+	"runtime.writeBarrier",
+	"runtime.typedmemmove",
+	"runtime.typedmemclr",
+	"runtime.typedslicecopy",
+	"runtime.selectnbsend",
+	"runtime.selectnbrecv",
+	"runtime.selectsetpc",
+	"runtime.selectgo",
+	"runtime.block",
+	"runtime.makeslice",
+	"runtime.makeslice64",
+	"runtime.makeslicecopy",
+	"runtime.growslice",
+	"runtime.unsafeslicecheckptr",
+	"runtime.panicunsafeslicelen",
+	"runtime.panicunsafeslicenilptr",
+	"runtime.unsafestringcheckptr",
+	"runtime.panicunsafestringlen",
+	"runtime.panicunsafestringnilptr",
+	"runtime.memmove",
+	"runtime.memclrNoHeapPointers",
+	"runtime.memclrHasPointers",
+	"runtime.memequal",
+	"runtime.memequal0",
+	"runtime.memequal8",
+	"runtime.memequal16",
+	"runtime.memequal32",
+	"runtime.memequal64",
+	"runtime.memequal128",
+	"runtime.f32equal",
+	"runtime.f64equal",
+	"runtime.c64equal",
+	"runtime.c128equal",
+	"runtime.strequal",
+	"runtime.interequal",
+	"runtime.nilinterequal",
+	"runtime.memhash",
+	"runtime.memhash0",
+	"runtime.memhash8",
+	"runtime.memhash16",
+	"runtime.memhash32",
+	"runtime.memhash64",
+	"runtime.memhash128",
+	"runtime.f32hash",
+	"runtime.f64hash",
+	"runtime.c64hash",
+	"runtime.c128hash",
+	"runtime.strhash",
+	"runtime.interhash",
+	"runtime.nilinterhash",
+	"runtime.int64div",
+	"runtime.uint64div",
+	"runtime.int64mod",
+	"runtime.uint64mod",
+	"runtime.float64toint64",
+	"runtime.float64touint64",
+	"runtime.float64touint32",
+	"runtime.int64tofloat64",
+	"runtime.int64tofloat32",
+	"runtime.uint64tofloat64",
+	"runtime.uint64tofloat32",
+	"runtime.uint32tofloat64",
+	"runtime.complex128div",
+	"runtime.racefuncenter",
+	"runtime.racefuncexit",
+	"runtime.raceread",
+	"runtime.racewrite",
+	"runtime.racereadrange",
+	"runtime.racewriterange",
+	"runtime.msanread",
+	"runtime.msanwrite",
+	"runtime.msanmove",
+	"runtime.asanread",
+	"runtime.asanwrite",
+	"runtime.checkptrAlignment",
+	"runtime.checkptrArithmetic",
+	"runtime.libfuzzerTraceCmp1",
+	"runtime.libfuzzerTraceCmp2",
+	"runtime.libfuzzerTraceCmp4",
+	"runtime.libfuzzerTraceCmp8",
+	"runtime.libfuzzerTraceConstCmp1",
+	"runtime.libfuzzerTraceConstCmp2",
+	"runtime.libfuzzerTraceConstCmp4",
+	"runtime.libfuzzerTraceConstCmp8",
+	"runtime.libfuzzerHookStrCmp",
+	"runtime.libfuzzerHookEqualFold",
+	"runtime.addCovMeta",
+	"runtime.x86HasPOPCNT",
+	"runtime.x86HasSSE41",
+	"runtime.x86HasFMA",
+	"runtime.armHasVFPv4",
+	"runtime.arm64HasATOMICS",
+	"runtime.loong64HasLAMCAS",
+	"runtime.loong64HasLAM_BH",
+	"runtime.loong64HasLSX",
+	"runtime.riscv64HasZbb",
+	"runtime.asanregisterglobals",
+	"runtime.deferproc",
+	"runtime.deferprocStack",
+	"runtime.deferreturn",
+	"runtime.newproc",
+	"runtime.panicoverflow",
+	"runtime.sigpanic",
+	"runtime.gcWriteBarrier",
+	"runtime.duffzero",
+	"runtime.duffcopy",
+	"runtime.morestack",
+	"runtime.morestackc",
+	"runtime.morestack_noctxt",
+	"type:int8",
+	"type:*int8",
+	"type:uint8",
+	"type:*uint8",
+	"type:int16",
+	"type:*int16",
+	"type:uint16",
+	"type:*uint16",
+	"type:int32",
+	"type:*int32",
+	"type:uint32",
+	"type:*uint32",
+	"type:int64",
+	"type:*int64",
+	"type:uint64",
+	"type:*uint64",
+	"type:float32",
+	"type:*float32",
+	"type:float64",
+	"type:*float64",
+	"type:complex64",
+	"type:*complex64",
+	"type:complex128",
+	"type:*complex128",
+	"type:unsafe.Pointer",
+	"type:*unsafe.Pointer",
+	"type:uintptr",
+	"type:*uintptr",
+	"type:bool",
+	"type:*bool",
+	"type:string",
+	"type:*string",
+	"type:error",
+	"type:*error",
+	"type:func(error) string",
+	"type:*func(error) string",
+}
+
+// go127Builtins are the runtime symbols referred to by index rather than by
+// name in Go 1.27 object files, as listed by cmd/internal/goobj/builtinlist.go.
+var go127Builtins = [...]string{
+	"runtime.newobject",
+	"runtime.mallocgc",
+	"runtime.panicdivide",
+	"runtime.panicshift",
+	"runtime.panicmakeslicelen",
+	"runtime.panicmakeslicecap",
+	"runtime.throwinit",
+	"runtime.panicwrap",
+	"runtime.gopanic",
+	"runtime.gorecover",
+	"runtime.goschedguarded",
+	"runtime.goPanicIndex",
+	"runtime.goPanicIndexU",
+	"runtime.goPanicSliceAlen",
+	"runtime.goPanicSliceAlenU",
+	"runtime.goPanicSliceAcap",
+	"runtime.goPanicSliceAcapU",
+	"runtime.goPanicSliceB",
+	"runtime.goPanicSliceBU",
+	"runtime.goPanicSlice3Alen",
+	"runtime.goPanicSlice3AlenU",
+	"runtime.goPanicSlice3Acap",
+	"runtime.goPanicSlice3AcapU",
+	"runtime.goPanicSlice3B",
+	"runtime.goPanicSlice3BU",
+	"runtime.goPanicSlice3C",
+	"runtime.goPanicSlice3CU",
+	"runtime.goPanicSliceConvert",
+	"runtime.printbool",
+	"runtime.printfloat64",
+	"runtime.printfloat32",
+	"runtime.printint",
+	"runtime.printhex",
+	"runtime.printuint",
+	"runtime.printcomplex128",
+	"runtime.printcomplex64",
+	"runtime.printstring",
+	"runtime.printquoted",
+	"runtime.printpointer",
+	"runtime.printuintptr",
+	"runtime.printiface",
+	"runtime.printeface",
+	"runtime.printslice",
+	"runtime.printnl",
+	"runtime.printsp",
+	"runtime.printlock",
+	"runtime.printunlock",
+	"runtime.concatstring2",
+	"runtime.concatstring3",
+	"runtime.concatstring4",
+	"runtime.concatstring5",
+	"runtime.concatstrings",
+	"runtime.concatbyte2",
+	"runtime.concatbyte3",
+	"runtime.concatbyte4",
+	"runtime.concatbyte5",
+	"runtime.concatbytes",
+	"runtime.cmpstring",
+	"runtime.intstring",
+	"runtime.slicebytetostring",
+	"runtime.slicebytetostringtmp",
+	"runtime.slicerunetostring",
+	"runtime.stringtoslicebyte",
+	"runtime.stringtoslicerune",
+	"runtime.slicecopy",
+	"runtime.decoderune",
+	"runtime.countrunes",
+	"runtime.convT",
+	"runtime.convTnoptr",
+	"runtime.convT16",
+	"runtime.convT32",
+	"runtime.convT64",
+	"runtime.convTstring",
+	"runtime.convTslice",
+	"runtime.assertE2I",
+	"runtime.assertE2I2",
+	"runtime.panicdottypeE",
+	"runtime.panicdottypeI",
+	"runtime.panicnildottype",
+	"runtime.typeAssert",
+	"runtime.interfaceSwitch",
+	"runtime.ifaceeq",
+	"runtime.efaceeq",
+	"runtime.panicrangestate",
+	"runtime.deferrangefunc",
+	"runtime.rand",
+	"runtime.rand32",
+	"runtime.makemap64",
+	"runtime.makemap",
+	"runtime.makemap_small",
+	"runtime.mapaccess1",
+	"runtime.mapaccess1_fast32",
+	"runtime.mapaccess1_fast64",
+	"runtime.mapaccess1_faststr",
+	"runtime.mapaccess1_fat",
+	"runtime.mapaccess2",
+	"runtime.mapaccess2_fast32",
+	"runtime.mapaccess2_fast64",
+	"runtime.mapaccess2_faststr",
+	"runtime.mapaccess2_fat",
+	"runtime.mapassign",
+	"runtime.mapassign_fast32",
+	"runtime.mapassign_fast32ptr",
+	"runtime.mapassign_fast64",
+	"runtime.mapassign_fast64ptr",
+	"runtime.mapassign_faststr",
+	"runtime.mapIterStart",
+	"runtime.mapdelete",
+	"runtime.mapdelete_fast32",
+	"runtime.mapdelete_fast64",
+	"runtime.mapdelete_faststr",
+	"runtime.mapIterNext",
+	"runtime.mapclear",
+	"runtime.makechan64",
+	"runtime.makechan",
+	"runtime.chanrecv1",
+	"runtime.chanrecv2",
+	"runtime.chansend1",
+	"runtime.closechan",
+	"runtime.chanlen",
+	"runtime.chancap",
+	"runtime.writeBarrier",
+	"runtime.typedmemmove",
+	"runtime.typedmemclr",
+	"runtime.typedslicecopy",
+	"runtime.selectnbsend",
+	"runtime.selectnbrecv",
+	"runtime.selectsetpc",
+	"runtime.selectgo",
+	"runtime.block",
+	"runtime.makeslice",
+	"runtime.makeslice64",
+	"runtime.makeslicecopy",
+	"runtime.growslice",
+	"runtime.growsliceBuf",
+	"runtime.growsliceBufNoAlias",
+	"runtime.growsliceNoAlias",
+	"runtime.unsafeslicecheckptr",
+	"runtime.panicunsafeslicelen",
+	"runtime.panicunsafeslicenilptr",
+	"runtime.unsafestringcheckptr",
+	"runtime.panicunsafestringlen",
+	"runtime.panicunsafestringnilptr",
+	"runtime.moveSlice",
+	"runtime.moveSliceNoScan",
+	"runtime.moveSliceNoCap",
+	"runtime.moveSliceNoCapNoScan",
+	"runtime.memmove",
+	"runtime.memclrNoHeapPointers",
+	"runtime.memclrHasPointers",
+	"runtime.memequal",
+	"runtime.memequal0",
+	"runtime.memequal8",
+	"runtime.memequal16",
+	"runtime.memequal32",
+	"runtime.memequal64",
+	"runtime.memequal128",
+	"runtime.f32equal",
+	"runtime.f64equal",
+	"runtime.c64equal",
+	"runtime.c128equal",
+	"runtime.strequal",
+	"runtime.interequal",
+	"runtime.nilinterequal",
+	"runtime.memhash",
+	"runtime.memhash0",
+	"runtime.memhash8",
+	"runtime.memhash16",
+	"runtime.memhash32",
+	"runtime.memhash64",
+	"runtime.memhash128",
+	"runtime.f32hash",
+	"runtime.f64hash",
+	"runtime.c64hash",
+	"runtime.c128hash",
+	"runtime.strhash",
+	"runtime.interhash",
+	"runtime.nilinterhash",
+	"runtime.int64div",
+	"runtime.uint64div",
+	"runtime.int64mod",
+	"runtime.uint64mod",
+	"runtime.float64toint64",
+	"runtime.float64touint64",
+	"runtime.float64touint32",
+	"runtime.int64tofloat64",
+	"runtime.int64tofloat32",
+	"runtime.uint64tofloat64",
+	"runtime.uint64tofloat32",
+	"runtime.uint32tofloat64",
+	"runtime.complex128div",
+	"runtime.racefuncenter",
+	"runtime.racefuncexit",
+	"runtime.raceread",
+	"runtime.racewrite",
+	"runtime.racereadrange",
+	"runtime.racewriterange",
+	"runtime.msanread",
+	"runtime.msanwrite",
+	"runtime.msanmove",
+	"runtime.asanread",
+	"runtime.asanwrite",
+	"runtime.checkptrAlignment",
+	"runtime.checkptrArithmetic",
+	"runtime.libfuzzerTraceCmp1",
+	"runtime.libfuzzerTraceCmp2",
+	"runtime.libfuzzerTraceCmp4",
+	"runtime.libfuzzerTraceCmp8",
+	"runtime.libfuzzerTraceConstCmp1",
+	"runtime.libfuzzerTraceConstCmp2",
+	"runtime.libfuzzerTraceConstCmp4",
+	"runtime.libfuzzerTraceConstCmp8",
+	"runtime.libfuzzerHookStrCmp",
+	"runtime.libfuzzerHookEqualFold",
+	"runtime.addCovMeta",
+	"runtime.x86HasAVX",
+	"runtime.x86HasFMA",
+	"runtime.x86HasPOPCNT",
+	"runtime.x86HasSSE41",
+	"runtime.armHasVFPv4",
+	"runtime.arm64HasATOMICS",
+	"runtime.loong64HasLAMCAS",
+	"runtime.loong64HasLAM_BH",
+	"runtime.loong64HasDBAR_HINTS",
+	"runtime.loong64HasLSX",
+	"runtime.riscv64HasZbb",
+	"runtime.asanregisterglobals",
+	"runtime.KeepAlive",
+	"runtime.deferproc",
+	"runtime.deferprocStack",
+	"runtime.deferreturn",
+	"runtime.newproc",
+	"runtime.panicoverflow",
+	"runtime.sigpanic",
+	"runtime.gcWriteBarrier1",
+	"runtime.gcWriteBarrier2",
+	"runtime.gcWriteBarrier3",
+	"runtime.gcWriteBarrier4",
+	"runtime.gcWriteBarrier5",
+	"runtime.gcWriteBarrier6",
+	"runtime.gcWriteBarrier7",
+	"runtime.gcWriteBarrier8",
+	"runtime.duffzero",
+	"runtime.duffcopy",
+	"runtime.morestack",
+	"runtime.morestackc",
+	"runtime.morestack_noctxt",
+	"runtime.retpolineAX",
+	"runtime.retpolineCX",
+	"runtime.retpolineDX",
+	"runtime.retpolineBX",
+	"runtime.retpolineBP",
+	"runtime.retpolineSI",
+	"runtime.retpolineDI",
+	"runtime.retpolineR8",
+	"runtime.retpolineR9",
+	"runtime.retpolineR10",
+	"runtime.retpolineR11",
+	"runtime.retpolineR12",
+	"runtime.retpolineR13",
+	"runtime.retpolineR14",
+	"runtime.retpolineR15",
+	"runtime.tls_g",
+	"type:int8",
+	"type:*int8",
+	"type:uint8",
+	"type:*uint8",
+	"type:int16",
+	"type:*int16",
+	"type:uint16",
+	"type:*uint16",
+	"type:int32",
+	"type:*int32",
+	"type:uint32",
+	"type:*uint32",
+	"type:int64",
+	"type:*int64",
+	"type:uint64",
+	"type:*uint64",
+	"type:float32",
+	"type:*float32",
+	"type:float64",
+	"type:*float64",
+	"type:complex64",
+	"type:*complex64",
+	"type:complex128",
+	"type:*complex128",
+	"type:unsafe.Pointer",
+	"type:*unsafe.Pointer",
+	"type:uintptr",
+	"type:*uintptr",
+	"type:bool",
+	"type:*bool",
+	"type:string",
+	"type:*string",
+	"type:error",
+	"type:*error",
+	"type:func(error) string",
+	"type:*func(error) string",
+}
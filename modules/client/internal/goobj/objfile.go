@@ -0,0 +1,593 @@
+package goobj
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/wgliang/opengacm/modules/client/internal/obj"
+)
+
+// Since Go 1.16, the compiler and the assembler write object files in an
+// indexed format made of fixed size blocks, described in
+// cmd/internal/goobj/objfile.go. The magic has been "\x00go120ld" since
+// Go 1.20, but the kinds of symbols, the types of relocations and the
+// builtin symbols are numbered differently by most releases: Go 1.24, for
+// one, inserted STEXTFIPS after STEXT. Object files are only read with the
+// tables of the Go version recorded in their header, which are known from
+// Go 1.24 to Go 1.27.
+var go120Magic = []byte("\x00go120ld")
+
+// Blocks of a Go 1.20 object file, in file order.
+const (
+	blkAutolib = iota
+	blkPkgIdx
+	blkFile
+	blkSymdef
+	blkHashed64def
+	blkHasheddef
+	blkNonpkgdef
+	blkNonpkgref
+	blkRefFlags
+	blkHash64
+	blkHash
+	blkRelocIdx
+	blkAuxIdx
+	blkDataIdx
+	blkReloc
+	blkAux
+	blkData
+	blkRefName
+	blkEnd
+	nBlk
+)
+
+// Sizes of the entries of the blocks.
+const (
+	stringRefSize   = 4 + 4 // length, offset
+	importedPkgSize = stringRefSize + 8
+	symSize         = stringRefSize + 2 + 1 + 1 + 1 + 4 + 4
+	relocSize       = 4 + 1 + 2 + 8 + 8
+	auxSize         = 1 + 8
+	refNameSize     = 8 + stringRefSize
+)
+
+// Package indices of symbol references with a special meaning, the others
+// are indices in the list of referenced packages.
+const (
+	pkgIdxNone = (1<<31 - 1) - iota
+	pkgIdxHashed64
+	pkgIdxHashed
+	pkgIdxBuiltin
+	pkgIdxSelf
+	pkgIdxInvalid = 0
+)
+
+const symABIstatic = ^uint16(0)
+
+// Symbol flags.
+const (
+	symFlagDupok = 1 << iota
+	symFlagLocal
+	symFlagTypelink
+	symFlagLeaf
+	symFlagNoSplit
+)
+
+// Types of auxiliary symbols.
+const (
+	auxGotype = iota
+	auxFuncInfo
+	auxFuncdata
+	auxDwarfInfo
+	auxDwarfLoc
+	auxDwarfRanges
+	auxDwarfLines
+	auxPcsp
+	auxPcfile
+	auxPcline
+	auxPcinline
+	auxPcdata
+)
+
+// relocWeak marks weak references in the type of relocations.
+const relocWeak = 1 << 15
+
+// go120Tables are the numberings of the object files of a Go version.
+type go120Tables struct {
+	kinds      []SymKind // SymKind of each kind of symbols
+	relocTypes []string  // names of the types of relocations, starting at 1
+	builtins   []string  // builtin symbols, see builtin.go
+}
+
+// Minor versions of the oldest and of the newest Go releases whose object
+// files can be read.
+const (
+	go120Oldest = 24
+	go120Newest = 27
+)
+
+// go120Versions maps the minor versions of Go to the tables of their
+// object files.
+var go120Versions = map[int]*go120Tables{
+	24: {go124Kinds[:], go124RelocTypes[:], go124Builtins[:]},
+	25: {go125Kinds[:], go125RelocTypes[:], go125Builtins[:]},
+	26: {go125Kinds[:], go126RelocTypes[:], go126Builtins[:]},
+	27: {go125Kinds[:], go127RelocTypes[:], go127Builtins[:]},
+}
+
+// goMinor returns the minor version of Go of the fields of the header of
+// an object file, "go object GOOS GOARCH VERSION ...", where VERSION is
+// go1.N[.P], or "devel go1.N-hash ..." for development toolchains.
+func goMinor(fields []string) (int, string, bool) {
+	for _, f := range fields {
+		if !strings.HasPrefix(f, "go1.") {
+			continue
+		}
+		n := len("go1.")
+		for n < len(f) && '0' <= f[n] && f[n] <= '9' {
+			n++
+		}
+		minor, err := strconv.Atoi(f[len("go1."):n])
+		return minor, f, err == nil
+	}
+	return 0, "", false
+}
+
+// go120For returns the tables of the object files written by the Go
+// version of the header fields.
+func go120For(fields []string) (*go120Tables, error) {
+	minor, v, ok := goMinor(fields)
+	if !ok {
+		return nil, errCorruptObject
+	}
+	t := go120Versions[minor]
+	if t == nil {
+		return nil, fmt.Errorf("unsupported object file of Go %s: Go 1.%d to 1.%d are supported", v, go120Oldest, go120Newest)
+	}
+	return t, nil
+}
+
+// go124Kinds maps the kinds of symbols of Go 1.24 object files to SymKind:
+// SDWARFADDR came in Go 1.25.
+var go124Kinds = [...]SymKind{
+	0,          // Sxxx
+	STEXT,      // STEXT
+	STEXT,      // STEXTFIPS
+	SRODATA,    // SRODATA
+	SRODATA,    // SRODATAFIPS
+	SNOPTRDATA, // SNOPTRDATA
+	SNOPTRDATA, // SNOPTRDATAFIPS
+	SDATA,      // SDATA
+	SDATA,      // SDATAFIPS
+	SBSS,       // SBSS
+	SNOPTRBSS,  // SNOPTRBSS
+	STLSBSS,    // STLSBSS
+	SDWARFINFO, // SDWARFCUINFO
+	SDWARFINFO, // SDWARFCONST
+	SDWARFINFO, // SDWARFFCN
+	SDWARFINFO, // SDWARFABSFCN
+	SDWARFINFO, // SDWARFTYPE
+	SDWARFINFO, // SDWARFVAR
+	SDWARFINFO, // SDWARFRANGE
+	SDWARFINFO, // SDWARFLOC
+	SDWARFINFO, // SDWARFLINES
+	SNOPTRBSS,  // SLIBFUZZER_8BIT_COUNTER
+	SNOPTRBSS,  // SCOVERAGE_COUNTER
+	SNOPTRDATA, // SCOVERAGE_AUXVAR
+	SRODATA,    // SSEHUNWINDINFO
+}
+
+// go125Kinds maps the kinds of symbols of Go 1.25 to 1.27 object files to
+// SymKind.
+var go125Kinds = [...]SymKind{
+	0,          // Sxxx
+	STEXT,      // STEXT
+	STEXT,      // STEXTFIPS
+	SRODATA,    // SRODATA
+	SRODATA,    // SRODATAFIPS
+	SNOPTRDATA, // SNOPTRDATA
+	SNOPTRDATA, // SNOPTRDATAFIPS
+	SDATA,      // SDATA
+	SDATA,      // SDATAFIPS
+	SBSS,       // SBSS
+	SNOPTRBSS,  // SNOPTRBSS
+	STLSBSS,    // STLSBSS
+	SDWARFINFO, // SDWARFCUINFO
+	SDWARFINFO, // SDWARFCONST
+	SDWARFINFO, // SDWARFFCN
+	SDWARFINFO, // SDWARFABSFCN
+	SDWARFINFO, // SDWARFTYPE
+	SDWARFINFO, // SDWARFVAR
+	SDWARFINFO, // SDWARFRANGE
+	SDWARFINFO, // SDWARFLOC
+	SDWARFINFO, // SDWARFLINES
+	SDWARFINFO, // SDWARFADDR
+	SNOPTRBSS,  // SLIBFUZZER_8BIT_COUNTER
+	SNOPTRBSS,  // SCOVERAGE_COUNTER
+	SNOPTRDATA, // SCOVERAGE_AUXVAR
+	SRODATA,    // SSEHUNWINDINFO
+}
+
+// go124RelocTypes are the names of the types of relocations of Go 1.24
+// object files, starting at 1.
+var go124RelocTypes = [...]string{
+	"R_ADDR", "R_ADDRPOWER", "R_ADDRARM64", "R_ADDRMIPS", "R_ADDROFF",
+	"R_SIZE", "R_CALL", "R_CALLARM", "R_CALLARM64", "R_CALLIND",
+	"R_CALLPOWER", "R_CALLMIPS", "R_CONST", "R_PCREL", "R_TLS_LE",
+	"R_TLS_IE", "R_GOTOFF", "R_PLT0", "R_PLT1", "R_PLT2", "R_USEFIELD",
+	"R_USETYPE", "R_USEIFACE", "R_USEIFACEMETHOD", "R_USENAMEDMETHOD",
+	"R_METHODOFF", "R_KEEP", "R_POWER_TOC", "R_GOTPCREL", "R_JMPMIPS",
+	"R_DWARFSECREF", "R_DWARFFILEREF", "R_ARM64_TLS_LE", "R_ARM64_TLS_IE",
+	"R_ARM64_GOTPCREL", "R_ARM64_GOT", "R_ARM64_PCREL",
+	"R_ARM64_PCREL_LDST8", "R_ARM64_PCREL_LDST16", "R_ARM64_PCREL_LDST32",
+	"R_ARM64_PCREL_LDST64", "R_ARM64_LDST8", "R_ARM64_LDST16",
+	"R_ARM64_LDST32", "R_ARM64_LDST64", "R_ARM64_LDST128",
+	"R_POWER_TLS_LE", "R_POWER_TLS_IE", "R_POWER_TLS",
+	"R_POWER_TLS_IE_PCREL34", "R_POWER_TLS_LE_TPREL34", "R_ADDRPOWER_DS",
+	"R_ADDRPOWER_GOT", "R_ADDRPOWER_GOT_PCREL34", "R_ADDRPOWER_PCREL",
+	"R_ADDRPOWER_TOCREL", "R_ADDRPOWER_TOCREL_DS", "R_ADDRPOWER_D34",
+	"R_ADDRPOWER_PCREL34", "R_RISCV_JAL", "R_RISCV_JAL_TRAMP",
+	"R_RISCV_CALL", "R_RISCV_PCREL_ITYPE", "R_RISCV_PCREL_STYPE",
+	"R_RISCV_TLS_IE", "R_RISCV_TLS_LE", "R_RISCV_GOT_HI20",
+	"R_RISCV_PCREL_HI20", "R_RISCV_PCREL_LO12_I", "R_RISCV_PCREL_LO12_S",
+	"R_RISCV_BRANCH", "R_RISCV_RVC_BRANCH", "R_RISCV_RVC_JUMP",
+	"R_PCRELDBL", "R_LOONG64_ADDR_HI", "R_LOONG64_ADDR_LO",
+	"R_LOONG64_TLS_LE_HI", "R_LOONG64_TLS_LE_LO", "R_CALLLOONG64",
+	"R_LOONG64_TLS_IE_HI", "R_LOONG64_TLS_IE_LO", "R_LOONG64_GOT_HI",
+	"R_LOONG64_GOT_LO", "R_LOONG64_ADD64", "R_LOONG64_SUB64",
+	"R_JMP16LOONG64", "R_JMP21LOONG64", "R_JMPLOONG64", "R_ADDRMIPSU",
+	"R_ADDRMIPSTLS", "R_ADDRCUOFF", "R_WASMIMPORT", "R_XCOFFREF",
+	"R_PEIMAGEOFF", "R_INITORDER",
+}
+
+// go125RelocTypes are the names of the types of relocations of Go 1.25
+// object files, starting at 1.
+var go125RelocTypes = [...]string{
+	"R_ADDR", "R_ADDRPOWER", "R_ADDRARM64", "R_ADDRMIPS", "R_ADDROFF",
+	"R_SIZE", "R_CALL", "R_CALLARM", "R_CALLARM64", "R_CALLIND",
+	"R_CALLPOWER", "R_CALLMIPS", "R_CONST", "R_PCREL", "R_TLS_LE",
+	"R_TLS_IE", "R_GOTOFF", "R_PLT0", "R_PLT1", "R_PLT2", "R_USEFIELD",
+	"R_USETYPE", "R_USEIFACE", "R_USEIFACEMETHOD", "R_USENAMEDMETHOD",
+	"R_METHODOFF", "R_KEEP", "R_POWER_TOC", "R_GOTPCREL", "R_JMPMIPS",
+	"R_DWARFSECREF", "R_ARM64_TLS_LE", "R_ARM64_TLS_IE",
+	"R_ARM64_GOTPCREL", "R_ARM64_GOT", "R_ARM64_PCREL",
+	"R_ARM64_PCREL_LDST8", "R_ARM64_PCREL_LDST16", "R_ARM64_PCREL_LDST32",
+	"R_ARM64_PCREL_LDST64", "R_ARM64_LDST8", "R_ARM64_LDST16",
+	"R_ARM64_LDST32", "R_ARM64_LDST64", "R_ARM64_LDST128",
+	"R_POWER_TLS_LE", "R_POWER_TLS_IE", "R_POWER_TLS",
+	"R_POWER_TLS_IE_PCREL34", "R_POWER_TLS_LE_TPREL34", "R_ADDRPOWER_DS",
+	"R_ADDRPOWER_GOT", "R_ADDRPOWER_GOT_PCREL34", "R_ADDRPOWER_PCREL",
+	"R_ADDRPOWER_TOCREL", "R_ADDRPOWER_TOCREL_DS", "R_ADDRPOWER_D34",
+	"R_ADDRPOWER_PCREL34", "R_RISCV_JAL", "R_RISCV_JAL_TRAMP",
+	"R_RISCV_CALL", "R_RISCV_PCREL_ITYPE", "R_RISCV_PCREL_STYPE",
+	"R_RISCV_TLS_IE", "R_RISCV_TLS_LE", "R_RISCV_GOT_HI20",
+	"R_RISCV_GOT_PCREL_ITYPE", "R_RISCV_PCREL_HI20",
+	"R_RISCV_PCREL_LO12_I", "R_RISCV_PCREL_LO12_S", "R_RISCV_BRANCH",
+	"R_RISCV_RVC_BRANCH", "R_RISCV_RVC_JUMP", "R_PCRELDBL",
+	"R_LOONG64_ADDR_HI", "R_LOONG64_ADDR_LO", "R_LOONG64_TLS_LE_HI",
+	"R_LOONG64_TLS_LE_LO", "R_CALLLOONG64", "R_LOONG64_TLS_IE_HI",
+	"R_LOONG64_TLS_IE_LO", "R_LOONG64_GOT_HI", "R_LOONG64_GOT_LO",
+	"R_LOONG64_ADD64", "R_LOONG64_SUB64", "R_JMP16LOONG64",
+	"R_JMP21LOONG64", "R_JMPLOONG64", "R_ADDRMIPSU", "R_ADDRMIPSTLS",
+	"R_ADDRCUOFF", "R_WASMIMPORT", "R_XCOFFREF", "R_PEIMAGEOFF",
+	"R_INITORDER", "R_DWTXTADDR_U1", "R_DWTXTADDR_U2", "R_DWTXTADDR_U3",
+	"R_DWTXTADDR_U4",
+}
+
+// go126RelocTypes are the names of the types of relocations of Go 1.26
+// object files, starting at 1.
+var go126RelocTypes = [...]string{
+	"R_ADDR", "R_ADDRPOWER", "R_ADDRARM64", "R_ADDRMIPS", "R_ADDROFF",
+	"R_SIZE", "R_CALL", "R_CALLARM", "R_CALLARM64", "R_CALLIND",
+	"R_CALLPOWER", "R_CALLMIPS", "R_CONST", "R_PCREL", "R_TLS_LE",
+	"R_TLS_IE", "R_GOTOFF", "R_PLT0", "R_PLT1", "R_PLT2", "R_USEFIELD",
+	"R_USETYPE", "R_USEIFACE", "R_USEIFACEMETHOD", "R_USENAMEDMETHOD",
+	"R_METHODOFF", "R_KEEP", "R_POWER_TOC", "R_GOTPCREL", "R_JMPMIPS",
+	"R_DWARFSECREF", "R_ARM64_TLS_LE", "R_ARM64_TLS_IE",
+	"R_ARM64_GOTPCREL", "R_ARM64_GOT", "R_ARM64_PCREL",
+	"R_ARM64_PCREL_LDST8", "R_ARM64_PCREL_LDST16", "R_ARM64_PCREL_LDST32",
+	"R_ARM64_PCREL_LDST64", "R_ARM64_LDST8", "R_ARM64_LDST16",
+	"R_ARM64_LDST32", "R_ARM64_LDST64", "R_ARM64_LDST128",
+	"R_POWER_TLS_LE", "R_POWER_TLS_IE", "R_POWER_TLS",
+	"R_POWER_TLS_IE_PCREL34", "R_POWER_TLS_LE_TPREL34", "R_ADDRPOWER_DS",
+	"R_ADDRPOWER_GOT", "R_ADDRPOWER_GOT_PCREL34", "R_ADDRPOWER_PCREL",
+	"R_ADDRPOWER_TOCREL", "R_ADDRPOWER_TOCREL_DS", "R_ADDRPOWER_D34",
+	"R_ADDRPOWER_PCREL34", "R_RISCV_JAL", "R_RISCV_JAL_TRAMP",
+	"R_RISCV_CALL", "R_RISCV_PCREL_ITYPE", "R_RISCV_PCREL_STYPE",
+	"R_RISCV_TLS_IE", "R_RISCV_TLS_LE", "R_RISCV_GOT_HI20",
+	"R_RISCV_GOT_PCREL_ITYPE", "R_RISCV_PCREL_HI20",
+	"R_RISCV_PCREL_LO12_I", "R_RISCV_PCREL_LO12_S", "R_RISCV_BRANCH",
+	"R_RISCV_ADD32", "R_RISCV_SUB32", "R_RISCV_RVC_BRANCH",
+	"R_RISCV_RVC_JUMP", "R_PCRELDBL", "R_LOONG64_ADDR_HI",
+	"R_LOONG64_ADDR_LO", "R_LOONG64_ADDR_PCREL20_S2",
+	"R_LOONG64_TLS_LE_HI", "R_LOONG64_TLS_LE_LO", "R_CALLLOONG64",
+	"R_LOONG64_CALL36", "R_LOONG64_TLS_IE_HI", "R_LOONG64_TLS_IE_LO",
+	"R_LOONG64_GOT_HI", "R_LOONG64_GOT_LO", "R_LOONG64_ADD64",
+	"R_LOONG64_SUB64", "R_JMP16LOONG64", "R_JMP21LOONG64", "R_JMPLOONG64",
+	"R_ADDRMIPSU", "R_ADDRMIPSTLS", "R_ADDRCUOFF", "R_WASMIMPORT",
+	"R_XCOFFREF", "R_PEIMAGEOFF", "R_INITORDER", "R_DWTXTADDR_U1",
+	"R_DWTXTADDR_U2", "R_DWTXTADDR_U3", "R_DWTXTADDR_U4",
+}
+
+// go127RelocTypes are the names of the types of relocations of Go 1.27
+// object files, starting at 1.
+var go127RelocTypes = [...]string{
+	"R_ADDR", "R_ADDRPOWER", "R_ADDRARM64", "R_ADDRMIPS", "R_ADDROFF",
+	"R_SIZE", "R_CALL", "R_CALLARM", "R_CALLARM64", "R_CALLIND",
+	"R_CALLPOWER", "R_CALLMIPS", "R_CONST", "R_PCREL", "R_TLS_LE",
+	"R_TLS_IE", "R_GOTOFF", "R_PLT0", "R_PLT1", "R_PLT2", "R_USEFIELD",
+	"R_USETYPE", "R_USEIFACE", "R_USEIFACEMETHOD", "R_USENAMEDMETHOD",
+	"R_METHODOFF", "R_KEEP", "R_POWER_TOC", "R_GOTPCREL", "R_JMPMIPS",
+	"R_DWARFSECREF", "R_ARM64_TLS_LE", "R_ARM64_TLS_IE",
+	"R_ARM64_GOTPCREL", "R_ARM64_GOT", "R_ARM64_PCREL",
+	"R_ARM64_PCREL_LDST8", "R_ARM64_PCREL_LDST16", "R_ARM64_PCREL_LDST32",
+	"R_ARM64_PCREL_LDST64", "R_ARM64_LDST8", "R_ARM64_LDST16",
+	"R_ARM64_LDST32", "R_ARM64_LDST64", "R_ARM64_LDST128",
+	"R_POWER_TLS_LE", "R_POWER_TLS_IE", "R_POWER_TLS",
+	"R_POWER_TLS_IE_PCREL34", "R_POWER_TLS_LE_TPREL34", "R_ADDRPOWER_DS",
+	"R_ADDRPOWER_GOT", "R_ADDRPOWER_GOT_PCREL34", "R_ADDRPOWER_PCREL",
+	"R_ADDRPOWER_TOCREL", "R_ADDRPOWER_TOCREL_DS", "R_ADDRPOWER_D34",
+	"R_ADDRPOWER_PCREL34", "R_RISCV_JAL", "R_RISCV_JAL_TRAMP",
+	"R_RISCV_CALL", "R_RISCV_PCREL_ITYPE", "R_RISCV_PCREL_STYPE",
+	"R_RISCV_TLS_IE", "R_RISCV_TLS_LE", "R_RISCV_GOT_HI20",
+	"R_RISCV_GOT_PCREL_ITYPE", "R_RISCV_PCREL_HI20",
+	"R_RISCV_PCREL_LO12_I", "R_RISCV_PCREL_LO12_S", "R_RISCV_BRANCH",
+	"R_RISCV_ADD32", "R_RISCV_SUB32", "R_RISCV_RVC_BRANCH",
+	"R_RISCV_RVC_JUMP", "R_PCRELDBL", "R_LOONG64_ADDR_HI",
+	"R_LOONG64_ADDR_LO", "R_LOONG64_ADDR64_HI", "R_LOONG64_ADDR64_LO",
+	"R_LOONG64_ADDR_PCREL20_S2", "R_LOONG64_TLS_LE_HI",
+	"R_LOONG64_TLS_LE_LO", "R_CALLLOONG64", "R_LOONG64_CALL36",
+	"R_LOONG64_TLS_IE_HI", "R_LOONG64_TLS_IE_LO", "R_LOONG64_GOT_HI",
+	"R_LOONG64_GOT_LO", "R_LOONG64_GOT64_HI", "R_LOONG64_GOT64_LO",
+	"R_LOONG64_ADD64", "R_LOONG64_SUB64", "R_JMP16LOONG64",
+	"R_JMP21LOONG64", "R_ADDRMIPSU", "R_ADDRMIPSTLS", "R_ADDRCUOFF",
+	"R_WASMIMPORT", "R_XCOFFREF", "R_PEIMAGEOFF", "R_INITORDER",
+	"R_DWTXTADDR_U1", "R_DWTXTADDR_U2", "R_DWTXTADDR_U3", "R_DWTXTADDR_U4",
+}
+
+// relocTypes maps the names of the types of relocations to the ones of
+// the obj package, for those both know.
+var relocTypes = make(map[string]obj.RelocType)
+
+func init() {
+	for t := obj.RelocType(1); !strings.HasPrefix(t.String(), "RelocType("); t++ {
+		relocTypes[t.String()] = t
+	}
+}
+
+// go120Reader reads the blocks of a Go 1.20 object file held in b, which
+// starts at base in the file being parsed.
+type go120Reader struct {
+	*go120Tables
+	b       []byte
+	base    int64
+	offsets [nBlk]uint32
+	corrupt bool
+
+	nsym, nhashed64, nhashed, ndef int
+	refNames                       map[[2]uint32]string
+}
+
+// bytesAt returns the n bytes at off, recording out of range reads of
+// corrupt files.
+func (o *go120Reader) bytesAt(off, n uint32) []byte {
+	if uint64(off)+uint64(n) > uint64(len(o.b)) {
+		o.corrupt = true
+		return make([]byte, n)
+	}
+	return o.b[off : off+n]
+}
+
+func (o *go120Reader) uint32At(off uint32) uint32 {
+	return binary.LittleEndian.Uint32(o.bytesAt(off, 4))
+}
+
+func (o *go120Reader) stringRef(off uint32) string {
+	return string(o.bytesAt(o.uint32At(off+4), o.uint32At(off)))
+}
+
+// count returns the number of entries of size n of block blk.
+func (o *go120Reader) count(blk int, n uint32) int {
+	return int((o.offsets[blk+1] - o.offsets[blk]) / n)
+}
+
+// sym returns the offset of the i-th symbol.
+func (o *go120Reader) sym(i uint32) uint32 {
+	return o.offsets[blkSymdef] + i*symSize
+}
+
+// data returns the data of the i-th symbol.
+func (o *go120Reader) data(i uint32) Data {
+	off := o.uint32At(o.offsets[blkDataIdx] + i*4)
+	end := o.uint32At(o.offsets[blkDataIdx] + i*4 + 4)
+	return Data{Offset: o.base + int64(o.offsets[blkData]+off), Size: int64(end - off)}
+}
+
+// local returns the index of the symbol defined by the object file a
+// symbol reference refers to.
+func (o *go120Reader) local(pkg, idx uint32) (uint32, bool) {
+	switch pkg {
+	case pkgIdxSelf:
+		return idx, true
+	case pkgIdxHashed64:
+		return idx + uint32(o.nsym), true
+	case pkgIdxHashed:
+		return idx + uint32(o.nsym+o.nhashed64), true
+	case pkgIdxNone:
+		return idx + uint32(o.nsym+o.nhashed64+o.nhashed), true
+	}
+	return 0, false
+}
+
+// symRef reads the symbol reference at off.
+func (o *go120Reader) symRef(off uint32) (pkg, idx uint32) {
+	return o.uint32At(off), o.uint32At(off + 4)
+}
+
+// parseGo120 parses the Go 1.20 object file b, found at offset base and
+// written by a toolchain with the tables t.
+func (r *objReader) parseGo120(b []byte, base int64, t *go120Tables) error {
+	o := &go120Reader{go120Tables: t, b: b, base: base}
+	off := uint32(len(go120Magic) + 8 + 4) // magic, fingerprint, flags
+	for i := range o.offsets {
+		o.offsets[i] = o.uint32At(off)
+		off += 4
+	}
+	for i := 1; i < nBlk; i++ {
+		if o.offsets[i] < o.offsets[i-1] || o.offsets[i] > uint32(len(b)) {
+			o.corrupt = true
+		}
+	}
+	if o.corrupt {
+		return r.error(errCorruptObject)
+	}
+	o.nsym = o.count(blkSymdef, symSize)
+	o.nhashed64 = o.count(blkHashed64def, symSize)
+	o.nhashed = o.count(blkHasheddef, symSize)
+	o.ndef = o.nsym + o.nhashed64 + o.nhashed + o.count(blkNonpkgdef, symSize)
+	nref := o.count(blkNonpkgref, symSize)
+
+	// Every object file gets its own version for static symbols, like the
+	// ones of the old format.
+	version := r.p.MaxVersion
+	symID := func(i uint32) SymID {
+		s := o.sym(i)
+		id := SymID{Name: strings.Replace(o.stringRef(s), `"".`, r.pkgprefix, -1)}
+		if binary.LittleEndian.Uint16(o.bytesAt(s+8, 2)) == symABIstatic {
+			id.Version = version
+		}
+		return id
+	}
+	o.refNames = make(map[[2]uint32]string)
+	for i := 0; i < o.count(blkRefName, refNameSize); i++ {
+		off := o.offsets[blkRefName] + uint32(i*refNameSize)
+		pkg, idx := o.symRef(off)
+		o.refNames[[2]uint32{pkg, idx}] = o.stringRef(off + 8)
+	}
+	resolve := func(pkg, idx uint32) SymID {
+		if i, ok := o.local(pkg, idx); ok {
+			return symID(i)
+		}
+		switch pkg {
+		case pkgIdxInvalid:
+			return SymID{}
+		case pkgIdxBuiltin:
+			if int(idx) < len(o.builtins) {
+				return SymID{Name: o.builtins[idx]}
+			}
+		}
+		return SymID{Name: o.refNames[[2]uint32{pkg, idx}]}
+	}
+
+	for i := 0; i < o.count(blkAutolib, importedPkgSize); i++ {
+		r.p.Imports = append(r.p.Imports, o.stringRef(o.offsets[blkAutolib]+uint32(i*importedPkgSize)))
+	}
+	files := make([]string, o.count(blkFile, stringRefSize))
+	for i := range files {
+		files[i] = o.stringRef(o.offsets[blkFile] + uint32(i*stringRefSize))
+	}
+	for i := o.ndef; i < o.ndef+nref; i++ {
+		r.p.SymRefs = append(r.p.SymRefs, symID(uint32(i)))
+	}
+	for _, name := range o.refNames {
+		r.p.SymRefs = append(r.p.SymRefs, SymID{Name: name})
+	}
+
+	for i := uint32(0); i < uint32(o.ndef); i++ {
+		id := symID(i)
+		if id.Name == "" {
+			continue // not a real symbol
+		}
+		sym := o.sym(i)
+		kind := SymKind(0)
+		if k := int(o.bytesAt(sym+10, 1)[0]); k < len(o.kinds) {
+			kind = o.kinds[k]
+		}
+		flag := o.bytesAt(sym+11, 1)[0]
+		s := &Sym{
+			SymID: id,
+			Kind:  kind,
+			DupOK: flag&symFlagDupok != 0,
+			Size:  int(o.uint32At(sym + 13)),
+			Data:  o.data(i),
+		}
+
+		relocs := o.offsets[blkRelocIdx] + i*4
+		first, last := o.uint32At(relocs), o.uint32At(relocs+4)
+		for j := first; j < last; j++ {
+			off := o.offsets[blkReloc] + j*relocSize
+			typ := binary.LittleEndian.Uint16(o.bytesAt(off+5, 2)) &^ relocWeak
+			rel := Reloc{
+				Offset: int(int32(o.uint32At(off))),
+				Size:   int(o.bytesAt(off+4, 1)[0]),
+				Add:    int(int64(binary.LittleEndian.Uint64(o.bytesAt(off+7, 8)))),
+				Sym:    resolve(o.symRef(off + 15)),
+			}
+			if typ > 0 && int(typ) <= len(o.relocTypes) {
+				rel.typeName = o.relocTypes[typ-1]
+				rel.Type = relocTypes[rel.typeName]
+			}
+			s.Reloc = append(s.Reloc, rel)
+		}
+
+		if kind == STEXT {
+			// The pcfile tables index the files of the object file.
+			s.Func = &Func{
+				Leaf:    flag&symFlagLeaf != 0,
+				NoSplit: flag&symFlagNoSplit != 0,
+				File:    files,
+			}
+		}
+		auxs := o.offsets[blkAuxIdx] + i*4
+		first, last = o.uint32At(auxs), o.uint32At(auxs+4)
+		for j := first; j < last; j++ {
+			off := o.offsets[blkAux] + j*auxSize
+			typ := o.bytesAt(off, 1)[0]
+			pkg, idx := o.symRef(off + 1)
+			if typ == auxGotype {
+				s.Type = resolve(pkg, idx)
+				continue
+			}
+			f := s.Func
+			if f == nil {
+				continue
+			}
+			if typ == auxFuncdata {
+				f.FuncData = append(f.FuncData, FuncData{Sym: resolve(pkg, idx)})
+				continue
+			}
+			aux, ok := o.local(pkg, idx)
+			if !ok {
+				continue
+			}
+			data := o.data(aux)
+			switch typ {
+			case auxFuncInfo:
+				o.funcInfo(data, f)
+			case auxPcsp:
+				f.PCSP = data
+			case auxPcfile:
+				f.PCFile = data
+			case auxPcline:
+				f.PCLine = data
+			case auxPcdata:
+				f.PCData = append(f.PCData, data)
+			}
+		}
+		r.p.Syms = append(r.p.Syms, s)
+	}
+	if o.corrupt {
+		return r.error(errCorruptObject)
+	}
+	return nil
+}
+
+// funcInfo reads into f the sizes of the frames held in the function
+// information data.
+func (o *go120Reader) funcInfo(data Data, f *Func) {
+	off := uint32(data.Offset - o.base)
+	f.Args = int(o.uint32At(off))
+	f.Frame = int(o.uint32At(off + 4))
+}
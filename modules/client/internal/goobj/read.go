@@ -72,12 +72,14 @@ const (
 	SCONST            = SymKind(obj.SCONST)
 	SDYNIMPORT        = SymKind(obj.SDYNIMPORT)
 	SHOSTOBJ          = SymKind(obj.SHOSTOBJ)
+	SDWARFINFO        = SymKind(obj.SDWARFINFO)
 )
 
 var symKindStrings = []string{
 	SBSS:              "SBSS",
 	SCONST:            "SCONST",
 	SDATA:             "SDATA",
+	SDWARFINFO:        "SDWARFINFO",
 	SDYNIMPORT:        "SDYNIMPORT",
 	SELFROSECT:        "SELFROSECT",
 	SELFRXSECT:        "SELFRXSECT",
@@ -174,6 +176,10 @@ type Reloc struct {
 	// described by the previous fields: absolute, PC-relative, and so on.
 	// TODO(rsc): The interpretation of Type is not exposed by this package.
 	Type obj.RelocType
+
+	// typeName is the name of the type of relocations of object files
+	// newer than the obj package, whose Type may be unknown to it.
+	typeName string
 }
 
 // A Var describes a variable in a function stack frame: a declared
@@ -220,6 +226,7 @@ type Package struct {
 	Syms       []*Sym   // symbols defined by this package
 	MaxVersion int      // maximum Version in any SymID in Syms
 	Arch       string   // architecture
+	BuildID    string   // build ID recorded by the go command, if any
 }
 
 var (
@@ -491,7 +498,6 @@ func trimSpace(b []byte) string {
 }
 
 // parseArchive parses a Unix archive of Go object files.
-// TODO(rsc): Need to skip non-Go object files.
 // TODO(rsc): Maybe record table of contents in r.p so that
 // linker can avoid having code to parse archives too.
 func (r *objReader) parseArchive() error {
@@ -537,6 +543,12 @@ func (r *objReader) parseArchive() error {
 		case "__.PKGDEF":
 			r.skip(size)
 		default:
+			if b, err := r.b.Peek(len(goobjHeader)); size < int64(len(goobjHeader)) || err != nil || !bytes.Equal(b, goobjHeader) {
+				// Native object files of cgo packages, and the
+				// markers the go command adds to some archives.
+				r.skip(size)
+				break
+			}
 			oldLimit := r.limit
 			r.limit = r.offset + size
 			if err := r.parseObject(nil); err != nil {
@@ -558,7 +570,8 @@ func (r *objReader) parseArchive() error {
 // The object file consists of a textual header ending in "\n!\n"
 // and then the part we want to parse begins.
 // The format of that part is defined in a comment at the top
-// of src/liblink/objfile.c.
+// of src/liblink/objfile.c, or for Go 1.20 and later object files,
+// in cmd/internal/goobj/objfile.go (see parseGo120).
 func (r *objReader) parseObject(prefix []byte) error {
 	r.p.MaxVersion++
 	h := make([]byte, 0, 256)
@@ -581,9 +594,27 @@ func (r *objReader) parseObject(prefix []byte) error {
 	if len(hs) >= 4 {
 		r.p.Arch = hs[3]
 	}
-	// TODO: extract OS + build ID if/when we need it
+	for _, line := range strings.Split(string(h), "\n") {
+		if strings.HasPrefix(line, "build id ") {
+			r.p.BuildID, _ = strconv.Unquote(strings.TrimPrefix(line, "build id "))
+		}
+	}
+	// TODO: extract OS if/when we need it
 
+	start := r.offset
 	r.readFull(r.tmp[:8])
+	if bytes.Equal(r.tmp[:8], go120Magic) {
+		t, err := go120For(hs)
+		if err != nil {
+			return r.error(err)
+		}
+		b := make([]byte, r.limit-start)
+		copy(b, r.tmp[:8])
+		if err := r.readFull(b[8:]); err != nil {
+			return err
+		}
+		return r.parseGo120(b, start, t)
+	}
 	if !bytes.Equal(r.tmp[:8], []byte("\x00\x00go17ld")) {
 		return r.error(errCorruptObject)
 	}
@@ -700,7 +731,12 @@ func (r *objReader) parseObject(prefix []byte) error {
 
 func (r *Reloc) String(insnOffset uint64) string {
 	delta := r.Offset - int(insnOffset)
-	s := fmt.Sprintf("[%d:%d]%s", delta, delta+r.Size, r.Type)
+	var s string
+	if r.typeName != "" {
+		s = fmt.Sprintf("[%d:%d]%s", delta, delta+r.Size, r.typeName)
+	} else {
+		s = fmt.Sprintf("[%d:%d]%s", delta, delta+r.Size, r.Type)
+	}
 	if r.Sym.Name != "" {
 		if r.Add != 0 {
 			return fmt.Sprintf("%s:%s+%d", s, r.Sym.Name, r.Add)
@@ -4,7 +4,10 @@
 
 package goobj
 
-import "testing"
+import (
+	"strings"
+	"testing"
+)
 
 var importPathToPrefixTests = []struct {
 	in  string
@@ -26,3 +29,26 @@ func TestImportPathToPrefix(t *testing.T) {
 		}
 	}
 }
+
+func TestGo120For(t *testing.T) {
+	for _, tt := range []struct {
+		header string
+		minor  int // minor version of the tables, 0 if unsupported
+	}{
+		{"go object linux amd64 go1.24.13 GOAMD64=v1 X:regabiwrappers", 24},
+		{"go object linux amd64 go1.25.0 X:none", 25},
+		{"go object linux amd64 go1.26.8 X:none", 26},
+		{"go object linux amd64 go1.27.1 X:none", 27},
+		{"go object linux amd64 go1.27 X:none", 27},
+		{"go object linux arm64 devel go1.27-0123abcd Mon Jan 1 00:00:00 2026 +0000 X:none", 27},
+		// Releases older than Go 1.24 and newer than the tables.
+		{"go object linux amd64 go1.23.4 X:none", 0},
+		{"go object linux amd64 go1.28rc1 X:none", 0},
+		{"go object linux amd64 X:none", 0},
+	} {
+		tables, err := go120For(strings.Fields(tt.header))
+		if want := go120Versions[tt.minor]; tables != want || (err == nil) != (want != nil) {
+			t.Errorf("go120For(%q) = %p, %v; want %p", tt.header, tables, err, want)
+		}
+	}
+}
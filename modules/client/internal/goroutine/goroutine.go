@@ -0,0 +1,297 @@
+// Package goroutine parses goroutine dumps as written by
+// pprof.Lookup("goroutine").WriteTo(w, 2) or by a crashing runtime,
+// and groups goroutines that share an identical stack.
+package goroutine
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Frame is a single call site of a goroutine stack.
+type Frame struct {
+	Func string `json:"func"`
+	File string `json:"file"`
+	Line int    `json:"line"`
+}
+
+// Goroutine is a goroutine as it appears in a dump.
+type Goroutine struct {
+	ID        int           `json:"id"`
+	State     string        `json:"state"`
+	Wait      time.Duration `json:"wait"`
+	Locked    bool          `json:"locked"`
+	Frames    []Frame       `json:"frames"`
+	CreatedBy *Frame        `json:"created_by,omitempty"`
+	Elided    bool          `json:"elided,omitempty"`
+}
+
+// Signature returns a key that is equal for goroutines
+// with identical stacks and creation sites.
+func (g *Goroutine) Signature() string {
+	var b bytes.Buffer
+	for _, f := range g.Frames {
+		fmt.Fprintf(&b, "%s %s:%d\n", f.Func, f.File, f.Line)
+	}
+	if g.Elided {
+		b.WriteString("...\n")
+	}
+	if g.CreatedBy != nil {
+		fmt.Fprintf(&b, "created by %s %s:%d\n", g.CreatedBy.Func, g.CreatedBy.File, g.CreatedBy.Line)
+	}
+	return b.String()
+}
+
+var headerRE = regexp.MustCompile(`^goroutine (\d+)(?: [^\[]*)? \[(.*)\]:$`)
+
+// Parse reads a goroutine dump from r.
+// Lines that do not belong to a goroutine block are ignored.
+func Parse(r io.Reader) ([]*Goroutine, error) {
+	var (
+		gs  []*Goroutine
+		g   *Goroutine
+		fn  string // function of the frame whose location is expected next
+		crt bool   // fn is a "created by" frame
+	)
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for sc.Scan() {
+		line := sc.Text()
+		if m := headerRE.FindStringSubmatch(line); m != nil {
+			id, _ := strconv.Atoi(m[1])
+			g = &Goroutine{ID: id}
+			parseState(g, m[2])
+			gs = append(gs, g)
+			fn = ""
+			continue
+		}
+		if g == nil {
+			continue
+		}
+		switch {
+		case line == "":
+			g = nil
+		case strings.HasPrefix(line, "\t"):
+			if fn == "" {
+				continue
+			}
+			f := Frame{Func: fn}
+			f.File, f.Line = parseLocation(strings.TrimSpace(line))
+			if crt {
+				g.CreatedBy = &f
+			} else {
+				g.Frames = append(g.Frames, f)
+			}
+			fn = ""
+		case strings.HasPrefix(line, "..."):
+			g.Elided = true
+		case strings.HasPrefix(line, "created by "):
+			fn = strings.TrimPrefix(line, "created by ")
+			if i := strings.Index(fn, " in goroutine "); i >= 0 {
+				fn = fn[:i]
+			}
+			crt = true
+		default:
+			fn = trimArgs(line)
+			crt = false
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return gs, nil
+}
+
+// parseState fills the state, wait duration and locked flag
+// from the bracketed part of a goroutine header,
+// e.g. "chan receive, 5 minutes, locked to thread".
+func parseState(g *Goroutine, s string) {
+	parts := strings.Split(s, ", ")
+	g.State = parts[0]
+	for _, p := range parts[1:] {
+		switch {
+		case p == "locked to thread":
+			g.Locked = true
+		case strings.HasSuffix(p, " minutes"):
+			n, err := strconv.Atoi(strings.TrimSuffix(p, " minutes"))
+			if err == nil {
+				g.Wait = time.Duration(n) * time.Minute
+			}
+		}
+	}
+}
+
+// parseLocation splits "file.go:42 +0x1d" into file and line.
+func parseLocation(s string) (string, int) {
+	if i := strings.LastIndex(s, " +0x"); i >= 0 {
+		s = s[:i]
+	}
+	i := strings.LastIndex(s, ":")
+	if i < 0 {
+		return s, 0
+	}
+	line, err := strconv.Atoi(s[i+1:])
+	if err != nil {
+		return s, 0
+	}
+	return s[:i], line
+}
+
+// trimArgs removes the argument list from a frame function line,
+// so that the same call site with different arguments compares equal.
+func trimArgs(s string) string {
+	if strings.HasSuffix(s, ")") {
+		depth := 0
+		for i := len(s) - 1; i >= 0; i-- {
+			switch s[i] {
+			case ')':
+				depth++
+			case '(':
+				depth--
+				if depth == 0 {
+					return s[:i]
+				}
+			}
+		}
+	}
+	return s
+}
+
+// Filter selects goroutines of a dump.
+type Filter struct {
+	Func  *regexp.Regexp // at least one frame function must match, if set
+	State string         // state must contain State, if set
+}
+
+// Match reports whether g is selected by the filter.
+func (f *Filter) Match(g *Goroutine) bool {
+	if f.State != "" && !strings.Contains(g.State, f.State) {
+		return false
+	}
+	if f.Func != nil {
+		for _, fr := range g.Frames {
+			if f.Func.MatchString(fr.Func) {
+				return true
+			}
+		}
+		if g.CreatedBy != nil && f.Func.MatchString(g.CreatedBy.Func) {
+			return true
+		}
+		return false
+	}
+	return true
+}
+
+// Apply returns the goroutines of gs selected by the filter.
+func (f *Filter) Apply(gs []*Goroutine) []*Goroutine {
+	var out []*Goroutine
+	for _, g := range gs {
+		if f.Match(g) {
+			out = append(out, g)
+		}
+	}
+	return out
+}
+
+// Group is a set of goroutines with an identical stack.
+type Group struct {
+	Signature string         `json:"-"`
+	Count     int            `json:"count"`
+	States    map[string]int `json:"states"`
+	MinWait   time.Duration  `json:"min_wait"`
+	MaxWait   time.Duration  `json:"max_wait"`
+	IDs       []int          `json:"ids"`
+	Frames    []Frame        `json:"frames"`
+	CreatedBy *Frame         `json:"created_by,omitempty"`
+}
+
+// GroupBy groups gs by stack signature. Groups are sorted
+// by decreasing count, then by signature.
+func GroupBy(gs []*Goroutine) []*Group {
+	index := make(map[string]*Group)
+	var groups []*Group
+	for _, g := range gs {
+		sig := g.Signature()
+		gr, ok := index[sig]
+		if !ok {
+			gr = &Group{
+				Signature: sig,
+				States:    make(map[string]int),
+				MinWait:   g.Wait,
+				MaxWait:   g.Wait,
+				Frames:    g.Frames,
+				CreatedBy: g.CreatedBy,
+			}
+			index[sig] = gr
+			groups = append(groups, gr)
+		}
+		gr.Count++
+		gr.States[g.State]++
+		gr.IDs = append(gr.IDs, g.ID)
+		if g.Wait < gr.MinWait {
+			gr.MinWait = g.Wait
+		}
+		if g.Wait > gr.MaxWait {
+			gr.MaxWait = g.Wait
+		}
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].Count != groups[j].Count {
+			return groups[i].Count > groups[j].Count
+		}
+		return groups[i].Signature < groups[j].Signature
+	})
+	return groups
+}
+
+// Print writes a human readable summary of groups to w.
+func Print(w io.Writer, groups []*Group) {
+	total := 0
+	for _, gr := range groups {
+		total += gr.Count
+	}
+	fmt.Fprintf(w, "%d goroutines in %d groups\n", total, len(groups))
+	for _, gr := range groups {
+		fmt.Fprintf(w, "\n%d goroutines [%s]", gr.Count, gr.StateSummary())
+		if gr.MaxWait > 0 {
+			if gr.MinWait == gr.MaxWait {
+				fmt.Fprintf(w, " waiting %v", gr.MaxWait)
+			} else {
+				fmt.Fprintf(w, " waiting %v-%v", gr.MinWait, gr.MaxWait)
+			}
+		}
+		fmt.Fprintln(w)
+		for _, f := range gr.Frames {
+			fmt.Fprintf(w, "    %s\n        %s:%d\n", f.Func, f.File, f.Line)
+		}
+		if gr.CreatedBy != nil {
+			fmt.Fprintf(w, "    created by %s\n        %s:%d\n", gr.CreatedBy.Func, gr.CreatedBy.File, gr.CreatedBy.Line)
+		}
+	}
+}
+
+// StateSummary formats the state counts of a group, most frequent first.
+func (gr *Group) StateSummary() string {
+	names := make([]string, 0, len(gr.States))
+	for s := range gr.States {
+		names = append(names, s)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if gr.States[names[i]] != gr.States[names[j]] {
+			return gr.States[names[i]] > gr.States[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	parts := make([]string, len(names))
+	for i, s := range names {
+		parts[i] = fmt.Sprintf("%s: %d", s, gr.States[s])
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,119 @@
+package goroutine
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+const dump = `goroutine 1 [running]:
+runtime/pprof.writeGoroutineStacks({0x5e1648, 0xc000010018})
+	/usr/local/go/src/runtime/pprof/pprof.go:816 +0x69
+main.main()
+	/tmp/d.go:3 +0x145
+
+goroutine 7 [chan receive, 5 minutes]:
+main.worker(0xc00001e0c0)
+	/tmp/d.go:10 +0x19
+created by main.main in goroutine 1
+	/tmp/d.go:3 +0x65
+
+goroutine 8 [chan receive, 2 minutes, locked to thread]:
+main.worker(0xc00001e0d0)
+	/tmp/d.go:10 +0x19
+created by main.main in goroutine 1
+	/tmp/d.go:3 +0x65
+
+goroutine 9 [select]:
+main.worker(0xc00001e0e0)
+	/tmp/d.go:10 +0x19
+created by main.main in goroutine 1
+	/tmp/d.go:3 +0x65
+
+goroutine 10 gp=0xc000003340 m=nil [sync.Mutex.Lock]:
+sync.(*Mutex).Lock(...)
+	/usr/local/go/src/sync/mutex.go:46
+main.main.func2()
+	/tmp/d.go:20 +0x2c
+...additional frames elided...
+created by main.main
+	/tmp/d.go:3 +0x105
+`
+
+func TestParse(t *testing.T) {
+	gs, err := Parse(strings.NewReader(dump))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(gs) != 5 {
+		t.Fatalf("got %d goroutines; want 5", len(gs))
+	}
+	g := gs[2]
+	if g.ID != 8 || g.State != "chan receive" || g.Wait != 2*time.Minute || !g.Locked {
+		t.Errorf("got header %d %q %v %v; want 8 \"chan receive\" 2m0s true", g.ID, g.State, g.Wait, g.Locked)
+	}
+	if len(g.Frames) != 1 || g.Frames[0] != (Frame{"main.worker", "/tmp/d.go", 10}) {
+		t.Errorf("got frames %v", g.Frames)
+	}
+	if g.CreatedBy == nil || *g.CreatedBy != (Frame{"main.main", "/tmp/d.go", 3}) {
+		t.Errorf("got created by %v", g.CreatedBy)
+	}
+	g = gs[4]
+	if g.ID != 10 || g.State != "sync.Mutex.Lock" || !g.Elided || len(g.Frames) != 2 {
+		t.Errorf("got %d %q elided=%v frames=%d; want 10 \"sync.Mutex.Lock\" true 2", g.ID, g.State, g.Elided, len(g.Frames))
+	}
+	if g.Frames[0].Func != "sync.(*Mutex).Lock" {
+		t.Errorf("got func %q; want \"sync.(*Mutex).Lock\"", g.Frames[0].Func)
+	}
+}
+
+func TestGroupBy(t *testing.T) {
+	gs, err := Parse(strings.NewReader(dump))
+	if err != nil {
+		t.Fatal(err)
+	}
+	groups := GroupBy(gs)
+	if len(groups) != 3 {
+		t.Fatalf("got %d groups; want 3", len(groups))
+	}
+	gr := groups[0]
+	if gr.Count != 3 || gr.States["chan receive"] != 2 || gr.States["select"] != 1 {
+		t.Errorf("got count %d states %v", gr.Count, gr.States)
+	}
+	if gr.MinWait != 0 || gr.MaxWait != 5*time.Minute {
+		t.Errorf("got wait %v-%v; want 0s-5m0s", gr.MinWait, gr.MaxWait)
+	}
+
+	var buf bytes.Buffer
+	Print(&buf, groups)
+	if !strings.HasPrefix(buf.String(), "5 goroutines in 3 groups\n") {
+		t.Errorf("unexpected summary:\n%s", buf.String())
+	}
+	if !strings.Contains(buf.String(), "3 goroutines [chan receive: 2, select: 1] waiting 0s-5m0s\n") {
+		t.Errorf("unexpected group header:\n%s", buf.String())
+	}
+}
+
+func TestFilter(t *testing.T) {
+	gs, err := Parse(strings.NewReader(dump))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		filter Filter
+		want   int
+	}{
+		{Filter{}, 5},
+		{Filter{State: "chan"}, 2},
+		{Filter{Func: regexp.MustCompile(`^main\.worker$`)}, 3},
+		{Filter{Func: regexp.MustCompile(`Mutex`), State: "Lock"}, 1},
+		{Filter{Func: regexp.MustCompile(`^main\.main$`), State: "select"}, 1},
+	}
+	for _, tt := range tests {
+		if got := len(tt.filter.Apply(gs)); got != tt.want {
+			t.Errorf("Filter{%v, %q}: got %d goroutines; want %d", tt.filter.Func, tt.filter.State, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package goroutine
+
+import "sort"
+
+// Growth is a group of goroutines whose count grew over a series of dumps.
+type Growth struct {
+	Group  *Group `json:"group"`  // group as seen in the last dump
+	Counts []int  `json:"counts"` // goroutine count in each dump
+}
+
+// Delta returns the growth between the first and the last dump.
+func (g *Growth) Delta() int {
+	return g.Counts[len(g.Counts)-1] - g.Counts[0]
+}
+
+// Leaks compares the grouped goroutines of successive dumps and returns
+// the groups whose count never decreased, grew between at least intervals
+// pairs of successive dumps, and grew by at least min between the first
+// and the last dump, largest growth first. A single jump of the count,
+// such as a burst of requests, is not taken for a leak if intervals is
+// more than 1.
+func Leaks(samples [][]*Group, min, intervals int) []*Growth {
+	if len(samples) < 2 {
+		return nil
+	}
+	counts := make(map[string][]int)
+	last := make(map[string]*Group)
+	for i, groups := range samples {
+		for _, gr := range groups {
+			c, ok := counts[gr.Signature]
+			if !ok {
+				c = make([]int, len(samples))
+				counts[gr.Signature] = c
+			}
+			c[i] = gr.Count
+			last[gr.Signature] = gr
+		}
+	}
+	var leaks []*Growth
+	for sig, c := range counts {
+		steady, grew := true, 0
+		for i := 1; i < len(c); i++ {
+			if c[i] < c[i-1] {
+				steady = false
+				break
+			}
+			if c[i] > c[i-1] {
+				grew++
+			}
+		}
+		if steady && grew >= intervals && c[len(c)-1]-c[0] >= min {
+			leaks = append(leaks, &Growth{Group: last[sig], Counts: c})
+		}
+	}
+	sort.Slice(leaks, func(i, j int) bool {
+		if leaks[i].Delta() != leaks[j].Delta() {
+			return leaks[i].Delta() > leaks[j].Delta()
+		}
+		return leaks[i].Group.Signature < leaks[j].Group.Signature
+	})
+	return leaks
+}
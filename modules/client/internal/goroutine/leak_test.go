@@ -0,0 +1,37 @@
+package goroutine
+
+import "testing"
+
+func TestLeaks(t *testing.T) {
+	group := func(sig string, n int) *Group {
+		return &Group{Signature: sig, Count: n}
+	}
+	samples := [][]*Group{
+		{group("a", 1), group("b", 10), group("c", 3)},
+		{group("a", 5), group("b", 12), group("c", 1)},
+		{group("a", 9), group("b", 12), group("c", 7), group("d", 4)},
+	}
+	leaks := Leaks(samples, 1, 1)
+	if len(leaks) != 3 {
+		t.Fatalf("got %d leaks; want 3", len(leaks))
+	}
+	want := []struct {
+		sig   string
+		delta int
+	}{{"a", 8}, {"d", 4}, {"b", 2}}
+	for i, w := range want {
+		if leaks[i].Group.Signature != w.sig || leaks[i].Delta() != w.delta {
+			t.Errorf("leaks[%d] = %q +%d; want %q +%d", i, leaks[i].Group.Signature, leaks[i].Delta(), w.sig, w.delta)
+		}
+	}
+	if got := Leaks(samples, 5, 1); len(got) != 1 {
+		t.Errorf("got %d leaks with min 5; want 1", len(got))
+	}
+	// b and d only grew once.
+	if got := Leaks(samples, 1, 2); len(got) != 1 || got[0].Group.Signature != "a" {
+		t.Errorf("got %d leaks growing in 2 intervals; want a only", len(got))
+	}
+	if got := Leaks(samples[:1], 1, 1); got != nil {
+		t.Errorf("got %d leaks from a single sample; want none", len(got))
+	}
+}
@@ -19,7 +19,13 @@ func ConfigDir() (string, error) {
 	if homeDir == "" {
 		return "", errors.New("unable to get current user home directory: os/user lookup failed; $HOME is empty")
 	}
-	return filepath.Join(homeDir, ".config", "gops"), nil
+	return HomeConfigDir(homeDir), nil
+}
+
+// HomeConfigDir returns the configuration directory
+// of a user whose home directory is home.
+func HomeConfigDir(home string) string {
+	return filepath.Join(home, ".config", "gops")
 }
 
 func guessUnixHomeDir() string {
@@ -0,0 +1,93 @@
+package objfile
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+)
+
+var (
+	buildIDPrefix = []byte("\xff Go build ID: \"")
+	buildIDSuffix = []byte("\"\n \xff")
+)
+
+const (
+	buildIDNote    = 4 // type of the ELF note holding the Go build ID
+	gnuBuildIDNote = 3 // type of the NT_GNU_BUILD_ID note
+)
+
+// BuildID returns the Go build ID of the file, which the go command
+// records in an ELF note or at the start of the text of other formats.
+func (f *File) BuildID() (string, error) {
+	return f.raw.buildID()
+}
+
+// GNUBuildID returns the build ID the linker records in the GNU note of
+// an ELF file, in hexadecimal, which pprof records in the mappings of the
+// profiles of the file.
+func (f *File) GNUBuildID() (string, error) {
+	ef, ok := f.raw.(*elfFile)
+	if !ok {
+		return "", fmt.Errorf("GNU build IDs are only recorded in ELF files")
+	}
+	return ef.gnuBuildID()
+}
+
+// IsGo reports whether the file was built by the Go toolchain, which
+// records its build information and build ID even in stripped binaries.
+func (f *File) IsGo() bool {
+	if _, _, err := f.buildInfo(); err == nil {
+		return true
+	}
+	_, err := f.BuildID()
+	return err == nil
+}
+
+// findNote returns the description of the note of a sequence of ELF notes
+// with the given name, including its terminating NUL, and type.
+func findNote(order binary.ByteOrder, data []byte, name string, typ uint32) []byte {
+	for len(data) >= 12 {
+		namesz := int(order.Uint32(data))
+		descsz := int(order.Uint32(data[4:]))
+		t := order.Uint32(data[8:])
+		data = data[12:]
+		n := align4(namesz)
+		if n+descsz > len(data) {
+			break
+		}
+		if t == typ && namesz == len(name) && string(data[:namesz]) == name {
+			return data[n : n+descsz]
+		}
+		if n+align4(descsz) > len(data) {
+			break
+		}
+		data = data[n+align4(descsz):]
+	}
+	return nil
+}
+
+func align4(n int) int {
+	return (n + 3) &^ 3
+}
+
+// textBuildID returns the Go build ID found at the start of the text of f.
+func textBuildID(f rawFile) (string, error) {
+	_, text, err := f.text()
+	if err != nil {
+		return "", err
+	}
+	if len(text) > 32*1024 {
+		text = text[:32*1024]
+	}
+	i := bytes.Index(text, buildIDPrefix)
+	if i < 0 {
+		return "", fmt.Errorf("build ID not found")
+	}
+	text = text[i+len(buildIDPrefix)-1:]
+	j := bytes.Index(text, buildIDSuffix)
+	if j < 0 {
+		return "", fmt.Errorf("build ID not found")
+	}
+	return strconv.Unquote(string(text[:j+1]))
+}
@@ -0,0 +1,78 @@
+package objfile
+
+import (
+	"encoding/binary"
+	"os"
+	"runtime"
+	"testing"
+)
+
+func TestFindNote(t *testing.T) {
+	var notes []byte
+	note := func(name string, typ uint32, desc string) {
+		var hdr [12]byte
+		binary.LittleEndian.PutUint32(hdr[0:], uint32(len(name)))
+		binary.LittleEndian.PutUint32(hdr[4:], uint32(len(desc)))
+		binary.LittleEndian.PutUint32(hdr[8:], typ)
+		notes = append(notes, hdr[:]...)
+		notes = append(notes, name...)
+		notes = append(notes, make([]byte, align4(len(name))-len(name))...)
+		notes = append(notes, desc...)
+		notes = append(notes, make([]byte, align4(len(desc))-len(desc))...)
+	}
+	note("GNU\x00", 3, "0123456789abcdef0123")
+	note("Go\x00\x00", buildIDNote, "abc/def")
+
+	if id := findNote(binary.LittleEndian, notes, "Go\x00\x00", buildIDNote); string(id) != "abc/def" {
+		t.Errorf("Go build ID = %q; want abc/def", id)
+	}
+	if id := findNote(binary.LittleEndian, notes, "GNU\x00", gnuBuildIDNote); string(id) != "0123456789abcdef0123" {
+		t.Errorf("GNU build ID = %q; want 0123456789abcdef0123", id)
+	}
+	if id := findNote(binary.LittleEndian, notes[:40], "Go\x00\x00", buildIDNote); id != nil {
+		t.Errorf("build ID of truncated notes = %q", id)
+	}
+}
+
+func TestIsGo(t *testing.T) {
+	f, err := Open(os.Args[0])
+	if err != nil {
+		t.Skip(err)
+	}
+	defer f.Close()
+	if !f.IsGo() {
+		t.Error("the test binary is not recognized as a Go binary")
+	}
+	if id, err := f.BuildID(); err != nil || id == "" {
+		t.Errorf("BuildID = %q, %v", id, err)
+	}
+	if runtime.GOOS == "linux" {
+		if id, err := f.GNUBuildID(); err != nil || id == "" {
+			t.Errorf("GNUBuildID = %q, %v", id, err)
+		}
+	}
+}
+
+func TestBuildInfo(t *testing.T) {
+	f, err := Open(os.Args[0])
+	if err != nil {
+		t.Skip(err)
+	}
+	defer f.Close()
+	bi, err := f.BuildInfo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bi.GoVersion != runtime.Version() {
+		t.Errorf("GoVersion = %q; want %q", bi.GoVersion, runtime.Version())
+	}
+	var goos string
+	for _, s := range bi.Settings {
+		if s.Key == "GOOS" {
+			goos = s.Value
+		}
+	}
+	if goos != runtime.GOOS {
+		t.Errorf("GOOS setting = %q; want %q", goos, runtime.GOOS)
+	}
+}
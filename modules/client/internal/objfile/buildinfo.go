@@ -0,0 +1,124 @@
+package objfile
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"runtime/debug"
+)
+
+var buildInfoMagic = []byte("\xff Go buildinf:")
+
+// Layout of the build information header written by the linker.
+const (
+	buildInfoHeaderSize = 32
+	buildInfoPtrSize    = 14 // offset of the pointer size
+	buildInfoFlags      = 15 // offset of the flags
+
+	flagsBigEndian = 0x1 // pointers are big endian
+	flagsInline    = 0x2 // strings follow the header, since Go 1.18
+)
+
+// BuildInfo returns the Go version, module and build settings recorded
+// in the file by the linker. It only needs the build information
+// section, so stripped binaries are supported. Binaries built before
+// Go 1.18 have no build settings, and binaries built outside of module
+// mode have no module information.
+func (f *File) BuildInfo() (*debug.BuildInfo, error) {
+	vers, mod, err := f.rawBuildInfo()
+	if err != nil {
+		return nil, err
+	}
+	if vers == "" {
+		return nil, fmt.Errorf("not a Go executable")
+	}
+	// The module information is enclosed in 16-byte sentinels.
+	if len(mod) >= 33 && mod[len(mod)-17] == '\n' {
+		mod = mod[16 : len(mod)-16]
+	} else {
+		mod = ""
+	}
+	bi, err := debug.ParseBuildInfo(mod)
+	if err != nil {
+		return nil, err
+	}
+	bi.GoVersion = vers
+	return bi, nil
+}
+
+// rawBuildInfo returns the Go version and module information strings.
+func (f *File) rawBuildInfo() (vers, mod string, err error) {
+	_, blob, err := f.buildInfo()
+	if err != nil {
+		return "", "", err
+	}
+	if len(blob) < buildInfoHeaderSize {
+		return "", "", fmt.Errorf("truncated build info")
+	}
+	flags := blob[buildInfoFlags]
+	if flags&flagsInline != 0 {
+		data := blob[buildInfoHeaderSize:]
+		var strs [2]string
+		for i := range strs {
+			n, m := binary.Uvarint(data)
+			if m <= 0 || uint64(len(data)-m) < n {
+				return "", "", fmt.Errorf("malformed build info")
+			}
+			strs[i] = string(data[m : m+int(n)])
+			data = data[m+int(n):]
+		}
+		return strs[0], strs[1], nil
+	}
+
+	// Before Go 1.18, the header holds pointers to the string headers.
+	ptrSize := int(blob[buildInfoPtrSize])
+	if ptrSize != 4 && ptrSize != 8 {
+		return "", "", fmt.Errorf("malformed build info")
+	}
+	var order binary.ByteOrder = binary.LittleEndian
+	if flags&flagsBigEndian != 0 {
+		order = binary.BigEndian
+	}
+	readPtr := func(b []byte) uint64 {
+		if ptrSize == 4 {
+			return uint64(order.Uint32(b))
+		}
+		return order.Uint64(b)
+	}
+	readString := func(ptr uint64) string {
+		hdr, err := f.raw.dataAt(ptr, uint64(2*ptrSize))
+		if err != nil || len(hdr) < 2*ptrSize {
+			return ""
+		}
+		n := readPtr(hdr[ptrSize:])
+		data, err := f.raw.dataAt(readPtr(hdr), n)
+		if err != nil || uint64(len(data)) < n {
+			return ""
+		}
+		return string(data)
+	}
+	vers = readString(readPtr(blob[16:]))
+	mod = readString(readPtr(blob[16+ptrSize:]))
+	return vers, mod, nil
+}
+
+// buildInfo returns the address and content of the build information
+// written by the linker, starting with its magic header.
+func (f *File) buildInfo() (uint64, []byte, error) {
+	addr, data, err := f.raw.buildInfo()
+	if err != nil {
+		return 0, nil, err
+	}
+	// The header is 16-byte aligned in memory.
+	for off := 0; ; {
+		i := bytes.Index(data[off:], buildInfoMagic)
+		if i < 0 {
+			return 0, nil, fmt.Errorf("build info not found")
+		}
+		off += i
+		if (addr+uint64(off))%16 == 0 {
+			return addr + uint64(off), data[off:], nil
+		}
+		off++
+	}
+}
@@ -16,7 +16,10 @@ import (
 	"text/tabwriter"
 
 	"golang.org/x/arch/arm/armasm"
+	"golang.org/x/arch/arm64/arm64asm"
 	"golang.org/x/arch/ppc64/ppc64asm"
+	"golang.org/x/arch/riscv64/riscv64asm"
+	"golang.org/x/arch/s390x/s390xasm"
 	"golang.org/x/arch/x86/x86asm"
 )
 
@@ -34,16 +37,28 @@ type Disasm struct {
 
 // Disasm returns a disassembler for the file f.
 func (f *File) Disasm() (*Disasm, error) {
-	syms, err := f.Symbols()
-	if err != nil {
-		return nil, err
-	}
+	syms, symErr := f.Symbols()
 
 	pcln, err := f.PCLineTable()
 	if err != nil {
 		return nil, err
 	}
 
+	if symErr != nil || !hasText(syms) {
+		// Stripped binaries still have the functions of the pclntab.
+		tab, ok := pcln.(*gosym.Table)
+		if !ok || len(tab.Funcs) == 0 {
+			if symErr == nil {
+				symErr = fmt.Errorf("no symbols")
+			}
+			return nil, symErr
+		}
+		syms = syms[:0]
+		for _, fn := range tab.Funcs {
+			syms = append(syms, Sym{Name: fn.Name, Addr: fn.Entry, Size: int64(fn.End - fn.Entry), Code: 'T'})
+		}
+	}
+
 	textStart, textBytes, err := f.Text()
 	if err != nil {
 		return nil, err
@@ -81,11 +96,24 @@ func (f *File) Disasm() (*Disasm, error) {
 	return d, nil
 }
 
+// hasText reports whether syms has text symbols. The symbol table of
+// stripped Mach-O binaries keeps the symbols of the dynamic linker.
+func hasText(syms []Sym) bool {
+	for _, s := range syms {
+		if s.Code == 'T' || s.Code == 't' {
+			return true
+		}
+	}
+	return false
+}
+
 // lookup finds the symbol name containing addr.
 func (d *Disasm) lookup(addr uint64) (name string, base uint64) {
 	i := sort.Search(len(d.syms), func(i int) bool { return addr < d.syms[i].Addr })
-	if i > 0 {
-		s := d.syms[i-1]
+	// Several symbols may start at the same address, such as a variable
+	// and the zero-sized symbol marking the start of its section.
+	for j := i - 1; j >= 0 && d.syms[j].Addr == d.syms[i-1].Addr; j-- {
+		s := d.syms[j]
 		if s.Addr != 0 && s.Addr <= addr && addr < s.Addr+uint64(s.Size) {
 			return s.Name, s.Addr
 		}
@@ -105,7 +133,8 @@ func base(path string) string {
 // Print prints a disassembly of the file to w.
 // If filter is non-nil, the disassembly only includes functions with names matching filter.
 // The disassembly only includes functions that overlap the range [start, end).
-func (d *Disasm) Print(w io.Writer, filter *regexp.Regexp, start, end uint64) {
+// If src is non-nil, the instructions are interleaved with the source lines they were compiled from.
+func (d *Disasm) Print(w io.Writer, filter *regexp.Regexp, start, end uint64, src *SourceCache) {
 	if start < d.textStart {
 		start = d.textStart
 	}
@@ -137,8 +166,18 @@ func (d *Disasm) Print(w io.Writer, filter *regexp.Regexp, start, end uint64) {
 			symEnd = end
 		}
 		code := d.text[:end-d.textStart]
+		var lastFile string
+		var lastLine int
 		d.Decode(symStart, symEnd, relocs, func(pc, size uint64, file string, line int, text string) {
 			i := pc - d.textStart
+			if src != nil && (file != lastFile || line != lastLine) {
+				lastFile, lastLine = file, line
+				if b, err := src.Line(file, line); err == nil {
+					// The source line goes in the column of the instructions,
+					// its tabs would be taken for cells by the tabwriter.
+					fmt.Fprintf(tw, "\t\t\t\t%s\n", strings.Replace(string(b), "\t", "    ", -1))
+				}
+			}
 			fmt.Fprintf(tw, "\t%s:%d\t%#x\t", base(file), line, pc)
 			if size%4 != 0 || d.goarch == "386" || d.goarch == "amd64" {
 				// Print instruction as bytes.
@@ -201,7 +240,7 @@ func disasm_amd64(code []byte, pc uint64, lookup lookupFunc, _ binary.ByteOrder)
 }
 
 func disasm_x86(code []byte, pc uint64, lookup lookupFunc, arch int) (string, int) {
-	inst, err := x86asm.Decode(code, 64)
+	inst, err := x86asm.Decode(code, arch)
 	var text string
 	size := inst.Len
 	if err != nil || size == 0 || inst.Op == 0 {
@@ -259,21 +298,79 @@ func disasm_ppc64(code []byte, pc uint64, lookup lookupFunc, byteOrder binary.By
 	return text, size
 }
 
+func disasm_arm64(code []byte, pc uint64, lookup lookupFunc, _ binary.ByteOrder) (string, int) {
+	inst, err := arm64asm.Decode(code)
+	var text string
+	if err != nil || inst.Op == 0 {
+		text = "?"
+	} else {
+		text = arm64asm.GoSyntax(inst, pc, lookup, textReader{code, pc})
+	}
+	return text, 4
+}
+
+func disasm_s390x(code []byte, pc uint64, lookup lookupFunc, _ binary.ByteOrder) (string, int) {
+	inst, err := s390xasm.Decode(code)
+	var text string
+	size := inst.Len
+	if err != nil || size == 0 || inst.Op == 0 {
+		size = 2
+		text = "?"
+	} else {
+		text = s390xasm.GoSyntax(inst, pc, lookup)
+	}
+	return text, size
+}
+
+func disasm_riscv64(code []byte, pc uint64, lookup lookupFunc, _ binary.ByteOrder) (string, int) {
+	inst, err := riscv64asm.Decode(code)
+	var text string
+	size := inst.Len
+	if err != nil || size == 0 || inst.Op == 0 {
+		size = 2
+		text = "?"
+	} else {
+		text = riscv64asm.GoSyntax(inst, pc, lookup, textReader{code, pc})
+	}
+	return text, size
+}
+
+func disasm_mips(code []byte, pc uint64, lookup lookupFunc, byteOrder binary.ByteOrder) (string, int) {
+	return mipsGoSyntax(code, pc, lookup, byteOrder, false), 4
+}
+
+func disasm_mips64(code []byte, pc uint64, lookup lookupFunc, byteOrder binary.ByteOrder) (string, int) {
+	return mipsGoSyntax(code, pc, lookup, byteOrder, true), 4
+}
+
 var disasms = map[string]disasmFunc{
-	"386":     disasm_386,
-	"amd64":   disasm_amd64,
-	"arm":     disasm_arm,
-	"ppc64":   disasm_ppc64,
-	"ppc64le": disasm_ppc64,
+	"386":      disasm_386,
+	"amd64":    disasm_amd64,
+	"arm":      disasm_arm,
+	"arm64":    disasm_arm64,
+	"mips":     disasm_mips,
+	"mipsle":   disasm_mips,
+	"mips64":   disasm_mips64,
+	"mips64le": disasm_mips64,
+	"ppc64":    disasm_ppc64,
+	"ppc64le":  disasm_ppc64,
+	"riscv64":  disasm_riscv64,
+	"s390x":    disasm_s390x,
 }
 
 var byteOrders = map[string]binary.ByteOrder{
-	"386":     binary.LittleEndian,
-	"amd64":   binary.LittleEndian,
-	"arm":     binary.LittleEndian,
-	"ppc64":   binary.BigEndian,
-	"ppc64le": binary.LittleEndian,
-	"s390x":   binary.BigEndian,
+	"386":      binary.LittleEndian,
+	"amd64":    binary.LittleEndian,
+	"arm":      binary.LittleEndian,
+	"arm64":    binary.LittleEndian,
+	"mips":     binary.BigEndian,
+	"mipsle":   binary.LittleEndian,
+	"mips64":   binary.BigEndian,
+	"mips64le": binary.LittleEndian,
+	"ppc64":    binary.BigEndian,
+	"ppc64le":  binary.LittleEndian,
+	"riscv64":  binary.LittleEndian,
+	"s390x":    binary.BigEndian,
 }
 
 type Liner interface {
@@ -0,0 +1,56 @@
+package objfile
+
+import (
+	"bufio"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestDisasmGolden decodes the instructions of testdata/disasm/<goarch>.txt,
+// made of lines of the bytes of an instruction in hexadecimal and of its
+// text when decoded at 0x10000. Blank lines and lines starting with # are
+// skipped.
+func TestDisasmGolden(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "disasm", "*.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Fatal("no golden files")
+	}
+	noLookup := func(uint64) (string, uint64) { return "", 0 }
+	for _, file := range files {
+		goarch := strings.TrimSuffix(filepath.Base(file), ".txt")
+		disasm, byteOrder := disasms[goarch], byteOrders[goarch]
+		if disasm == nil || byteOrder == nil {
+			t.Errorf("%s: unsupported architecture", goarch)
+			continue
+		}
+		f, err := os.Open(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s := bufio.NewScanner(f)
+		for n := 1; s.Scan(); n++ {
+			if s.Text() == "" || strings.HasPrefix(s.Text(), "#") {
+				continue
+			}
+			fields := strings.SplitN(s.Text(), "\t", 2)
+			if len(fields) != 2 {
+				t.Fatalf("%s:%d: malformed line", file, n)
+			}
+			code, err := hex.DecodeString(fields[0])
+			if err != nil {
+				t.Fatalf("%s:%d: %v", file, n, err)
+			}
+			text, size := disasm(code, 0x10000, noLookup, byteOrder)
+			if text != fields[1] || size != len(code) {
+				t.Errorf("%s:%d: %s decodes to %q (%d bytes); want %q (%d bytes)", file, n, fields[0], text, size, fields[1], len(code))
+			}
+		}
+		f.Close()
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"debug/dwarf"
 	"debug/elf"
 	"encoding/binary"
+	"encoding/hex"
 	"fmt"
 	"os"
 )
@@ -53,6 +54,9 @@ func (f *elfFile) symbols() ([]Sym, error) {
 				sym.Code = 'R'
 			case elf.SHF_ALLOC | elf.SHF_WRITE:
 				sym.Code = 'D'
+				if sect.Type == elf.SHT_NOBITS {
+					sym.Code = 'B'
+				}
 			}
 		}
 		if elf.ST_BIND(s.Info) == elf.STB_LOCAL {
@@ -106,6 +110,19 @@ func (f *elfFile) goarch() string {
 		return "ppc64"
 	case elf.EM_S390:
 		return "s390x"
+	case elf.EM_AARCH64:
+		return "arm64"
+	case elf.EM_MIPS:
+		arch := "mips"
+		if f.elf.Class == elf.ELFCLASS64 {
+			arch = "mips64"
+		}
+		if f.elf.ByteOrder == binary.LittleEndian {
+			arch += "le"
+		}
+		return arch
+	case elf.EM_RISCV:
+		return "riscv64"
 	}
 	return ""
 }
@@ -122,3 +139,131 @@ func (f *elfFile) loadAddress() (uint64, error) {
 func (f *elfFile) dwarf() (*dwarf.Data, error) {
 	return f.elf.DWARF()
 }
+
+func (f *elfFile) buildInfo() (addr uint64, data []byte, err error) {
+	if sect := f.elf.Section(".go.buildinfo"); sect != nil {
+		data, err = sect.Data()
+		return sect.Addr, data, err
+	}
+	if len(f.elf.Sections) > 0 {
+		return 0, nil, fmt.Errorf("build info not found")
+	}
+	// Without section headers, the build info is
+	// at the start of the first writable segment.
+	for _, p := range f.elf.Progs {
+		if p.Type == elf.PT_LOAD && p.Flags&elf.PF_W != 0 {
+			data = make([]byte, p.Filesz)
+			if _, err := p.ReadAt(data, 0); err != nil {
+				return 0, nil, err
+			}
+			return p.Vaddr, data, nil
+		}
+	}
+	return 0, nil, fmt.Errorf("build info not found")
+}
+
+func (f *elfFile) hardening(h *Hardening) error {
+	h.Format = "elf"
+	h.PIE = f.elf.Type == elf.ET_DYN
+	h.RELRO = "none"
+	for _, p := range f.elf.Progs {
+		switch p.Type {
+		case elf.PT_GNU_RELRO:
+			h.RELRO = "partial"
+		case elf.PT_GNU_STACK:
+			h.NX = p.Flags&elf.PF_X == 0
+		}
+	}
+	if h.RELRO == "partial" && f.bindNow() {
+		// The dynamic linker resolves all the symbols at startup
+		// and the GOT is read-only too.
+		h.RELRO = "full"
+	}
+	if f.elf.Section(".dynamic") != nil || f.hasProg(elf.PT_DYNAMIC) {
+		libs, err := f.elf.ImportedLibraries()
+		if err != nil {
+			return err
+		}
+		h.Libraries = libs
+	}
+	return nil
+}
+
+// bindNow reports whether the dynamic linker must resolve all the symbols
+// when loading the file.
+func (f *elfFile) bindNow() bool {
+	if v, _ := f.elf.DynValue(elf.DT_BIND_NOW); len(v) > 0 {
+		return true
+	}
+	if v, _ := f.elf.DynValue(elf.DT_FLAGS); len(v) > 0 && v[0]&uint64(elf.DF_BIND_NOW) != 0 {
+		return true
+	}
+	v, _ := f.elf.DynValue(elf.DT_FLAGS_1)
+	return len(v) > 0 && v[0]&uint64(elf.DF_1_NOW) != 0
+}
+
+func (f *elfFile) hasProg(typ elf.ProgType) bool {
+	for _, p := range f.elf.Progs {
+		if p.Type == typ {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *elfFile) buildID() (string, error) {
+	desc, err := f.note(".note.go.buildid", "Go\x00\x00", buildIDNote)
+	if err != nil {
+		return "", err
+	}
+	return string(desc), nil
+}
+
+func (f *elfFile) gnuBuildID() (string, error) {
+	desc, err := f.note(".note.gnu.build-id", "GNU\x00", gnuBuildIDNote)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(desc), nil
+}
+
+// note returns the description of an ELF note, looked up in its section
+// and in the segments of notes, where stripped files still have it.
+func (f *elfFile) note(section, name string, typ uint32) ([]byte, error) {
+	if sect := f.elf.Section(section); sect != nil {
+		data, err := sect.Data()
+		if err != nil {
+			return nil, err
+		}
+		if desc := findNote(f.elf.ByteOrder, data, name, typ); desc != nil {
+			return desc, nil
+		}
+	}
+	for _, p := range f.elf.Progs {
+		if p.Type != elf.PT_NOTE {
+			continue
+		}
+		data := make([]byte, p.Filesz)
+		if _, err := p.ReadAt(data, 0); err != nil {
+			return nil, err
+		}
+		if desc := findNote(f.elf.ByteOrder, data, name, typ); desc != nil {
+			return desc, nil
+		}
+	}
+	return nil, fmt.Errorf("build ID note not found")
+}
+
+func (f *elfFile) dataAt(addr, size uint64) ([]byte, error) {
+	for _, p := range f.elf.Progs {
+		if p.Type == elf.PT_LOAD && p.Vaddr <= addr && addr-p.Vaddr < p.Filesz {
+			if n := p.Filesz - (addr - p.Vaddr); size > n {
+				size = n
+			}
+			data := make([]byte, size)
+			_, err := p.ReadAt(data, int64(addr-p.Vaddr))
+			return data, err
+		}
+	}
+	return nil, fmt.Errorf("address %#x not found", addr)
+}
@@ -0,0 +1,143 @@
+package objfile
+
+import (
+	"encoding/binary"
+	"errors"
+	"sort"
+)
+
+// Magic numbers of the pclntab of Go 1.18 and 1.20 and later.
+const (
+	pclntab118 = 0xfffffff0
+	pclntab120 = 0xfffffff1
+)
+
+// FrameTable tells the entries of the Go functions of a binary and the
+// size of their frame at each of their instructions, from the pcsp tables
+// of the pclntab.
+type FrameTable struct {
+	order     binary.ByteOrder
+	quantum   uint64
+	textStart uint64
+	functab   []byte // pairs of function entry and _func offsets
+	funcdata  []byte // _func structures, from the function table
+	pctab     []byte
+	nfunc     int
+}
+
+// FrameTable returns the frame table of the Go binary, which must have
+// been built by Go 1.18 or later.
+func (f *File) FrameTable() (*FrameTable, error) {
+	textStart, _, pclntab, err := f.raw.pcln()
+	if err != nil {
+		return nil, err
+	}
+	return newFrameTable(pclntab, textStart)
+}
+
+var errFrameTable = errors.New("pclntab of Go 1.18 or later required")
+
+func newFrameTable(data []byte, textStart uint64) (*FrameTable, error) {
+	if len(data) < 8 {
+		return nil, errFrameTable
+	}
+	var order binary.ByteOrder
+	switch magic := binary.LittleEndian.Uint32(data); {
+	case magic == pclntab118 || magic == pclntab120:
+		order = binary.LittleEndian
+	case binary.BigEndian.Uint32(data) == pclntab118 || binary.BigEndian.Uint32(data) == pclntab120:
+		order = binary.BigEndian
+	default:
+		return nil, errFrameTable
+	}
+	quantum, ptrSize := uint64(data[6]), int(data[7])
+	if ptrSize != 4 && ptrSize != 8 || len(data) < 8+8*ptrSize {
+		return nil, errFrameTable
+	}
+	// The header is followed by the number of functions and the offsets
+	// of the tables, as words.
+	word := func(i int) uint64 {
+		if ptrSize == 4 {
+			return uint64(order.Uint32(data[8+4*i:]))
+		}
+		return order.Uint64(data[8+8*i:])
+	}
+	nfunc, pctab, funcdata := word(0), word(6), word(7)
+	// The entries and the _func offsets of the functions, followed by
+	// the end of the last one.
+	size := uint64(len(data))
+	if pctab > size || funcdata > size || nfunc > size || size-funcdata < nfunc*8+4 {
+		return nil, errFrameTable
+	}
+	t := &FrameTable{
+		order:     order,
+		quantum:   quantum,
+		textStart: textStart,
+		funcdata:  data[funcdata:],
+		pctab:     data[pctab:],
+		nfunc:     int(nfunc),
+	}
+	t.functab = t.funcdata[:nfunc*8+4]
+	return t, nil
+}
+
+// Frame returns the entry of the function at pc and the size of its frame
+// at pc: the difference between the stack pointer at the entry of the
+// function, where it points to the return address on amd64, and at pc.
+// The size is 0 in the prologue of the function before it has allocated
+// its frame.
+func (t *FrameTable) Frame(pc uint64) (entry uint64, size int64, ok bool) {
+	if pc < t.textStart || t.nfunc == 0 {
+		return 0, 0, false
+	}
+	off := pc - t.textStart
+	entryOff := func(i int) uint64 { return uint64(t.order.Uint32(t.functab[8*i:])) }
+	if off >= entryOff(t.nfunc) {
+		return 0, 0, false
+	}
+	i := sort.Search(t.nfunc, func(i int) bool { return entryOff(i) > off }) - 1
+	if i < 0 {
+		return 0, 0, false
+	}
+	entry = t.textStart + entryOff(i)
+	fn := uint64(t.order.Uint32(t.functab[8*i+4:]))
+	// _func: entryOff, nameOff, args, deferreturn, pcsp, ...
+	if fn+20 > uint64(len(t.funcdata)) {
+		return 0, 0, false
+	}
+	pcsp := uint64(t.order.Uint32(t.funcdata[fn+16:]))
+	size, ok = t.pcvalue(pcsp, entry, pc)
+	return entry, size, ok
+}
+
+// pcvalue returns the value at pc of the table at off of the function at
+// entry, as decoded by the runtime's pcvalue: pairs of a zigzag-encoded
+// value delta and of a PC delta in quanta, starting at -1.
+func (t *FrameTable) pcvalue(off, entry, pc uint64) (int64, bool) {
+	if off == 0 || off >= uint64(len(t.pctab)) {
+		return 0, false
+	}
+	p := t.pctab[off:]
+	val, cur := int64(-1), entry
+	for first := true; ; first = false {
+		uv, n := binary.Uvarint(p)
+		if n <= 0 || uv == 0 && !first {
+			return 0, false
+		}
+		p = p[n:]
+		if uv&1 != 0 {
+			val += ^int64(uv >> 1)
+		} else {
+			val += int64(uv >> 1)
+		}
+		pcDelta, n := binary.Uvarint(p)
+		if n <= 0 {
+			return 0, false
+		}
+		p = p[n:]
+		cur += pcDelta * t.quantum
+		if pc < cur {
+			return val, true
+		}
+	}
+}
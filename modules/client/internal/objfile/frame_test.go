@@ -0,0 +1,39 @@
+package objfile
+
+import (
+	"debug/gosym"
+	"os"
+	"testing"
+)
+
+func TestFrameTable(t *testing.T) {
+	f, err := Open(os.Args[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	tab, err := f.FrameTable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Test binaries have no symbol table, the functions are looked up in
+	// the pclntab.
+	pcln, err := f.PCLineTable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fn := pcln.(*gosym.Table).LookupFunc("runtime.main")
+	if fn == nil {
+		t.Fatal("no runtime.main")
+	}
+	// The frame is allocated by the prologue.
+	if entry, size, ok := tab.Frame(fn.Entry); !ok || entry != fn.Entry || size != 0 {
+		t.Errorf("Frame(entry of runtime.main) = %#x, %d, %v; want %#x, 0, true", entry, size, ok, fn.Entry)
+	}
+	if entry, size, ok := tab.Frame((fn.Entry + fn.End) / 2); !ok || entry != fn.Entry || size <= 0 {
+		t.Errorf("Frame(middle of runtime.main) = %#x, %d, %v; want %#x, >0, true", entry, size, ok, fn.Entry)
+	}
+	if _, _, ok := tab.Frame(0); ok {
+		t.Error("Frame(0) found a function")
+	}
+}
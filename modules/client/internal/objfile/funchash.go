@@ -0,0 +1,117 @@
+package objfile
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// Funcs returns the functions of the text segment, sorted by address.
+func (d *Disasm) Funcs() []Sym {
+	var funcs []Sym
+	for _, sym := range d.syms {
+		if (sym.Code == 'T' || sym.Code == 't') && sym.Size > 0 &&
+			sym.Addr >= d.textStart && sym.Addr+uint64(sym.Size) <= d.textEnd {
+			funcs = append(funcs, sym)
+		}
+	}
+	return funcs
+}
+
+// Code returns the machine code of the function sym.
+func (d *Disasm) Code(sym Sym) []byte {
+	if sym.Addr < d.textStart || sym.Addr+uint64(sym.Size) > d.textEnd {
+		return nil
+	}
+	i := sym.Addr - d.textStart
+	return d.text[i : i+uint64(sym.Size)]
+}
+
+// CodeHash returns a hash of the instructions of the function sym that
+// does not depend on where the linker placed the function and the code
+// and data it refers to, so that the same function compiled into two
+// binaries has the same hash.
+func (d *Disasm) CodeHash(sym Sym) [sha256.Size]byte {
+	start, end := sym.Addr, sym.Addr+uint64(sym.Size)
+	if start < d.textStart || end > d.textEnd {
+		// No instructions: the hash of the empty input.
+		return sha256.Sum256(nil)
+	}
+	h := sha256.New()
+	// The instructions are decoded without symbols: the disassemblers
+	// also name the immediates that happen to fall within a symbol.
+	noLookup := func(uint64) (string, uint64) { return "", 0 }
+	code := d.text[:end-d.textStart]
+	for pc := start; pc < end; {
+		text, size := d.disasm(code[pc-d.textStart:], pc, noLookup, d.byteOrder)
+		if size <= 0 {
+			break
+		}
+		pc += uint64(size)
+		h.Write([]byte(normalize(text, start, end, pc, d.textStart, d.lookup)))
+		h.Write([]byte{'\n'})
+	}
+	var sum [sha256.Size]byte
+	h.Sum(sum[:0])
+	return sum
+}
+
+// addrRE matches the hexadecimal numbers of an instruction text, with the
+// "$" of immediates, the sign of displacements and the "(IP)" or "(PC)" of
+// relative operands.
+var addrRE = regexp.MustCompile(`[$-]?0x[0-9a-f]+(\((IP|PC)\))?`)
+
+// normalize rewrites the addresses of the instruction text of a function
+// spanning [start, end): branches within the function become offsets from
+// its start, the other addresses of the binary, at or above textStart,
+// become the symbol they refer to, or "addr" if there is none. next is the
+// address of the following instruction, relative operands are relative to
+// it. Immediates are kept as is.
+func normalize(text string, start, end, next, textStart uint64, lookup lookupFunc) string {
+	return addrRE.ReplaceAllStringFunc(text, func(s string) string {
+		if s[0] == '$' {
+			return s
+		}
+		neg := s[0] == '-'
+		hex := s
+		if neg {
+			hex = s[1:]
+		}
+		n := len(hex)
+		relative := hex[n-1] == ')'
+		if relative {
+			n -= len("(IP)")
+		}
+		v, err := strconv.ParseUint(hex[2:n], 16, 64)
+		if err != nil {
+			return s
+		}
+		if relative {
+			addr := next + v
+			if neg {
+				addr = next - v
+			}
+			if addr >= start && addr < end {
+				return fmt.Sprintf("+%#x%s", addr-start, hex[n:])
+			}
+			if name, base := lookup(addr); name != "" {
+				if addr != base {
+					name += fmt.Sprintf("%+d", addr-base)
+				}
+				return name + "(SB)"
+			}
+			return "addr" + hex[n:]
+		}
+		switch {
+		case neg || v < textStart:
+			return s
+		case v >= start && v < end:
+			return fmt.Sprintf("+%#x", v-start)
+		}
+		if name, base := lookup(v); name != "" && v == base {
+			return name + "(SB)"
+		}
+		return "addr"
+	})
+}
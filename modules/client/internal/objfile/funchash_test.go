@@ -0,0 +1,64 @@
+package objfile
+
+import (
+	"crypto/sha256"
+	"os"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	lookup := func(addr uint64) (string, uint64) {
+		if addr >= 0x2000 && addr < 0x2100 {
+			return "runtime.x", 0x2000
+		}
+		return "", 0
+	}
+	for _, tt := range []struct {
+		text string
+		want string
+	}{
+		{"JBE 0x1040", "JBE +0x40"},
+		{"JMP 0x1800", "JMP addr"},
+		{"CALL 0x2000", "CALL runtime.x(SB)"},
+		{"SUBQ $0x98, SP", "SUBQ $0x98, SP"},
+		{"LEAQ -0x20(SP), R12", "LEAQ -0x20(SP), R12"},
+		{"MOVQ 0x90(SP), CX", "MOVQ 0x90(SP), CX"},
+		{"LEAQ 0xff8(IP), AX", "LEAQ runtime.x(SB), AX"},
+		{"MOVQ 0x1000(IP), AX", "MOVQ runtime.x+8(SB), AX"},
+		{"LEAQ -0x8(IP), AX", "LEAQ +0x0(IP), AX"},
+		{"CMPL $0x0, 0x5000(IP)", "CMPL $0x0, addr(IP)"},
+		{"CALL runtime.newobject(SB)", "CALL runtime.newobject(SB)"},
+	} {
+		if got := normalize(tt.text, 0x1000, 0x1100, 0x1008, 0x1000, lookup); got != tt.want {
+			t.Errorf("normalize(%q) = %q; want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestCodeHash(t *testing.T) {
+	f, err := Open(os.Args[0])
+	if err != nil {
+		t.Skip(err)
+	}
+	defer f.Close()
+	d, err := f.Disasm()
+	if err != nil {
+		t.Skip(err)
+	}
+	funcs := d.Funcs()
+	if len(funcs) == 0 {
+		t.Fatal("no functions")
+	}
+	for _, fn := range funcs {
+		if int64(len(d.Code(fn))) != fn.Size {
+			t.Fatalf("len(Code(%s)) = %d; want %d", fn.Name, len(d.Code(fn)), fn.Size)
+		}
+	}
+	if d.CodeHash(funcs[0]) != d.CodeHash(funcs[0]) {
+		t.Error("CodeHash is not deterministic")
+	}
+	// Functions out of the text section have no instructions.
+	if d.CodeHash(Sym{Name: "data", Addr: 0, Size: 16}) != sha256.Sum256(nil) {
+		t.Error("CodeHash of a function out of text is not the empty hash")
+	}
+}
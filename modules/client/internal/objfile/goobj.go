@@ -6,19 +6,16 @@
 
 package objfile
 
-/*
-
-
 import (
 	"debug/dwarf"
 	"debug/gosym"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 
-	"github.com/wgliang/opengacm/modules/client/internal/sys"
-
 	"github.com/wgliang/opengacm/modules/client/internal/goobj"
+	"github.com/wgliang/opengacm/modules/client/internal/sys"
 )
 
 type goobjFile struct {
@@ -119,14 +116,17 @@ func (f *goobjFile) PCToLine(pc uint64) (string, int, *gosym.Func) {
 		if err != nil {
 			return "", 0, nil
 		}
-		fileID := gosym.PCValue(pcfile, pc-uint64(s.Data.Offset), arch.MinLC)
+		fileID := pcValue(pcfile, pc-uint64(s.Data.Offset), arch.MinLC)
+		if fileID < 0 || int(fileID) >= len(s.Func.File) {
+			return "", 0, nil
+		}
 		fileName := s.Func.File[fileID]
 		pcline := make([]byte, s.Func.PCLine.Size)
 		_, err = f.f.ReadAt(pcline, s.Func.PCLine.Offset)
 		if err != nil {
 			return "", 0, nil
 		}
-		line := gosym.PCValue(pcline, pc-uint64(s.Data.Offset), arch.MinLC)
+		line := int(pcValue(pcline, pc-uint64(s.Data.Offset), arch.MinLC))
 		// Note: we provide only the name in the Func structure.
 		// We could provide more if needed.
 		return fileName, line, &gosym.Func{Sym: &gosym.Sym{Name: s.Name}}
@@ -134,6 +134,54 @@ func (f *goobjFile) PCToLine(pc uint64) (string, int, *gosym.Func) {
 	return "", 0, nil
 }
 
+// pcValue looks up the given PC in a pc value table. target is the
+// offset of the pc from the entry point.
+func pcValue(tab []byte, target uint64, minLC int) int32 {
+	val := int32(-1)
+	var pc uint64
+	for step(&tab, &pc, &val, pc == 0, minLC) {
+		if target < pc {
+			return val
+		}
+	}
+	return -1
+}
+
+// step advances to the next pc, value pair in the encoded table.
+func step(p *[]byte, pc *uint64, val *int32, first bool, minLC int) bool {
+	uvdelta := readvarint(p)
+	if uvdelta == 0 && !first {
+		return false
+	}
+	if uvdelta&1 != 0 {
+		uvdelta = ^(uvdelta >> 1)
+	} else {
+		uvdelta >>= 1
+	}
+	vdelta := int32(uvdelta)
+	pcdelta := readvarint(p) * uint32(minLC)
+	*pc += uint64(pcdelta)
+	*val += vdelta
+	return true
+}
+
+// readvarint reads, removes, and returns a varint from *p.
+// A truncated table reads as its end.
+func readvarint(p *[]byte) uint32 {
+	var v, shift uint32
+	s := *p
+	for shift = 0; len(s) > 0; shift += 7 {
+		b := s[0]
+		s = s[1:]
+		v |= (uint32(b) & 0x7F) << shift
+		if b&0x80 == 0 {
+			break
+		}
+	}
+	*p = s
+	return v
+}
+
 // We treat the whole object file as the text section.
 func (f *goobjFile) text() (textStart uint64, text []byte, err error) {
 	var info os.FileInfo
@@ -157,4 +205,28 @@ func (f *goobjFile) loadAddress() (uint64, error) {
 func (f *goobjFile) dwarf() (*dwarf.Data, error) {
 	return nil, errors.New("no DWARF data in go object file")
 }
-*/
+
+func (f *goobjFile) buildInfo() (addr uint64, data []byte, err error) {
+	return 0, nil, fmt.Errorf("build info not found")
+}
+
+func (f *goobjFile) hardening(h *Hardening) error {
+	return fmt.Errorf("hardening not applicable to go object files")
+}
+
+func (f *goobjFile) buildID() (string, error) {
+	if f.goobj.BuildID == "" {
+		return "", fmt.Errorf("build ID not found")
+	}
+	return f.goobj.BuildID, nil
+}
+
+// Addresses are offsets in the object file.
+func (f *goobjFile) dataAt(addr, size uint64) ([]byte, error) {
+	data := make([]byte, size)
+	n, err := f.f.ReadAt(data, int64(addr))
+	if n > 0 && err == io.EOF {
+		err = nil
+	}
+	return data[:n], err
+}
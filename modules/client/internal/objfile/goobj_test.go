@@ -0,0 +1,104 @@
+package objfile
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var goobjSources = map[string]string{
+	"a.go": `package p
+
+import "fmt"
+
+var Counter int
+
+func Hello(n int) string {
+	Counter++
+	return fmt.Sprint("hello", n)
+}
+`,
+	"b.go": `package p
+
+func Twice(n int) int {
+	return n * 2
+}
+`,
+}
+
+func TestGoobj(t *testing.T) {
+	gotool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not found")
+	}
+	dir, err := ioutil.TempDir("", "goobj")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	src := filepath.Join(dir, "p")
+	if err := os.Mkdir(src, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range goobjSources {
+		if err := ioutil.WriteFile(filepath.Join(src, name), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	archive := filepath.Join(dir, "p.a")
+	cmd := exec.Command(gotool, "build", "-o", archive, ".")
+	cmd.Dir = src
+	cmd.Env = append(os.Environ(), "GO111MODULE=off", "GOFLAGS=")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Skipf("go build: %v\n%s", err, out)
+	}
+
+	f, err := Open(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	syms, err := f.Symbols()
+	if err != nil {
+		t.Fatal(err)
+	}
+	byName := make(map[string]Sym)
+	for _, s := range syms {
+		if i := strings.LastIndex(s.Name, "/p."); i >= 0 {
+			s.Name = s.Name[i+1:]
+		}
+		byName[s.Name] = s
+	}
+	for name, code := range map[string]rune{"p.Hello": 'T', "p.Twice": 'T', "p.Counter": 'B', "fmt.Sprint": 'U'} {
+		if s, ok := byName[name]; !ok || s.Code != code {
+			t.Errorf("symbol %s: code %c, found %v; want %c", name, s.Code, ok, code)
+		}
+	}
+	hello := byName["p.Hello"]
+	var calls bool
+	for _, r := range hello.Relocs {
+		calls = calls || strings.Contains(r.Stringer.String(0), "R_CALL:fmt.Sprint")
+	}
+	if !calls {
+		t.Errorf("p.Hello has no call relocation to fmt.Sprint")
+	}
+
+	twice := byName["p.Twice"]
+	if twice.Size == 0 {
+		t.Fatal("p.Twice is empty")
+	}
+	pcln, err := f.PCLineTable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	file, line, fn := pcln.PCToLine(twice.Addr)
+	if filepath.Base(file) != "b.go" || line != 4 || fn == nil {
+		t.Errorf("PCToLine(p.Twice) = %s:%d, %v; want b.go:4", file, line, fn)
+	}
+	if id, err := f.BuildID(); err != nil || id == "" {
+		t.Errorf("BuildID() = %q, %v", id, err)
+	}
+}
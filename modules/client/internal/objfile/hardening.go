@@ -0,0 +1,30 @@
+package objfile
+
+// Hardening describes the exploit mitigations and the debugging information
+// of an executable.
+type Hardening struct {
+	Format    string   // "elf", "macho" or "pe"
+	PIE       bool     // position-independent, its address randomized
+	RELRO     string   // "none", "partial" or "full", empty if not applicable
+	NX        bool     // non-executable stack and data
+	Stripped  bool     // no symbol table
+	DWARF     bool     // DWARF debugging information
+	Libraries []string // dynamic libraries linked
+}
+
+// Hardening returns the exploit mitigations and the debugging information
+// of the executable.
+func (f *File) Hardening() (*Hardening, error) {
+	h := new(Hardening)
+	if err := f.raw.hardening(h); err != nil {
+		return nil, err
+	}
+	syms, err := f.Symbols()
+	h.Stripped = err != nil || !hasText(syms)
+	if d, err := f.DWARF(); err == nil {
+		// Binaries linked with -w may still have an empty DWARF section.
+		_, err = d.Reader().Next()
+		h.DWARF = err == nil
+	}
+	return h, nil
+}
@@ -0,0 +1,29 @@
+package objfile
+
+import (
+	"os"
+	"runtime"
+	"testing"
+)
+
+func TestHardening(t *testing.T) {
+	f, err := Open(os.Args[0])
+	if err != nil {
+		t.Skip(err)
+	}
+	defer f.Close()
+	h, err := f.Hardening()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"linux": "elf", "darwin": "macho", "windows": "pe"}[runtime.GOOS]
+	if want != "" && h.Format != want {
+		t.Errorf("Format = %q; want %q", h.Format, want)
+	}
+	if !h.NX {
+		t.Error("test binary has an executable stack")
+	}
+	if h.Format == "elf" && h.RELRO == "" {
+		t.Error("RELRO not reported for ELF")
+	}
+}
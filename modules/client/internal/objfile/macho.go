@@ -113,6 +113,8 @@ func (f *machoFile) goarch() string {
 		return "amd64"
 	case macho.CpuArm:
 		return "arm"
+	case macho.CpuArm64:
+		return "arm64"
 	case macho.CpuPpc64:
 		return "ppc64"
 	}
@@ -132,3 +134,54 @@ func (f *machoFile) loadAddress() (uint64, error) {
 func (f *machoFile) dwarf() (*dwarf.Data, error) {
 	return f.macho.DWARF()
 }
+
+func (f *machoFile) buildInfo() (addr uint64, data []byte, err error) {
+	if sect := f.macho.Section("__go_buildinfo"); sect != nil {
+		data, err = sect.Data()
+		return sect.Addr, data, err
+	}
+	if seg := f.macho.Segment("__DATA"); seg != nil {
+		data, err = seg.Data()
+		return seg.Addr, data, err
+	}
+	return 0, nil, fmt.Errorf("build info not found")
+}
+
+// Mach-O header flags.
+const (
+	machoAllowStackExecution = 0x20000
+	machoPIE                 = 0x200000
+	machoNoHeapExecution     = 0x1000000
+)
+
+func (f *machoFile) hardening(h *Hardening) error {
+	h.Format = "macho"
+	h.PIE = f.macho.Flags&machoPIE != 0
+	// The heap is not executable on 64-bit architectures.
+	h.NX = f.macho.Flags&machoAllowStackExecution == 0 &&
+		(f.macho.Magic == macho.Magic64 || f.macho.Flags&machoNoHeapExecution != 0)
+	libs, err := f.macho.ImportedLibraries()
+	if err != nil {
+		return err
+	}
+	h.Libraries = libs
+	return nil
+}
+
+func (f *machoFile) buildID() (string, error) {
+	return textBuildID(f)
+}
+
+func (f *machoFile) dataAt(addr, size uint64) ([]byte, error) {
+	for _, sect := range f.macho.Sections {
+		if sect.Addr <= addr && addr-sect.Addr < sect.Size {
+			if n := sect.Size - (addr - sect.Addr); size > n {
+				size = n
+			}
+			data := make([]byte, size)
+			_, err := sect.ReadAt(data, int64(addr-sect.Addr))
+			return data, err
+		}
+	}
+	return nil, fmt.Errorf("address %#x not found", addr)
+}
@@ -0,0 +1,404 @@
+package objfile
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// golang.org/x/arch has no MIPS disassembler: the instructions the Go
+// toolchain emits for mips, mipsle, mips64 and mips64le are decoded here,
+// and printed in the syntax of the Go assembler, with the operands in the
+// same order.
+
+// mipsGoSyntax returns the Go assembler syntax of the instruction at the
+// start of code, or "?" if it is unknown. mips64 enables the 64-bit
+// instructions.
+func mipsGoSyntax(code []byte, pc uint64, lookup lookupFunc, byteOrder binary.ByteOrder, mips64 bool) string {
+	if len(code) < 4 {
+		return "?"
+	}
+	w := byteOrder.Uint32(code)
+	i := mipsInst{w: w, pc: pc, lookup: lookup, mips64: mips64}
+	if text := i.decode(); text != "" {
+		return text
+	}
+	return "?"
+}
+
+type mipsInst struct {
+	w      uint32
+	pc     uint64
+	lookup lookupFunc
+	mips64 bool
+}
+
+func (i mipsInst) op() uint32    { return i.w >> 26 }
+func (i mipsInst) rs() uint32    { return i.w >> 21 & 31 }
+func (i mipsInst) rt() uint32    { return i.w >> 16 & 31 }
+func (i mipsInst) rd() uint32    { return i.w >> 11 & 31 }
+func (i mipsInst) sa() uint32    { return i.w >> 6 & 31 }
+func (i mipsInst) funct() uint32 { return i.w & 63 }
+func (i mipsInst) simm() int32   { return int32(int16(i.w)) }
+func (i mipsInst) uimm() uint32  { return i.w & 0xffff }
+
+func mipsReg(r uint32) string  { return fmt.Sprintf("R%d", r) }
+func mipsFReg(r uint32) string { return fmt.Sprintf("F%d", r) }
+
+// word returns the suffix of the moves of registers, W or V on mips64.
+func (i mipsInst) word() string {
+	if i.mips64 {
+		return "V"
+	}
+	return "W"
+}
+
+// branch returns the target of a branch with a 16-bit offset.
+func (i mipsInst) branch() string {
+	return i.target(i.pc + 4 + uint64(int64(i.simm())<<2))
+}
+
+func (i mipsInst) target(addr uint64) string {
+	if i.lookup != nil {
+		if s, base := i.lookup(addr); s != "" && addr == base {
+			return s + "(SB)"
+		}
+	}
+	return fmt.Sprintf("%#x", addr)
+}
+
+// mem returns a memory operand, as in 8(R29).
+func (i mipsInst) mem() string {
+	return fmt.Sprintf("%d(%s)", i.simm(), mipsReg(i.rs()))
+}
+
+// rrr formats the three registers operands of op, in the order of the Go
+// assembler: "ADDU R1, R2, R3" computes R3 = R2 + R1.
+func (i mipsInst) rrr(op string) string {
+	return fmt.Sprintf("%s %s, %s, %s", op, mipsReg(i.rt()), mipsReg(i.rs()), mipsReg(i.rd()))
+}
+
+// irr formats an operation of an immediate and a register.
+func (i mipsInst) irr(op string, imm string) string {
+	return fmt.Sprintf("%s $%s, %s, %s", op, imm, mipsReg(i.rs()), mipsReg(i.rt()))
+}
+
+// shift formats a shift by the sa field, plus add.
+func (i mipsInst) shift(op string, add uint32) string {
+	return fmt.Sprintf("%s $%d, %s, %s", op, i.sa()+add, mipsReg(i.rt()), mipsReg(i.rd()))
+}
+
+// Names of the loads and stores by opcode.
+var mipsLoads = map[uint32]string{
+	0x20: "MOVB", 0x21: "MOVH", 0x22: "MOVWL", 0x23: "MOVW", 0x24: "MOVBU",
+	0x25: "MOVHU", 0x26: "MOVWR", 0x30: "LL",
+}
+
+var mipsStores = map[uint32]string{
+	0x28: "MOVB", 0x29: "MOVH", 0x2a: "MOVWL", 0x2b: "MOVW", 0x2e: "MOVWR",
+	0x38: "SC",
+}
+
+var mips64Loads = map[uint32]string{
+	0x1a: "MOVVL", 0x1b: "MOVVR", 0x27: "MOVWU", 0x34: "LLV", 0x37: "MOVV",
+}
+
+var mips64Stores = map[uint32]string{
+	0x2c: "MOVVL", 0x2d: "MOVVR", 0x3c: "SCV", 0x3f: "MOVV",
+}
+
+// decode returns the text of the instruction, or "" if it is unknown.
+func (i mipsInst) decode() string {
+	switch op := i.op(); op {
+	case 0x00:
+		return i.special()
+	case 0x01:
+		return i.regimm()
+	case 0x02:
+		return "JMP " + i.target((i.pc+4)&^0x0fffffff|uint64(i.w&0x03ffffff)<<2)
+	case 0x03:
+		return "JAL " + i.target((i.pc+4)&^0x0fffffff|uint64(i.w&0x03ffffff)<<2)
+	case 0x04:
+		if i.rs() == 0 && i.rt() == 0 {
+			return "JMP " + i.branch()
+		}
+		return fmt.Sprintf("BEQ %s, %s, %s", mipsReg(i.rs()), mipsReg(i.rt()), i.branch())
+	case 0x05:
+		return fmt.Sprintf("BNE %s, %s, %s", mipsReg(i.rs()), mipsReg(i.rt()), i.branch())
+	case 0x06:
+		return fmt.Sprintf("BLEZ %s, %s", mipsReg(i.rs()), i.branch())
+	case 0x07:
+		return fmt.Sprintf("BGTZ %s, %s", mipsReg(i.rs()), i.branch())
+	case 0x08:
+		return i.irr("ADD", fmt.Sprint(i.simm()))
+	case 0x09:
+		return i.irr("ADDU", fmt.Sprint(i.simm()))
+	case 0x0a:
+		return i.irr("SGT", fmt.Sprint(i.simm()))
+	case 0x0b:
+		return i.irr("SGTU", fmt.Sprint(i.simm()))
+	case 0x0c:
+		return i.irr("AND", fmt.Sprintf("%#x", i.uimm()))
+	case 0x0d:
+		return i.irr("OR", fmt.Sprintf("%#x", i.uimm()))
+	case 0x0e:
+		return i.irr("XOR", fmt.Sprintf("%#x", i.uimm()))
+	case 0x0f:
+		// The assembler has no LUI: it emits one for the moves of constants
+		// whose lower half is zero, sign extended on mips64.
+		if i.mips64 {
+			return fmt.Sprintf("MOVV $%#x, %s", int64(int32(i.w<<16)), mipsReg(i.rt()))
+		}
+		return fmt.Sprintf("MOVW $%#x, %s", i.w<<16, mipsReg(i.rt()))
+	case 0x11:
+		return i.cop1()
+	case 0x18:
+		if i.mips64 {
+			return i.irr("ADDV", fmt.Sprint(i.simm()))
+		}
+	case 0x19:
+		if i.mips64 {
+			return i.irr("ADDVU", fmt.Sprint(i.simm()))
+		}
+	case 0x1c:
+		switch i.funct() {
+		case 0x02:
+			return i.rrr("MUL")
+		case 0x20:
+			return fmt.Sprintf("CLZ %s, %s", mipsReg(i.rs()), mipsReg(i.rd()))
+		case 0x21:
+			return fmt.Sprintf("CLO %s, %s", mipsReg(i.rs()), mipsReg(i.rd()))
+		}
+	case 0x1f:
+		switch {
+		case i.funct() == 0x3b:
+			return fmt.Sprintf("RDHWR $%d, %s", i.rd(), mipsReg(i.rt()))
+		case i.funct() == 0x20 && i.sa() == 0x10:
+			return fmt.Sprintf("SEB %s, %s", mipsReg(i.rt()), mipsReg(i.rd()))
+		case i.funct() == 0x20 && i.sa() == 0x18:
+			return fmt.Sprintf("SEH %s, %s", mipsReg(i.rt()), mipsReg(i.rd()))
+		}
+	case 0x31, 0x35:
+		return fmt.Sprintf("%s %s, %s", map[uint32]string{0x31: "MOVF", 0x35: "MOVD"}[op], i.mem(), mipsFReg(i.rt()))
+	case 0x39, 0x3d:
+		return fmt.Sprintf("%s %s, %s", map[uint32]string{0x39: "MOVF", 0x3d: "MOVD"}[op], mipsFReg(i.rt()), i.mem())
+	case 0x2f:
+		return fmt.Sprintf("CACHE $%d, %s", i.rt(), i.mem())
+	case 0x33:
+		return fmt.Sprintf("PREF $%d, %s", i.rt(), i.mem())
+	default:
+		if name, ok := mipsLoads[op]; ok {
+			return fmt.Sprintf("%s %s, %s", name, i.mem(), mipsReg(i.rt()))
+		}
+		if name, ok := mipsStores[op]; ok {
+			return fmt.Sprintf("%s %s, %s", name, mipsReg(i.rt()), i.mem())
+		}
+		if !i.mips64 {
+			break
+		}
+		if name, ok := mips64Loads[op]; ok {
+			return fmt.Sprintf("%s %s, %s", name, i.mem(), mipsReg(i.rt()))
+		}
+		if name, ok := mips64Stores[op]; ok {
+			return fmt.Sprintf("%s %s, %s", name, mipsReg(i.rt()), i.mem())
+		}
+	}
+	return ""
+}
+
+// Names of the three registers operations of the SPECIAL opcode by
+// function, and of their 64-bit variants.
+var mipsRRR = map[uint32]string{
+	0x20: "ADD", 0x21: "ADDU", 0x22: "SUB", 0x23: "SUBU", 0x24: "AND",
+	0x25: "OR", 0x26: "XOR", 0x27: "NOR", 0x2a: "SGT", 0x2b: "SGTU",
+	0x0a: "CMOVZ", 0x0b: "CMOVN",
+}
+
+var mips64RRR = map[uint32]string{
+	0x2c: "ADDV", 0x2d: "ADDVU", 0x2e: "SUBV", 0x2f: "SUBVU",
+}
+
+// Names of the multiplications and divisions, which write HI and LO.
+var mipsHiLo = map[uint32]string{
+	0x18: "MUL", 0x19: "MULU", 0x1a: "DIV", 0x1b: "DIVU",
+}
+
+var mips64HiLo = map[uint32]string{
+	0x1c: "MULV", 0x1d: "MULVU", 0x1e: "DIVV", 0x1f: "DIVVU",
+}
+
+func (i mipsInst) special() string {
+	if i.w == 0 {
+		return "NOOP"
+	}
+	rs, rt, rd := mipsReg(i.rs()), mipsReg(i.rt()), mipsReg(i.rd())
+	switch f := i.funct(); f {
+	case 0x00:
+		return i.shift("SLL", 0)
+	case 0x01:
+		// Moves conditional on the floating-point condition code.
+		if i.rt()&1 != 0 {
+			return fmt.Sprintf("CMOVT %s, %s", rs, rd)
+		}
+		return fmt.Sprintf("CMOVF %s, %s", rs, rd)
+	case 0x02:
+		return i.shift("SRL", 0)
+	case 0x03:
+		return i.shift("SRA", 0)
+	case 0x04:
+		return fmt.Sprintf("SLL %s, %s, %s", rs, rt, rd)
+	case 0x06:
+		return fmt.Sprintf("SRL %s, %s, %s", rs, rt, rd)
+	case 0x07:
+		return fmt.Sprintf("SRA %s, %s, %s", rs, rt, rd)
+	case 0x08:
+		if i.rs() == 31 {
+			return "RET"
+		}
+		return fmt.Sprintf("JMP (%s)", rs)
+	case 0x09:
+		return fmt.Sprintf("JAL (%s)", rs)
+	case 0x0c:
+		return "SYSCALL"
+	case 0x0d:
+		return "BREAK"
+	case 0x0f:
+		return "SYNC"
+	case 0x10:
+		return fmt.Sprintf("MOV%s HI, %s", i.word(), rd)
+	case 0x11:
+		return fmt.Sprintf("MOV%s %s, HI", i.word(), rs)
+	case 0x12:
+		return fmt.Sprintf("MOV%s LO, %s", i.word(), rd)
+	case 0x13:
+		return fmt.Sprintf("MOV%s %s, LO", i.word(), rs)
+	case 0x34:
+		// The trap code is in the 10 bits above the function.
+		if i.rs() == 0 {
+			return fmt.Sprintf("TEQ $%d, %s", i.w>>6&0x3ff, rt)
+		}
+		return fmt.Sprintf("TEQ $%d, %s, %s", i.w>>6&0x3ff, rs, rt)
+	default:
+		if name, ok := mipsRRR[f]; ok {
+			return i.rrr(name)
+		}
+		if name, ok := mipsHiLo[f]; ok {
+			return fmt.Sprintf("%s %s, %s", name, rt, rs)
+		}
+		if !i.mips64 {
+			break
+		}
+		if name, ok := mips64RRR[f]; ok {
+			return i.rrr(name)
+		}
+		if name, ok := mips64HiLo[f]; ok {
+			return fmt.Sprintf("%s %s, %s", name, rt, rs)
+		}
+		switch f {
+		case 0x14:
+			return fmt.Sprintf("SLLV %s, %s, %s", rs, rt, rd)
+		case 0x16:
+			return fmt.Sprintf("SRLV %s, %s, %s", rs, rt, rd)
+		case 0x17:
+			return fmt.Sprintf("SRAV %s, %s, %s", rs, rt, rd)
+		case 0x38:
+			return i.shift("SLLV", 0)
+		case 0x3a:
+			return i.shift("SRLV", 0)
+		case 0x3b:
+			return i.shift("SRAV", 0)
+		case 0x3c:
+			return i.shift("SLLV", 32)
+		case 0x3e:
+			return i.shift("SRLV", 32)
+		case 0x3f:
+			return i.shift("SRAV", 32)
+		}
+	}
+	return ""
+}
+
+func (i mipsInst) regimm() string {
+	rs := mipsReg(i.rs())
+	switch i.rt() {
+	case 0x00:
+		return fmt.Sprintf("BLTZ %s, %s", rs, i.branch())
+	case 0x01:
+		return fmt.Sprintf("BGEZ %s, %s", rs, i.branch())
+	case 0x10:
+		return fmt.Sprintf("BLTZAL %s, %s", rs, i.branch())
+	case 0x11:
+		if i.rs() == 0 {
+			return "JAL " + i.branch()
+		}
+		return fmt.Sprintf("BGEZAL %s, %s", rs, i.branch())
+	}
+	return ""
+}
+
+// Letters of the formats of the floating-point instructions, as in the
+// names of the conversions of the Go assembler, such as MOVFD.
+var mipsFmts = map[uint32]string{16: "F", 17: "D", 20: "W", 21: "V"}
+
+func (i mipsInst) cop1() string {
+	// The fields of floating-point instructions: fmt, ft, fs and fd
+	// are where rs, rt, rd and sa are.
+	ft, fs, fd := mipsFReg(i.rt()), mipsFReg(i.rd()), mipsFReg(i.sa())
+	switch i.rs() {
+	case 0x00:
+		return fmt.Sprintf("MOVW %s, %s", fs, mipsReg(i.rt()))
+	case 0x01:
+		if i.mips64 {
+			return fmt.Sprintf("MOVV %s, %s", fs, mipsReg(i.rt()))
+		}
+	case 0x02:
+		return fmt.Sprintf("MOVW FCR%d, %s", i.rd(), mipsReg(i.rt()))
+	case 0x04:
+		return fmt.Sprintf("MOVW %s, %s", mipsReg(i.rt()), fs)
+	case 0x05:
+		if i.mips64 {
+			return fmt.Sprintf("MOVV %s, %s", mipsReg(i.rt()), fs)
+		}
+	case 0x06:
+		return fmt.Sprintf("MOVW %s, FCR%d", mipsReg(i.rt()), i.rd())
+	case 0x08:
+		if i.rt()&1 != 0 {
+			return "BFPT " + i.branch()
+		}
+		return "BFPF " + i.branch()
+	}
+	f, ok := mipsFmts[i.rs()]
+	if !ok {
+		return ""
+	}
+	switch fn := i.funct(); fn {
+	case 0x00:
+		return fmt.Sprintf("ADD%s %s, %s, %s", f, ft, fs, fd)
+	case 0x01:
+		return fmt.Sprintf("SUB%s %s, %s, %s", f, ft, fs, fd)
+	case 0x02:
+		return fmt.Sprintf("MUL%s %s, %s, %s", f, ft, fs, fd)
+	case 0x03:
+		return fmt.Sprintf("DIV%s %s, %s, %s", f, ft, fs, fd)
+	case 0x04:
+		return fmt.Sprintf("SQRT%s %s, %s", f, fs, fd)
+	case 0x05:
+		return fmt.Sprintf("ABS%s %s, %s", f, fs, fd)
+	case 0x06:
+		return fmt.Sprintf("MOV%s %s, %s", f, fs, fd)
+	case 0x07:
+		return fmt.Sprintf("NEG%s %s, %s", f, fs, fd)
+	case 0x09:
+		return fmt.Sprintf("TRUNC%sV %s, %s", f, fs, fd)
+	case 0x0d:
+		return fmt.Sprintf("TRUNC%sW %s, %s", f, fs, fd)
+	case 0x20, 0x21, 0x24, 0x25:
+		return fmt.Sprintf("MOV%s%s %s, %s", f, mipsFmts[fn-0x20+16], fs, fd)
+	case 0x32:
+		return fmt.Sprintf("CMPEQ%s %s, %s", f, ft, fs)
+	case 0x34, 0x3c:
+		return fmt.Sprintf("CMPGT%s %s, %s", f, ft, fs)
+	case 0x36, 0x3e:
+		return fmt.Sprintf("CMPGE%s %s, %s", f, ft, fs)
+	}
+	return ""
+}
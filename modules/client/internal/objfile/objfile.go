@@ -20,6 +20,10 @@ type rawFile interface {
 	goarch() string
 	loadAddress() (uint64, error)
 	dwarf() (*dwarf.Data, error)
+	buildInfo() (addr uint64, data []byte, err error)
+	buildID() (string, error)
+	dataAt(addr, size uint64) ([]byte, error)
+	hardening(h *Hardening) error
 }
 
 // A File is an opened executable file.
@@ -52,7 +56,7 @@ type RelocStringer interface {
 
 var openers = []func(*os.File) (rawFile, error){
 	openElf,
-	// openGoobj, // TODO(jbd): Bring it back when 1.8 is popular.
+	openGoobj,
 	openMacho,
 	openPE,
 	openPlan9,
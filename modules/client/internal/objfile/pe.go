@@ -12,6 +12,7 @@ import (
 	"fmt"
 	"os"
 	"sort"
+	"strings"
 )
 
 type peFile struct {
@@ -188,7 +189,16 @@ func loadPETable(f *pe.File, sname, ename string) ([]byte, error) {
 }
 
 func (f *peFile) goarch() string {
-	// Not sure how to get the info we want from PE header.
+	switch f.pe.Machine {
+	case pe.IMAGE_FILE_MACHINE_I386:
+		return "386"
+	case pe.IMAGE_FILE_MACHINE_AMD64:
+		return "amd64"
+	case pe.IMAGE_FILE_MACHINE_ARMNT:
+		return "arm"
+	case pe.IMAGE_FILE_MACHINE_ARM64:
+		return "arm64"
+	}
 	// Look in symbol table for telltale rt0 symbol.
 	if _, err := findPESymbol(f.pe, "_rt0_386_windows"); err == nil {
 		return "386"
@@ -206,3 +216,79 @@ func (f *peFile) loadAddress() (uint64, error) {
 func (f *peFile) dwarf() (*dwarf.Data, error) {
 	return f.pe.DWARF()
 }
+
+func (f *peFile) buildInfo() (addr uint64, data []byte, err error) {
+	sect := f.pe.Section(".data")
+	if sect == nil {
+		return 0, nil, fmt.Errorf("build info not found")
+	}
+	var imageBase uint64
+	switch oh := f.pe.OptionalHeader.(type) {
+	case *pe.OptionalHeader32:
+		imageBase = uint64(oh.ImageBase)
+	case *pe.OptionalHeader64:
+		imageBase = oh.ImageBase
+	}
+	data, err = sect.Data()
+	return imageBase + uint64(sect.VirtualAddress), data, err
+}
+
+// PE DLL characteristics.
+const (
+	peDynamicBase = 0x40
+	peNXCompat    = 0x100
+)
+
+func (f *peFile) hardening(h *Hardening) error {
+	h.Format = "pe"
+	var flags uint16
+	switch oh := f.pe.OptionalHeader.(type) {
+	case *pe.OptionalHeader32:
+		flags = oh.DllCharacteristics
+	case *pe.OptionalHeader64:
+		flags = oh.DllCharacteristics
+	}
+	h.PIE = flags&peDynamicBase != 0
+	h.NX = flags&peNXCompat != 0
+	// ImportedLibraries is not implemented by debug/pe, the DLLs are
+	// named by the imported symbols, as "symbol:dll".
+	syms, err := f.pe.ImportedSymbols()
+	if err != nil {
+		return err
+	}
+	seen := make(map[string]bool)
+	for _, s := range syms {
+		i := strings.LastIndexByte(s, ':')
+		if dll := strings.ToLower(s[i+1:]); i >= 0 && !seen[dll] {
+			seen[dll] = true
+			h.Libraries = append(h.Libraries, dll)
+		}
+	}
+	return nil
+}
+
+func (f *peFile) buildID() (string, error) {
+	return textBuildID(f)
+}
+
+func (f *peFile) dataAt(addr, size uint64) ([]byte, error) {
+	var imageBase uint64
+	switch oh := f.pe.OptionalHeader.(type) {
+	case *pe.OptionalHeader32:
+		imageBase = uint64(oh.ImageBase)
+	case *pe.OptionalHeader64:
+		imageBase = oh.ImageBase
+	}
+	for _, sect := range f.pe.Sections {
+		start := imageBase + uint64(sect.VirtualAddress)
+		if start <= addr && addr-start < uint64(sect.Size) {
+			if n := uint64(sect.Size) - (addr - start); size > n {
+				size = n
+			}
+			data := make([]byte, size)
+			_, err := sect.ReadAt(data, int64(addr-start))
+			return data, err
+		}
+	}
+	return nil, fmt.Errorf("address %#x not found", addr)
+}
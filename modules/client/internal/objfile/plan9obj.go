@@ -154,3 +154,19 @@ func (f *plan9File) loadAddress() (uint64, error) {
 func (f *plan9File) dwarf() (*dwarf.Data, error) {
 	return nil, errors.New("no DWARF data in Plan 9 file")
 }
+
+func (f *plan9File) buildInfo() (addr uint64, data []byte, err error) {
+	return 0, nil, fmt.Errorf("build info not found")
+}
+
+func (f *plan9File) hardening(h *Hardening) error {
+	return fmt.Errorf("hardening not applicable to Plan 9 files")
+}
+
+func (f *plan9File) buildID() (string, error) {
+	return textBuildID(f)
+}
+
+func (f *plan9File) dataAt(addr, size uint64) ([]byte, error) {
+	return nil, fmt.Errorf("address %#x not found", addr)
+}
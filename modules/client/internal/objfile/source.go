@@ -0,0 +1,70 @@
+package objfile
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SourceCache reads and caches the lines of source files.
+type SourceCache struct {
+	// Dirs are the directories the relative file names recorded by
+	// binaries built with -trimpath are looked up in, such as the root
+	// of a source tree, a GOPATH src directory or a module cache.
+	Dirs  []string
+	files map[string][][]byte
+}
+
+// NewSourceCache returns a cache looking up relative file names in dirs.
+func NewSourceCache(dirs ...string) *SourceCache {
+	return &SourceCache{Dirs: dirs, files: make(map[string][][]byte)}
+}
+
+// Line returns the line-th line, counting from 1, of the file named name
+// in the pcln table.
+func (c *SourceCache) Line(name string, line int) ([]byte, error) {
+	lines, ok := c.files[name]
+	if !ok {
+		b, err := c.read(name)
+		if err == nil {
+			lines = bytes.Split(b, []byte("\n"))
+		}
+		// Missing files are cached too, they are looked up for every
+		// instruction.
+		c.files[name] = lines
+	}
+	if lines == nil {
+		return nil, fmt.Errorf("%s: source not found", name)
+	}
+	if line < 1 || line > len(lines) {
+		return nil, fmt.Errorf("%s:%d: no such line", name, line)
+	}
+	return lines[line-1], nil
+}
+
+// read reads the file named name. Relative names start with the import
+// path of the module or package of the file, and the directories may be
+// the root of the module: the leading elements of the name are dropped
+// one by one until the file is found.
+func (c *SourceCache) read(name string) ([]byte, error) {
+	if filepath.IsAbs(name) {
+		return ioutil.ReadFile(name)
+	}
+	for name != "" {
+		for _, dir := range c.Dirs {
+			b, err := ioutil.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+			if err == nil || !os.IsNotExist(err) {
+				return b, err
+			}
+		}
+		i := strings.IndexByte(name, '/')
+		if i < 0 {
+			break
+		}
+		name = name[i+1:]
+	}
+	return nil, os.ErrNotExist
+}
@@ -0,0 +1,35 @@
+package objfile
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSourceCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "source")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := os.MkdirAll(filepath.Join(dir, "cmd"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "cmd", "main.go"), []byte("package main\n\nfunc main() {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	c := NewSourceCache(dir)
+	for _, name := range []string{filepath.Join(dir, "cmd", "main.go"), "cmd/main.go", "example.com/app/cmd/main.go"} {
+		line, err := c.Line(name, 3)
+		if err != nil || string(line) != "func main() {}" {
+			t.Errorf("Line(%s, 3) = %q, %v; want func main() {}", name, line, err)
+		}
+	}
+	if _, err := c.Line("cmd/main.go", 10); err == nil {
+		t.Error("Line past the end of the file succeeded")
+	}
+	if _, err := c.Line("example.com/app/other.go", 1); err == nil {
+		t.Error("Line of a missing file succeeded")
+	}
+}
@@ -0,0 +1,85 @@
+package proc
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Path returns the path of the file name of the process pid in the proc
+// file system. Opening "exe" opens the binary of the process and "root"
+// is its view of the file system, both work across mount namespaces.
+func Path(pid int, name string) string {
+	return filepath.Join(root, strconv.Itoa(pid), name)
+}
+
+// Cgroup returns the cgroup of the process pid: its path in the unified
+// hierarchy, or else the first path of the other hierarchies that is not
+// the root cgroup.
+func Cgroup(pid int) (string, error) {
+	b, err := ioutil.ReadFile(Path(pid, "cgroup"))
+	if err != nil {
+		return "", err
+	}
+	var cgroup string
+	for _, line := range strings.Split(string(b), "\n") {
+		// hierarchy-ID:controller-list:cgroup-path
+		parts := strings.SplitN(line, ":", 3)
+		if len(parts) != 3 || parts[2] == "/" {
+			continue
+		}
+		if parts[0] == "0" && parts[1] == "" {
+			return parts[2], nil
+		}
+		if cgroup == "" {
+			cgroup = parts[2]
+		}
+	}
+	if cgroup == "" {
+		cgroup = "/"
+	}
+	return cgroup, nil
+}
+
+// containerID matches the IDs of the containers of Docker, containerd,
+// CRI-O and Podman, which appear in the cgroup paths as "/docker/<id>",
+// "docker-<id>.scope", "cri-containerd-<id>.scope", "crio-<id>.scope" or
+// "libpod-<id>.scope".
+var containerID = regexp.MustCompile(`[0-9a-f]{64}`)
+
+// ContainerID returns the ID of the container of a cgroup path,
+// or an empty string if the cgroup is not a container's.
+func ContainerID(cgroup string) string {
+	ids := containerID.FindAllString(cgroup, -1)
+	if len(ids) == 0 {
+		return ""
+	}
+	return ids[len(ids)-1]
+}
+
+// Getenv returns the value of the environment variable key
+// of the process pid, as it was when the process started.
+func Getenv(pid int, key string) (string, error) {
+	b, err := ioutil.ReadFile(Path(pid, "environ"))
+	if err != nil {
+		return "", err
+	}
+	prefix := []byte(key + "=")
+	for _, kv := range bytes.Split(b, []byte{0}) {
+		if bytes.HasPrefix(kv, prefix) {
+			return string(kv[len(prefix):]), nil
+		}
+	}
+	return "", nil
+}
+
+// NetNS returns the network namespace of the process pid, such as
+// "net:[4026531992]". Processes in the same namespace share the loopback
+// interface.
+func NetNS(pid int) (string, error) {
+	return os.Readlink(Path(pid, "ns/net"))
+}
@@ -0,0 +1,73 @@
+package proc
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+)
+
+// A Mapping is a memory mapping of a process.
+type Mapping struct {
+	Start, End uint64
+	Perms      string // such as "r-xp"
+	Offset     uint64 // offset of the mapping in the file
+	Path       string // mapped file, or pseudo-path such as "[heap]", if any
+}
+
+// Maps returns the memory mappings of the process pid, in increasing
+// address order.
+func Maps(pid int) ([]Mapping, error) {
+	b, err := ioutil.ReadFile(Path(pid, "maps"))
+	if err != nil {
+		return nil, err
+	}
+	return parseMaps(string(b))
+}
+
+// parseMaps parses the lines of /proc/<pid>/maps:
+//
+//	address           perms offset  dev   inode      pathname
+//	00400000-00452000 r-xp 00000000 08:02 173521     /usr/bin/dbus-daemon
+func parseMaps(s string) ([]Mapping, error) {
+	var maps []Mapping
+	for _, line := range strings.Split(s, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 5 {
+			continue
+		}
+		var m Mapping
+		addrs := strings.SplitN(fields[0], "-", 2)
+		var err error
+		if len(addrs) != 2 {
+			err = fmt.Errorf("bad address range %q", fields[0])
+		} else if m.Start, err = strconv.ParseUint(addrs[0], 16, 64); err == nil {
+			m.End, err = strconv.ParseUint(addrs[1], 16, 64)
+		}
+		if err == nil {
+			m.Offset, err = strconv.ParseUint(fields[2], 16, 64)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("maps: %v", err)
+		}
+		m.Perms = fields[1]
+		if len(fields) > 5 {
+			// The path may contain spaces.
+			m.Path = strings.Join(fields[5:], " ")
+		}
+		maps = append(maps, m)
+	}
+	return maps, nil
+}
+
+// Mapped returns the mappings of maps of the file at path, whose mappings
+// are named "path (deleted)" once the file is removed or replaced.
+func Mapped(maps []Mapping, path string) []Mapping {
+	var file []Mapping
+	for _, m := range maps {
+		if m.Path == path || m.Path == path+" (deleted)" {
+			file = append(file, m)
+		}
+	}
+	return file
+}
@@ -0,0 +1,200 @@
+// Package proc reads the information of a process from the Linux /proc
+// file system.
+package proc
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ClockTicks is the unit of the CPU and start times of /proc/<pid>/stat.
+const ClockTicks = 100
+
+// root is the mount point of the proc file system.
+var root = "/proc"
+
+// Info is the information of a process.
+type Info struct {
+	PID      int
+	PPID     int
+	NSPID    int // PID in the innermost PID namespace, such as a container's
+	UID      int
+	Start    time.Time // zero if the boot time is unknown
+	RSS      uint64    // resident set size, in bytes
+	CPUTicks uint64    // user and system CPU time, in clock ticks
+}
+
+// Read returns the information of the process pid.
+func Read(pid int) (*Info, error) {
+	dir := filepath.Join(root, strconv.Itoa(pid))
+	b, err := ioutil.ReadFile(filepath.Join(dir, "stat"))
+	if err != nil {
+		return nil, err
+	}
+	info, err := parseStat(b)
+	if err != nil {
+		return nil, fmt.Errorf("%s/stat: %v", dir, err)
+	}
+	if info.UID, info.NSPID, err = parseStatus(filepath.Join(dir, "status")); err != nil {
+		return nil, err
+	}
+	if info.NSPID == 0 {
+		// Kernels before 4.1 do not report namespaced PIDs.
+		info.NSPID = info.PID
+	}
+	if boot, err := bootTime(); err == nil {
+		info.Start = boot.Add(time.Duration(info.startTicks) * time.Second / ClockTicks)
+	}
+	return &info.Info, nil
+}
+
+// CPUTicks returns the user and system CPU time of the process pid,
+// in clock ticks.
+func CPUTicks(pid int) (uint64, error) {
+	dir := filepath.Join(root, strconv.Itoa(pid))
+	b, err := ioutil.ReadFile(filepath.Join(dir, "stat"))
+	if err != nil {
+		return 0, err
+	}
+	info, err := parseStat(b)
+	if err != nil {
+		return 0, fmt.Errorf("%s/stat: %v", dir, err)
+	}
+	return info.CPUTicks, nil
+}
+
+// Thread is a thread of a process.
+type Thread struct {
+	TID   int
+	State byte // such as 'R' for running and 'S' for sleeping
+}
+
+// Threads returns the threads of the process pid, sorted by ID. Threads
+// that exit while they are listed are left out.
+func Threads(pid int) ([]Thread, error) {
+	dir := filepath.Join(root, strconv.Itoa(pid), "task")
+	names, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var threads []Thread
+	for _, fi := range names {
+		tid, err := strconv.Atoi(fi.Name())
+		if err != nil {
+			continue
+		}
+		b, err := ioutil.ReadFile(filepath.Join(dir, fi.Name(), "stat"))
+		if err != nil {
+			continue
+		}
+		j := bytes.LastIndexByte(b, ')')
+		if j < 0 || j+2 >= len(b) {
+			return nil, fmt.Errorf("%s/%d/stat: malformed stat", dir, tid)
+		}
+		threads = append(threads, Thread{TID: tid, State: b[j+2]})
+	}
+	sort.Slice(threads, func(i, j int) bool {
+		return threads[i].TID < threads[j].TID
+	})
+	return threads, nil
+}
+
+type stat struct {
+	Info
+	startTicks uint64 // start time after boot, in clock ticks
+}
+
+// parseStat parses the content of /proc/<pid>/stat.
+func parseStat(b []byte) (*stat, error) {
+	// The command name may contain spaces, fields start after its closing parenthesis.
+	i := bytes.IndexByte(b, ' ')
+	j := bytes.LastIndexByte(b, ')')
+	if i < 0 || j < 0 {
+		return nil, fmt.Errorf("malformed stat")
+	}
+	pid, err := strconv.Atoi(string(b[:i]))
+	if err != nil {
+		return nil, err
+	}
+	fields := strings.Fields(string(b[j+1:]))
+	if len(fields) < 22 {
+		return nil, fmt.Errorf("malformed stat")
+	}
+	// fields[0] is the third field of proc(5).
+	var n [5]uint64
+	for k, f := range []int{1, 11, 12, 19, 21} {
+		if n[k], err = strconv.ParseUint(fields[f], 10, 64); err != nil {
+			return nil, err
+		}
+	}
+	return &stat{
+		Info: Info{
+			PID:      pid,
+			PPID:     int(n[0]),
+			CPUTicks: n[1] + n[2],
+			RSS:      n[4] * uint64(os.Getpagesize()),
+		},
+		startTicks: n[3],
+	}, nil
+}
+
+// parseStatus returns the real user ID of the Uid line of a status file,
+// and the last PID of its NSpid line, if any.
+func parseStatus(path string) (uid, nspid int, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer f.Close()
+	uid = -1
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		fields := strings.Fields(s.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		switch fields[0] {
+		case "Uid:":
+			if uid, err = strconv.Atoi(fields[1]); err != nil {
+				return 0, 0, err
+			}
+		case "NSpid:":
+			if nspid, err = strconv.Atoi(fields[len(fields)-1]); err != nil {
+				return 0, 0, err
+			}
+		}
+	}
+	if err := s.Err(); err != nil {
+		return 0, 0, err
+	}
+	if uid < 0 {
+		return 0, 0, fmt.Errorf("%s: no Uid line", path)
+	}
+	return uid, nspid, nil
+}
+
+// bootTime returns the boot time of the system.
+func bootTime() (time.Time, error) {
+	b, err := ioutil.ReadFile(filepath.Join(root, "stat"))
+	if err != nil {
+		return time.Time{}, err
+	}
+	for _, line := range strings.Split(string(b), "\n") {
+		if strings.HasPrefix(line, "btime ") {
+			sec, err := strconv.ParseInt(strings.TrimSpace(line[len("btime "):]), 10, 64)
+			if err != nil {
+				return time.Time{}, err
+			}
+			return time.Unix(sec, 0), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%s/stat: no btime line", root)
+}
@@ -0,0 +1,104 @@
+package proc
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestRead(t *testing.T) {
+	defer func(r string) { root = r }(root)
+	root = "testdata"
+
+	info, err := Read(42)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Info{
+		PID:      42,
+		PPID:     7,
+		NSPID:    3,
+		UID:      1000,
+		Start:    time.Unix(1600000005, 0),
+		RSS:      10 * uint64(os.Getpagesize()),
+		CPUTicks: 42,
+	}
+	if *info != want {
+		t.Errorf("Read(42) = %+v; want %+v", *info, want)
+	}
+	if _, err := Read(43); err == nil {
+		t.Error("Read of a missing process succeeded")
+	}
+}
+
+func TestContainer(t *testing.T) {
+	defer func(r string) { root = r }(root)
+	root = "testdata"
+
+	cgroup, err := Cgroup(42)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const id = "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	if cgroup != "/docker/"+id {
+		t.Errorf("Cgroup(42) = %q", cgroup)
+	}
+	if home, err := Getenv(42, "HOME"); err != nil || home != "/home/app" {
+		t.Errorf("Getenv(42, HOME) = %q, %v; want /home/app", home, err)
+	}
+	if ns, err := NetNS(42); err != nil || ns != "net:[4026532301]" {
+		t.Errorf("NetNS(42) = %q, %v; want net:[4026532301]", ns, err)
+	}
+
+	for cgroup, want := range map[string]string{
+		"/docker/" + id:                         id,
+		"/system.slice/docker-" + id + ".scope": id,
+		"/kubepods.slice/kubepods-pod1.slice/cri-containerd-" + id + ".scope": id,
+		"/user.slice/user-1000.slice/session-2.scope":                         "",
+		"/": "",
+	} {
+		if got := ContainerID(cgroup); got != want {
+			t.Errorf("ContainerID(%q) = %q; want %q", cgroup, got, want)
+		}
+	}
+}
+
+func TestMaps(t *testing.T) {
+	defer func(r string) { root = r }(root)
+	root = "testdata"
+
+	maps, err := Maps(42)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(maps) != 6 {
+		t.Fatalf("Maps(42) returned %d mappings; want 6", len(maps))
+	}
+	want := Mapping{Start: 0x55d4c6b35000, End: 0x55d4c6ca7000, Perms: "r--p", Offset: 0x135000, Path: "/opt/my app/server (deleted)"}
+	if maps[2] != want {
+		t.Errorf("maps[2] = %+v; want %+v", maps[2], want)
+	}
+	if maps[0].Path != "" || maps[4].Path != "[heap]" {
+		t.Errorf("paths = %q, %q; want \"\", [heap]", maps[0].Path, maps[4].Path)
+	}
+	if exe := Mapped(maps, "/opt/my app/server"); len(exe) != 3 || exe[0].Start != 0x55d4c6a00000 {
+		t.Errorf("Mapped(server) = %+v", exe)
+	}
+	if _, err := parseMaps("zz-10 r--p 0 00:00 0\n"); err == nil {
+		t.Error("parseMaps of a bad address succeeded")
+	}
+}
+
+func TestThreads(t *testing.T) {
+	defer func(r string) { root = r }(root)
+	root = "testdata"
+
+	threads, err := Threads(42)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Thread{{42, 'S'}, {44, 'R'}}
+	if len(threads) != len(want) || threads[0] != want[0] || threads[1] != want[1] {
+		t.Errorf("Threads(42) = %+v; want %+v", threads, want)
+	}
+}
@@ -0,0 +1,76 @@
+package report
+
+import (
+	"sort"
+
+	"github.com/wgliang/opengacm/modules/client/internal/objfile"
+	"github.com/wgliang/opengacm/modules/client/internal/profile"
+)
+
+// AddrValues are the sample values of a profile by link-time address in
+// the profiled binary.
+type AddrValues struct {
+	Total int64 // total of the sample values of the profile
+
+	addrs     []uint64 // sorted
+	flat, cum []int64
+}
+
+// Addresses returns the values of the sample type i of p by address in
+// the profiled binary f, which is the first mapping of p. Flat values are
+// at the addresses of the leaf locations of the samples; cumulative ones
+// are at the addresses of all their locations. As written by runtime/pprof
+// and by sample, the addresses of the callers are already in their calls.
+func Addresses(p *profile.Profile, i int, f *objfile.File) (*AddrValues, error) {
+	load, err := f.LoadAddress()
+	if err != nil {
+		return nil, err
+	}
+	flat := make(map[uint64]int64)
+	cum := make(map[uint64]int64)
+	v := &AddrValues{}
+	for _, s := range p.Sample {
+		if i >= len(s.Value) {
+			continue
+		}
+		value := s.Value[i]
+		v.Total += value
+		seen := make(map[uint64]bool)
+		for j, loc := range s.Location {
+			if loc.Mapping != nil && loc.Mapping != p.Mapping[0] {
+				continue
+			}
+			addr := loc.Address
+			if m := loc.Mapping; m != nil && m.Start != 0 {
+				addr = addr - m.Start + m.Offset + load
+			}
+			if j == 0 {
+				flat[addr] += value
+			}
+			if !seen[addr] {
+				seen[addr] = true
+				cum[addr] += value
+			}
+		}
+	}
+	for addr := range cum {
+		v.addrs = append(v.addrs, addr)
+	}
+	sort.Slice(v.addrs, func(i, j int) bool { return v.addrs[i] < v.addrs[j] })
+	for _, addr := range v.addrs {
+		v.flat = append(v.flat, flat[addr])
+		v.cum = append(v.cum, cum[addr])
+	}
+	return v, nil
+}
+
+// Range returns the sum of the flat and of the cumulative values at the
+// addresses of [start, end).
+func (v *AddrValues) Range(start, end uint64) (flat, cum int64) {
+	i := sort.Search(len(v.addrs), func(i int) bool { return v.addrs[i] >= start })
+	for ; i < len(v.addrs) && v.addrs[i] < end; i++ {
+		flat += v.flat[i]
+		cum += v.cum[i]
+	}
+	return flat, cum
+}
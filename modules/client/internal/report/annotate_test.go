@@ -0,0 +1,68 @@
+package report
+
+import (
+	"os"
+	"testing"
+
+	"github.com/wgliang/opengacm/modules/client/internal/objfile"
+	"github.com/wgliang/opengacm/modules/client/internal/profile"
+)
+
+func TestAddresses(t *testing.T) {
+	f, err := objfile.Open(os.Args[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	load, err := f.LoadAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The binary is mapped at 0x7f0000000000 in the profiled process.
+	m := &profile.Mapping{ID: 1, Start: 0x7f0000000000, Limit: 0x7f0001000000}
+	other := &profile.Mapping{ID: 2, Start: 0x7f1000000000, Limit: 0x7f1001000000}
+	loc := func(addr uint64) *profile.Location {
+		return &profile.Location{Mapping: m, Address: m.Start + addr}
+	}
+	// The callers are at the addresses of their calls, as in the profiles
+	// of runtime/pprof.
+	leaf, leaf2 := loc(0x100), loc(0x250)
+	caller, recursive := loc(0x200), loc(0x300)
+	libc := &profile.Location{Mapping: other, Address: other.Start + 0x100}
+	p := &profile.Profile{
+		SampleType: []*profile.ValueType{{Type: "samples", Unit: "count"}},
+		Mapping:    []*profile.Mapping{m, other},
+		Sample: []*profile.Sample{
+			{Location: []*profile.Location{leaf, caller}, Value: []int64{3}},
+			{Location: []*profile.Location{leaf2, recursive, recursive}, Value: []int64{2}},
+			{Location: []*profile.Location{libc, caller}, Value: []int64{1}},
+		},
+	}
+	v, err := Addresses(p, 0, f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Total != 6 {
+		t.Errorf("Total = %d; want 6", v.Total)
+	}
+	for _, tt := range []struct {
+		start, end uint64
+		flat, cum  int64
+	}{
+		{0x100, 0x101, 3, 3},
+		// The addresses of the callers are kept as they are.
+		{0x1ff, 0x200, 0, 0},
+		{0x200, 0x201, 0, 4},
+		{0x250, 0x251, 2, 2},
+		{0x2ff, 0x300, 0, 0},
+		{0x300, 0x301, 0, 2},
+		{0x100, 0x400, 5, 11},
+		{0x400, 0x500, 0, 0},
+	} {
+		flat, cum := v.Range(load+tt.start, load+tt.end)
+		if flat != tt.flat || cum != tt.cum {
+			t.Errorf("Range(%#x, %#x) = %d, %d; want %d, %d", tt.start, tt.end, flat, cum, tt.flat, tt.cum)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/wgliang/opengacm/modules/client/internal/profile"
+)
+
+// FuncDelta is the change of the flat and cumulative values
+// of a function between two profiles.
+type FuncDelta struct {
+	Name     string
+	BaseFlat int64
+	NewFlat  int64
+	BaseCum  int64
+	NewCum   int64
+}
+
+// Flat returns the change of the flat value.
+func (d *FuncDelta) Flat() int64 { return d.NewFlat - d.BaseFlat }
+
+// Cum returns the change of the cumulative value.
+func (d *FuncDelta) Cum() int64 { return d.NewCum - d.BaseCum }
+
+// Diff is the per-function difference between two profiles.
+type Diff struct {
+	SampleType string
+	Unit       string
+	BaseTotal  int64
+	NewTotal   int64
+	Funcs      []*FuncDelta
+}
+
+// Compare computes the per-function difference between base and cur for
+// the sample type named sampleType (see SampleIndex). If normalize is set,
+// base is scaled so that both profiles have the same total, which makes
+// CPU profiles of different durations comparable.
+func Compare(base, cur *profile.Profile, sampleType string, normalize bool) (*Diff, error) {
+	ni, err := SampleIndex(cur, sampleType)
+	if err != nil {
+		return nil, err
+	}
+	st := cur.SampleType[ni]
+	bi, err := SampleIndex(base, st.Type)
+	if err != nil {
+		return nil, fmt.Errorf("base profile: %v", err)
+	}
+	if u := base.SampleType[bi].Unit; u != st.Unit {
+		return nil, fmt.Errorf("incompatible units %q and %q", u, st.Unit)
+	}
+
+	d := &Diff{SampleType: st.Type, Unit: st.Unit}
+	funcs := make(map[string]*FuncDelta)
+	get := func(name string) *FuncDelta {
+		f, ok := funcs[name]
+		if !ok {
+			f = &FuncDelta{Name: name}
+			funcs[name] = f
+		}
+		return f
+	}
+	d.BaseTotal = accumulate(base, bi, func(name string, flat, cum int64) {
+		f := get(name)
+		f.BaseFlat += flat
+		f.BaseCum += cum
+	})
+	d.NewTotal = accumulate(cur, ni, func(name string, flat, cum int64) {
+		f := get(name)
+		f.NewFlat += flat
+		f.NewCum += cum
+	})
+	if normalize && d.BaseTotal != 0 && d.NewTotal != 0 {
+		r := float64(d.NewTotal) / float64(d.BaseTotal)
+		for _, f := range funcs {
+			f.BaseFlat = int64(float64(f.BaseFlat) * r)
+			f.BaseCum = int64(float64(f.BaseCum) * r)
+		}
+		d.BaseTotal = d.NewTotal
+	}
+	for _, f := range funcs {
+		d.Funcs = append(d.Funcs, f)
+	}
+	d.Sort(false)
+	return d, nil
+}
+
+// accumulate calls fn with the flat and cumulative contribution of every
+// function of every sample of p, and returns the total of value index i.
+func accumulate(p *profile.Profile, i int, fn func(name string, flat, cum int64)) int64 {
+	var total int64
+	for _, s := range p.Sample {
+		v := s.Value[i]
+		total += v
+		seen := make(map[string]bool)
+		for j, loc := range s.Location {
+			for k, name := range locationNames(loc) {
+				var flat, cum int64
+				if j == 0 && k == 0 {
+					flat = v
+				}
+				if !seen[name] {
+					seen[name] = true
+					cum = v
+				}
+				if flat != 0 || cum != 0 {
+					fn(name, flat, cum)
+				}
+			}
+		}
+	}
+	return total
+}
+
+// Sort orders the functions by decreasing flat change,
+// or cumulative change if cum is set.
+func (d *Diff) Sort(cum bool) {
+	sort.Slice(d.Funcs, func(i, j int) bool {
+		a, b := d.Funcs[i], d.Funcs[j]
+		da, db := a.Flat(), b.Flat()
+		if cum {
+			da, db = a.Cum(), b.Cum()
+		}
+		if da != db {
+			return da > db
+		}
+		return a.Name < b.Name
+	})
+}
+
+// Print writes the top functions of the diff to w, in their current order.
+// Functions that didn't change are skipped and don't count in top.
+func (d *Diff) Print(w io.Writer, top int) {
+	fmt.Fprintf(w, "Type: %s\n", d.SampleType)
+	fmt.Fprintf(w, "Total: base %s, new %s, delta %s%s\n\n",
+		FormatValue(d.BaseTotal, d.Unit), FormatValue(d.NewTotal, d.Unit),
+		signed(d.NewTotal-d.BaseTotal, d.Unit), percent(d.NewTotal-d.BaseTotal, d.BaseTotal))
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "flat delta\tflat base\tflat new\tcum delta\tcum new\t\t")
+	printed := 0
+	for _, f := range d.Funcs {
+		if top > 0 && printed >= top {
+			break
+		}
+		if f.Flat() == 0 && f.Cum() == 0 {
+			continue
+		}
+		printed++
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t\t%s\n",
+			signed(f.Flat(), d.Unit), FormatValue(f.BaseFlat, d.Unit), FormatValue(f.NewFlat, d.Unit),
+			signed(f.Cum(), d.Unit), FormatValue(f.NewCum, d.Unit), f.Name)
+	}
+	tw.Flush()
+}
+
+func signed(v int64, unit string) string {
+	if v > 0 {
+		return "+" + FormatValue(v, unit)
+	}
+	return FormatValue(v, unit)
+}
+
+func percent(delta, base int64) string {
+	if base == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" (%+.1f%%)", 100*float64(delta)/float64(base))
+}
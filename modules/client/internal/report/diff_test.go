@@ -0,0 +1,109 @@
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/wgliang/opengacm/modules/client/internal/profile"
+)
+
+// testProfile builds a profile whose samples are call stacks of function
+// names, leaf first, with a single value each.
+func testProfile(stacks map[string]int64) *profile.Profile {
+	p := &profile.Profile{
+		SampleType: []*profile.ValueType{{Type: "samples", Unit: "count"}, {Type: "cpu", Unit: "nanoseconds"}},
+	}
+	funcs := make(map[string]*profile.Location)
+	for stack, v := range stacks {
+		s := &profile.Sample{Value: []int64{1, v}}
+		for _, name := range strings.Split(stack, ";") {
+			loc, ok := funcs[name]
+			if !ok {
+				fn := &profile.Function{ID: uint64(len(funcs) + 1), Name: name}
+				loc = &profile.Location{ID: fn.ID, Line: []profile.Line{{Function: fn}}}
+				funcs[name] = loc
+				p.Function = append(p.Function, fn)
+				p.Location = append(p.Location, loc)
+			}
+			s.Location = append(s.Location, loc)
+		}
+		p.Sample = append(p.Sample, s)
+	}
+	return p
+}
+
+func TestCompare(t *testing.T) {
+	base := testProfile(map[string]int64{
+		"a;main": 100,
+		"b;main": 50,
+	})
+	cur := testProfile(map[string]int64{
+		"a;main":   100,
+		"b;main":   20,
+		"c;b;main": 80,
+	})
+	d, err := Compare(base, cur, "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.SampleType != "cpu" || d.BaseTotal != 150 || d.NewTotal != 200 {
+		t.Fatalf("got %s %d %d; want cpu 150 200", d.SampleType, d.BaseTotal, d.NewTotal)
+	}
+	want := []struct {
+		name      string
+		flat, cum int64
+	}{
+		{"c", 80, 80},
+		{"a", 0, 0},
+		{"main", 0, 50},
+		{"b", -30, 50},
+	}
+	if len(d.Funcs) != len(want) {
+		t.Fatalf("got %d functions; want %d", len(d.Funcs), len(want))
+	}
+	for i, w := range want {
+		f := d.Funcs[i]
+		if f.Name != w.name || f.Flat() != w.flat || f.Cum() != w.cum {
+			t.Errorf("Funcs[%d] = %s %+d %+d; want %s %+d %+d", i, f.Name, f.Flat(), f.Cum(), w.name, w.flat, w.cum)
+		}
+	}
+
+	var buf bytes.Buffer
+	d.Print(&buf, 1)
+	if !strings.Contains(buf.String(), "Total: base 150ns, new 200ns, delta +50ns (+33.3%)") {
+		t.Errorf("unexpected total in:\n%s", buf.String())
+	}
+	if strings.Contains(buf.String(), "main") {
+		t.Errorf("top 1 printed more than one function:\n%s", buf.String())
+	}
+
+	if _, err := Compare(base, cur, "alloc_space", false); err == nil {
+		t.Error("Compare with an unknown sample type succeeded")
+	}
+}
+
+func TestPrintTop(t *testing.T) {
+	d := &Diff{SampleType: "cpu", Unit: "nanoseconds", Funcs: []*FuncDelta{
+		{Name: "unchanged", BaseFlat: 10, NewFlat: 10, BaseCum: 10, NewCum: 10},
+		{Name: "grown", BaseFlat: 10, NewFlat: 20, BaseCum: 10, NewCum: 20},
+		{Name: "shrunk", BaseFlat: 20, NewFlat: 10, BaseCum: 20, NewCum: 10},
+	}}
+	var buf bytes.Buffer
+	d.Print(&buf, 2)
+	if out := buf.String(); strings.Contains(out, "unchanged") || !strings.Contains(out, "grown") || !strings.Contains(out, "shrunk") {
+		t.Errorf("top 2 without the unchanged function:\n%s", out)
+	}
+}
+
+func TestCompareNormalize(t *testing.T) {
+	base := testProfile(map[string]int64{"a": 100})
+	cur := testProfile(map[string]int64{"a": 200})
+	d, err := Compare(base, cur, "cpu", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.BaseTotal != 200 || d.Funcs[0].Flat() != 0 {
+		t.Errorf("got base total %d and delta %d; want 200 and 0", d.BaseTotal, d.Funcs[0].Flat())
+	}
+}
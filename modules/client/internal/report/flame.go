@@ -0,0 +1,227 @@
+package report
+
+import (
+	"bufio"
+	"fmt"
+	"hash/fnv"
+	"html"
+	"io"
+	"sort"
+
+	"github.com/wgliang/opengacm/modules/client/internal/profile"
+)
+
+// Node is a frame of a flame graph. The value of a node
+// is the sum of the values of the samples going through it.
+type Node struct {
+	Name     string
+	Value    int64
+	Children []*Node
+}
+
+func (n *Node) child(name string) *Node {
+	for _, c := range n.Children {
+		if c.Name == name {
+			return c
+		}
+	}
+	c := &Node{Name: name}
+	n.Children = append(n.Children, c)
+	return c
+}
+
+func (n *Node) sort() {
+	sort.Slice(n.Children, func(i, j int) bool { return n.Children[i].Name < n.Children[j].Name })
+	for _, c := range n.Children {
+		c.sort()
+	}
+}
+
+// depth returns the number of levels of the tree rooted at n.
+func (n *Node) depth() int {
+	d := 0
+	for _, c := range n.Children {
+		if cd := c.depth(); cd > d {
+			d = cd
+		}
+	}
+	return d + 1
+}
+
+// FlameTree merges the stacks of the samples of p into a tree, using
+// the sample value at index i. Inlined functions get their own frame.
+func FlameTree(p *profile.Profile, i int) *Node {
+	root := &Node{Name: "root"}
+	for _, s := range p.Sample {
+		v := s.Value[i]
+		if v == 0 {
+			continue
+		}
+		n := root
+		n.Value += v
+		for j := len(s.Location) - 1; j >= 0; j-- {
+			names := locationNames(s.Location[j])
+			for k := len(names) - 1; k >= 0; k-- {
+				n = n.child(names[k])
+				n.Value += v
+			}
+		}
+	}
+	root.sort()
+	return root
+}
+
+const (
+	flameWidth      = 1200
+	flameFrame      = 16
+	flameMargin     = 10
+	flameHeader     = 40
+	flameFontSize   = 12
+	flameCharWidth  = 7
+	flameMinWidthPx = 0.1
+)
+
+// WriteFlameSVG writes root as a standalone SVG flame graph. The graph
+// needs no network access: clicking a frame zooms into it, clicking the
+// title resets the zoom, and hovering shows the frame values.
+func WriteFlameSVG(w io.Writer, root *Node, title, unit string) error {
+	bw := bufio.NewWriter(w)
+	writeFlameSVG(bw, root, title, unit)
+	return bw.Flush()
+}
+
+// WriteFlameHTML writes root as a standalone HTML page embedding
+// the SVG flame graph and a search box highlighting matching frames.
+func WriteFlameHTML(w io.Writer, root *Node, title, unit string) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>%s</title>
+<style>
+body { font-family: Verdana, sans-serif; margin: 0; padding: 8px; }
+#search { margin: 4px 0 8px 0; width: 300px; }
+</style>
+</head>
+<body>
+<input id="search" placeholder="Search functions (regexp)" oninput="flameSearch(this.value)">
+`, html.EscapeString(title))
+	writeFlameSVG(bw, root, title, unit)
+	fmt.Fprint(bw, "\n</body>\n</html>\n")
+	return bw.Flush()
+}
+
+func writeFlameSVG(w io.Writer, root *Node, title, unit string) {
+	height := flameHeader + root.depth()*flameFrame + flameMargin
+	fmt.Fprintf(w, `<svg xmlns="http://www.w3.org/2000/svg" version="1.1" width="%d" height="%d" viewBox="0 0 %d %d" font-family="Verdana, sans-serif" font-size="%d">
+<style>
+.f:hover rect { stroke: #000; stroke-width: 0.5; cursor: pointer; }
+.f text { pointer-events: none; }
+.hidden { display: none; }
+.dim rect { opacity: 0.5; }
+.match rect { fill: #e040e0 !important; }
+</style>
+<rect x="0" y="0" width="%d" height="%d" fill="#f8f8f8"/>
+<text id="title" x="%d" y="24" text-anchor="middle" font-size="17" style="cursor: pointer" onclick="flameZoom(null)">%s</text>
+<text id="details" x="%d" y="%d"> </text>
+`, flameWidth, height, flameWidth, height, flameFontSize,
+		flameWidth, height, flameWidth/2, html.EscapeString(title), flameMargin, height-2)
+
+	total := root.Value
+	if total == 0 {
+		total = 1
+	}
+	inner := float64(flameWidth - 2*flameMargin)
+	var walk func(n *Node, x int64, depth int)
+	walk = func(n *Node, x int64, depth int) {
+		wpx := float64(n.Value) / float64(total) * inner
+		if wpx < flameMinWidthPx {
+			return
+		}
+		xpx := flameMargin + float64(x)/float64(total)*inner
+		y := height - flameMargin - (depth+1)*flameFrame
+		info := fmt.Sprintf("%s (%s, %.2f%%)", n.Name, FormatValue(n.Value, unit), 100*float64(n.Value)/float64(total))
+		fmt.Fprintf(w, `<g class="f" data-x="%d" data-v="%d" data-d="%d" onclick="flameZoom(this)" onmouseover="flameInfo(this)" onmouseout="flameInfo(null)">`+
+			`<title>%s</title><rect x="%.2f" y="%d" width="%.2f" height="%d" fill="%s" rx="2"/>`+
+			`<text x="%.2f" y="%d">%s</text></g>`+"\n",
+			x, n.Value, depth, html.EscapeString(info), xpx, y, wpx, flameFrame-1, frameColor(n.Name),
+			xpx+3, y+flameFrame-4, html.EscapeString(fitText(n.Name, wpx)))
+		for _, c := range n.Children {
+			walk(c, x, depth+1)
+			x += c.Value
+		}
+	}
+	walk(root, 0, 0)
+
+	fmt.Fprintf(w, `<script type="text/ecmascript"><![CDATA[
+(function() {
+var total = %d, width = %d, margin = %d, charWidth = %d;
+var frames = Array.prototype.slice.call(document.querySelectorAll(".f"));
+var details = document.getElementById("details");
+function fit(name, w) {
+	var n = Math.floor((w - 6) / charWidth);
+	if (n < 3) return "";
+	if (name.length <= n) return name;
+	return name.substring(0, n - 2) + "..";
+}
+function layout(x0, v0, d0) {
+	var inner = width - 2 * margin;
+	frames.forEach(function(g) {
+		var x = +g.getAttribute("data-x"), v = +g.getAttribute("data-v"), d = +g.getAttribute("data-d");
+		var inside = x >= x0 && x + v <= x0 + v0;
+		var ancestor = d < d0 && x <= x0 && x + v >= x0 + v0;
+		g.classList.toggle("hidden", !(ancestor || (inside && d >= d0)));
+		g.classList.toggle("dim", ancestor);
+		var rect = g.querySelector("rect"), text = g.querySelector("text");
+		var px = ancestor ? margin : margin + (x - x0) / v0 * inner;
+		var pw = ancestor ? inner : v / v0 * inner;
+		rect.setAttribute("x", px.toFixed(2));
+		rect.setAttribute("width", pw.toFixed(2));
+		text.setAttribute("x", (px + 3).toFixed(2));
+		text.textContent = fit(g.querySelector("title").textContent.replace(/ \([^()]*\)$/, ""), pw);
+	});
+}
+window.flameZoom = function(g) {
+	if (!g) { layout(0, total, 0); return; }
+	layout(+g.getAttribute("data-x"), +g.getAttribute("data-v"), +g.getAttribute("data-d"));
+};
+window.flameInfo = function(g) {
+	details.textContent = g ? g.querySelector("title").textContent : " ";
+};
+window.flameSearch = function(term) {
+	var re = null;
+	try { re = term ? new RegExp(term) : null; } catch (e) { return; }
+	frames.forEach(function(g) {
+		var name = g.querySelector("title").textContent.replace(/ \([^()]*\)$/, "");
+		g.classList.toggle("match", !!re && re.test(name));
+	});
+};
+})();
+]]></script>
+</svg>`, total, flameWidth, flameMargin, flameCharWidth)
+}
+
+// fitText truncates name to the number of characters fitting in w pixels.
+func fitText(name string, w float64) string {
+	n := int((w - 6) / flameCharWidth)
+	if n < 3 {
+		return ""
+	}
+	r := []rune(name)
+	if len(r) <= n {
+		return name
+	}
+	return string(r[:n-2]) + ".."
+}
+
+// frameColor returns a warm color that is stable for a function name.
+func frameColor(name string) string {
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	v := h.Sum32()
+	r := 205 + v%50
+	g := (v >> 8) % 230
+	b := (v >> 16) % 55
+	return fmt.Sprintf("rgb(%d,%d,%d)", r, g, b)
+}
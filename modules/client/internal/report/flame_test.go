@@ -0,0 +1,79 @@
+package report
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestFlameTree(t *testing.T) {
+	p := testProfile(map[string]int64{
+		"a;main":   100,
+		"b;main":   50,
+		"c;b;main": 25,
+		"gc":       10,
+	})
+	root := FlameTree(p, 1)
+	if root.Value != 185 || len(root.Children) != 2 {
+		t.Fatalf("got root %d with %d children; want 185 with 2", root.Value, len(root.Children))
+	}
+	main := root.Children[1]
+	if main.Name != "main" || main.Value != 175 {
+		t.Fatalf("got %s %d; want main 175", main.Name, main.Value)
+	}
+	b := main.Children[1]
+	if b.Name != "b" || b.Value != 75 || len(b.Children) != 1 || b.Children[0].Value != 25 {
+		t.Errorf("got %s %d %v; want b 75 with child c 25", b.Name, b.Value, b.Children)
+	}
+	if d := root.depth(); d != 4 {
+		t.Errorf("got depth %d; want 4", d)
+	}
+}
+
+func TestWriteFlameSVG(t *testing.T) {
+	p := testProfile(map[string]int64{
+		"a<T>;main": 100,
+		"b;main":    50,
+	})
+	var buf bytes.Buffer
+	if err := WriteFlameSVG(&buf, FlameTree(p, 1), "CPU & more", "nanoseconds"); err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(buf.String(), `<g class="f"`); n != 4 {
+		t.Errorf("got %d frames; want 4", n)
+	}
+	if !strings.Contains(buf.String(), "<title>a&lt;T&gt; (100ns, 66.67%)</title>") {
+		t.Errorf("frame title not found or not escaped:\n%s", buf.String())
+	}
+	// The output must be well-formed XML to be usable as a standalone SVG.
+	d := xml.NewDecoder(&buf)
+	for {
+		if _, err := d.Token(); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("invalid SVG: %v", err)
+		}
+	}
+}
+
+func TestFitText(t *testing.T) {
+	w := float64(6 + 5*flameCharWidth)
+	for _, tt := range []struct{ name, want string }{
+		{"main", "main"},
+		{"main.run", "mai.."},
+		{"main.日本語", "mai.."},
+		{"日本語日本語", "日本語.."},
+		{"ab", "ab"},
+	} {
+		got := fitText(tt.name, w)
+		if got != tt.want || !utf8.ValidString(got) {
+			t.Errorf("fitText(%q) = %q; want %q", tt.name, got, tt.want)
+		}
+	}
+	if got := fitText("main", 6+2*flameCharWidth); got != "" {
+		t.Errorf("fitText in 2 characters = %q; want none", got)
+	}
+}
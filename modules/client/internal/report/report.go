@@ -0,0 +1,75 @@
+// Package report summarizes pprof profiles without the help of
+// "go tool pprof".
+package report
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/wgliang/opengacm/modules/client/internal/profile"
+)
+
+// SampleIndex returns the index of the sample value named name in p.
+// An empty name selects the default sample type of the profile,
+// or its last sample type if it has no default.
+func SampleIndex(p *profile.Profile, name string) (int, error) {
+	if len(p.SampleType) == 0 {
+		return 0, fmt.Errorf("profile has no sample types")
+	}
+	if name == "" {
+		name = p.DefaultSampleType
+	}
+	if name == "" {
+		return len(p.SampleType) - 1, nil
+	}
+	for i, st := range p.SampleType {
+		if st.Type == name {
+			return i, nil
+		}
+	}
+	var types []string
+	for _, st := range p.SampleType {
+		types = append(types, st.Type)
+	}
+	return 0, fmt.Errorf("sample type %q not found, profile has %v", name, types)
+}
+
+// locationNames returns the function names of a location, innermost
+// inlined function first. Unsymbolized locations are named by address.
+func locationNames(loc *profile.Location) []string {
+	if len(loc.Line) == 0 {
+		return []string{fmt.Sprintf("%#x", loc.Address)}
+	}
+	names := make([]string, 0, len(loc.Line))
+	for _, l := range loc.Line {
+		if l.Function == nil {
+			names = append(names, fmt.Sprintf("%#x", loc.Address))
+			continue
+		}
+		names = append(names, l.Function.Name)
+	}
+	return names
+}
+
+// FormatValue formats a sample value of the given unit for humans.
+func FormatValue(v int64, unit string) string {
+	switch unit {
+	case "nanoseconds":
+		return time.Duration(v).String()
+	case "bytes":
+		abs := v
+		if abs < 0 {
+			abs = -abs
+		}
+		switch {
+		case abs >= 1<<30:
+			return fmt.Sprintf("%.2fGB", float64(v)/(1<<30))
+		case abs >= 1<<20:
+			return fmt.Sprintf("%.2fMB", float64(v)/(1<<20))
+		case abs >= 1<<10:
+			return fmt.Sprintf("%.2fKB", float64(v)/(1<<10))
+		}
+		return fmt.Sprintf("%dB", v)
+	}
+	return fmt.Sprintf("%d", v)
+}
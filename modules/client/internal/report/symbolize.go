@@ -0,0 +1,67 @@
+package report
+
+import (
+	"github.com/wgliang/opengacm/modules/client/internal/objfile"
+	"github.com/wgliang/opengacm/modules/client/internal/profile"
+)
+
+// Symbolize fills in the function, file and line of the locations of p
+// that have none, using the line table of the profiled binary f.
+// Locations of other mappings, such as shared libraries, and locations
+// that the binary cannot resolve are left untouched.
+func Symbolize(p *profile.Profile, f *objfile.File) error {
+	pcln, err := f.PCLineTable()
+	if err != nil {
+		return err
+	}
+	load, err := f.LoadAddress()
+	if err != nil {
+		return err
+	}
+	funcs := make(map[string]*profile.Function)
+	var nextID uint64
+	for _, fn := range p.Function {
+		funcs[fn.Name] = fn
+		if fn.ID > nextID {
+			nextID = fn.ID
+		}
+	}
+	for _, loc := range p.Location {
+		if len(loc.Line) > 0 {
+			continue
+		}
+		if loc.Mapping != nil && loc.Mapping != p.Mapping[0] {
+			// Only the first mapping is the profiled binary.
+			continue
+		}
+		pc := loc.Address
+		if m := loc.Mapping; m != nil && m.Start != 0 {
+			// Translate the runtime address into a link-time one,
+			// which differs for position independent executables.
+			pc = pc - m.Start + m.Offset + load
+		}
+		file, line, fn := pcln.PCToLine(pc)
+		if fn == nil {
+			continue
+		}
+		pf, ok := funcs[fn.Name]
+		if !ok {
+			nextID++
+			pf = &profile.Function{
+				ID:         nextID,
+				Name:       fn.Name,
+				SystemName: fn.Name,
+				Filename:   file,
+			}
+			funcs[fn.Name] = pf
+			p.Function = append(p.Function, pf)
+		}
+		loc.Line = []profile.Line{{Function: pf, Line: int64(line)}}
+		if m := loc.Mapping; m != nil {
+			m.HasFunctions = true
+			m.HasFilenames = true
+			m.HasLineNumbers = true
+		}
+	}
+	return nil
+}
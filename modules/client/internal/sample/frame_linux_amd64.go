@@ -0,0 +1,8 @@
+package sample
+
+import "syscall"
+
+// regs returns the registers of r the stack is unwound from.
+func regs(r *syscall.PtraceRegs) Regs {
+	return Regs{PC: r.Rip, SP: r.Rsp, FP: r.Rbp}
+}
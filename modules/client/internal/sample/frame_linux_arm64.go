@@ -0,0 +1,9 @@
+package sample
+
+import "syscall"
+
+// regs returns the registers of r the stack is unwound from. The frame
+// pointer is R29 and the link register R30.
+func regs(r *syscall.PtraceRegs) Regs {
+	return Regs{PC: r.Pc, SP: r.Sp, FP: r.Regs[29], LR: r.Regs[30]}
+}
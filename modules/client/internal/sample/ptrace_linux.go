@@ -0,0 +1,128 @@
+//go:build linux && (amd64 || arm64)
+// +build linux
+// +build amd64 arm64
+
+package sample
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"syscall"
+	"time"
+	"unsafe"
+
+	"github.com/wgliang/opengacm/modules/client/internal/proc"
+)
+
+// ptrace requests and constants that package syscall lacks.
+const (
+	ptraceGetRegSet = 0x4204
+	ptraceSeize     = 0x4206
+	ptraceInterrupt = 0x4207
+	ptraceEventStop = 0x80 // event of the stops caused by ptraceInterrupt
+	ntPRStatus      = 1    // register set of the general purpose registers
+)
+
+// Sample stops the running threads of the process pid hz times a second
+// for d, and returns their stacks unwound by u. Threads that sleep are not
+// sampled, so that the stacks account for the CPU time of the process.
+func Sample(pid int, d time.Duration, hz int, u *Unwinder) ([]Stack, error) {
+	mem, err := os.Open(proc.Path(pid, "mem"))
+	if err != nil {
+		return nil, fmt.Errorf("reading the memory of process %d requires ptrace access: %v", pid, err)
+	}
+	defer mem.Close()
+
+	// The threads are traced by the thread that attached to them.
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	var stacks []Stack
+	tick := time.NewTicker(time.Second / time.Duration(hz))
+	defer tick.Stop()
+	end := time.Now().Add(d)
+	for now := time.Now(); now.Before(end); now = <-tick.C {
+		threads, err := proc.Threads(pid)
+		if err != nil {
+			if len(stacks) > 0 && os.IsNotExist(err) {
+				// The process exited, keep what was sampled.
+				return stacks, nil
+			}
+			return nil, err
+		}
+		for _, t := range threads {
+			if t.State != 'R' {
+				continue
+			}
+			stack, err := sampleThread(mem, t.TID, u)
+			if err == syscall.ESRCH {
+				// The thread exited.
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("thread %d of process %d: %v", t.TID, pid, err)
+			}
+			stacks = append(stacks, stack)
+		}
+	}
+	return stacks, nil
+}
+
+// sampleThread stops the thread tid and returns its stack.
+func sampleThread(mem io.ReaderAt, tid int, u *Unwinder) (Stack, error) {
+	if err := ptrace(ptraceSeize, tid, 0, 0); err != nil {
+		return nil, err
+	}
+	defer syscall.PtraceDetach(tid)
+	if err := ptrace(ptraceInterrupt, tid, 0, 0); err != nil {
+		return nil, err
+	}
+	if err := waitStop(tid); err != nil {
+		return nil, err
+	}
+	var r syscall.PtraceRegs
+	iov := syscall.Iovec{Base: (*byte)(unsafe.Pointer(&r))}
+	iov.SetLen(int(unsafe.Sizeof(r)))
+	if err := ptrace(ptraceGetRegSet, tid, ntPRStatus, uintptr(unsafe.Pointer(&iov))); err != nil {
+		return nil, err
+	}
+	return u.Unwind(mem, regs(&r)), nil
+}
+
+// waitStop waits for the thread tid to stop after PTRACE_INTERRUPT.
+// Signals it receives meanwhile are delivered.
+func waitStop(tid int) error {
+	for {
+		var ws syscall.WaitStatus
+		_, err := syscall.Wait4(tid, &ws, syscall.WALL, nil)
+		switch {
+		case err == syscall.EINTR:
+			continue
+		case err == syscall.ECHILD:
+			return syscall.ESRCH
+		case err != nil:
+			return err
+		case ws.Exited() || ws.Signaled():
+			return syscall.ESRCH
+		case !ws.Stopped():
+			continue
+		case uint32(ws)>>16 == ptraceEventStop:
+			return nil
+		}
+		// A signal-delivery-stop: deliver the signal and wait for the
+		// pending interrupt.
+		if err := ptrace(syscall.PTRACE_CONT, tid, 0, uintptr(ws.StopSignal())); err != nil {
+			return err
+		}
+	}
+}
+
+func ptrace(request, tid int, addr, data uintptr) error {
+	_, _, errno := syscall.Syscall6(syscall.SYS_PTRACE, uintptr(request), uintptr(tid), addr, data, 0, 0)
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
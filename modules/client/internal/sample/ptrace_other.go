@@ -0,0 +1,16 @@
+//go:build !linux || (!amd64 && !arm64)
+// +build !linux !amd64,!arm64
+
+package sample
+
+import (
+	"fmt"
+	"runtime"
+	"time"
+)
+
+// Sample stops the running threads of the process pid hz times a second
+// for d, and returns their stacks unwound by u.
+func Sample(pid int, d time.Duration, hz int, u *Unwinder) ([]Stack, error) {
+	return nil, fmt.Errorf("sampling is not supported on %s/%s", runtime.GOOS, runtime.GOARCH)
+}
@@ -0,0 +1,198 @@
+// Package sample profiles the CPU usage of Go processes that do not embed
+// the agent: it periodically stops their running threads with ptrace and
+// unwinds their stacks along the frame pointers.
+package sample
+
+import (
+	"encoding/binary"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wgliang/opengacm/modules/client/internal/proc"
+	"github.com/wgliang/opengacm/modules/client/internal/profile"
+	"github.com/wgliang/opengacm/modules/client/internal/unwind"
+)
+
+// Stack is a sampled call stack. Its first PC is the one the thread was
+// stopped at, the others are return addresses.
+type Stack []uint64
+
+// Regs are the registers of a thread its stack is unwound from. LR is the
+// link register of arm64.
+type Regs struct {
+	PC, SP, FP, LR uint64
+}
+
+// FrameTable tells the entry of the function at a PC and the size of its
+// frame at the PC, such as objfile.FrameTable does.
+type FrameTable interface {
+	Frame(pc uint64) (entry uint64, size int64, ok bool)
+}
+
+// Unwinder unwinds the stacks of the threads of a Go process.
+type Unwinder struct {
+	Frames FrameTable // of the binary of the process, or nil
+	Offset uint64     // difference between runtime and link-time addresses of the binary
+	Arch   string     // GOARCH of the process
+}
+
+// Unwind returns the stack of the thread whose registers are r. The
+// innermost function may be stopped in its prologue or its epilogue, with
+// the frame pointer of its caller in FP, or be a leaf without frame: its
+// return address is found with the size of its frame at the PC, as given
+// by the frame table. The frames of the callers are walked along the
+// frame pointers, upward only as the stacks of threads stopped by ptrace
+// are not switching. Without frame table, or out of the functions of the
+// binary, the whole stack is walked along the frame pointers.
+func (u *Unwinder) Unwind(mem io.ReaderAt, r Regs) Stack {
+	ret, fp, ok := u.caller(mem, r)
+	if !ok {
+		return unwind.FramePointers(mem, r.PC, r.FP, false)
+	}
+	if ret == 0 {
+		return Stack{r.PC}
+	}
+	return append(Stack{r.PC}, unwind.FramePointers(mem, ret, fp, false)...)
+}
+
+// caller returns the return address of the innermost function of the
+// thread whose registers are r, and the frame pointer of its caller.
+func (u *Unwinder) caller(mem io.ReaderAt, r Regs) (ret, fp uint64, ok bool) {
+	if u.Frames == nil || r.PC < u.Offset {
+		return 0, 0, false
+	}
+	entry, size, ok := u.Frames.Frame(r.PC - u.Offset)
+	if !ok || size < 0 {
+		return 0, 0, false
+	}
+	if r.PC-u.Offset == entry {
+		size = 0
+	}
+	var err error
+	fp = r.FP
+	switch u.Arch {
+	case "amd64":
+		// The return address is above the frame. The prologue pushes
+		// the frame pointer of the caller below it, then points FP to
+		// it.
+		if ret, err = readWord(mem, r.SP+uint64(size)); err != nil {
+			return 0, 0, false
+		}
+		if size > 0 && r.FP == r.SP+uint64(size)-8 {
+			fp, err = readWord(mem, r.FP)
+		}
+	case "arm64":
+		// The return address is in LR until the prologue allocates the
+		// frame and saves LR at its bottom, below which it saves the
+		// frame pointer of the caller and points FP to it.
+		ret = r.LR
+		if size > 0 {
+			if ret, err = readWord(mem, r.SP); err != nil {
+				return 0, 0, false
+			}
+			if r.FP == r.SP-8 {
+				fp, err = readWord(mem, r.FP)
+			}
+		}
+	default:
+		return 0, 0, false
+	}
+	if err != nil {
+		fp = 0
+	}
+	return ret, fp, true
+}
+
+func readWord(mem io.ReaderAt, addr uint64) (uint64, error) {
+	var b [8]byte
+	if _, err := mem.ReadAt(b[:], int64(addr)); err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint64(b[:]), nil
+}
+
+// Build returns the CPU profile of stacks sampled every period over d out
+// of a process whose memory mappings are maps. The first mapping of the
+// profile is the one of the binary exe, the others are the executable
+// mappings of shared libraries.
+func Build(stacks []Stack, period, d time.Duration, maps []proc.Mapping, exe string) *profile.Profile {
+	p := &profile.Profile{
+		SampleType: []*profile.ValueType{
+			{Type: "samples", Unit: "count"},
+			{Type: "cpu", Unit: "nanoseconds"},
+		},
+		PeriodType:    &profile.ValueType{Type: "cpu", Unit: "nanoseconds"},
+		Period:        period.Nanoseconds(),
+		TimeNanos:     time.Now().Add(-d).UnixNano(),
+		DurationNanos: d.Nanoseconds(),
+	}
+	p.Mapping = mappings(maps, exe)
+	locs := make(map[uint64]*profile.Location)
+	samples := make(map[string]*profile.Sample)
+	var key strings.Builder
+	for _, stack := range stacks {
+		key.Reset()
+		for _, pc := range stack {
+			key.WriteString(strconv.FormatUint(pc, 16))
+			key.WriteByte(' ')
+		}
+		if s, ok := samples[key.String()]; ok {
+			s.Value[0]++
+			s.Value[1] += p.Period
+			continue
+		}
+		s := &profile.Sample{Value: []int64{1, p.Period}}
+		for i, pc := range stack {
+			if i > 0 {
+				// Return addresses follow the calls, whose lines
+				// are the ones of the previous instruction.
+				pc--
+			}
+			loc, ok := locs[pc]
+			if !ok {
+				loc = &profile.Location{ID: uint64(len(p.Location) + 1), Address: pc}
+				for _, m := range p.Mapping {
+					if m.Start <= pc && pc < m.Limit {
+						loc.Mapping = m
+						break
+					}
+				}
+				locs[pc] = loc
+				p.Location = append(p.Location, loc)
+			}
+			s.Location = append(s.Location, loc)
+		}
+		samples[key.String()] = s
+		p.Sample = append(p.Sample, s)
+	}
+	return p
+}
+
+// mappings returns the profile mappings of the executable mappings of
+// maps, the one of exe first.
+func mappings(maps []proc.Mapping, exe string) []*profile.Mapping {
+	var bin, libs []*profile.Mapping
+	for _, m := range maps {
+		if !strings.Contains(m.Perms, "x") || m.Path == "" {
+			continue
+		}
+		pm := &profile.Mapping{
+			Start:  m.Start,
+			Limit:  m.End,
+			Offset: m.Offset,
+			File:   strings.TrimSuffix(m.Path, " (deleted)"),
+		}
+		if pm.File == exe {
+			bin = append(bin, pm)
+		} else {
+			libs = append(libs, pm)
+		}
+	}
+	all := append(bin, libs...)
+	for i, m := range all {
+		m.ID = uint64(i + 1)
+	}
+	return all
+}
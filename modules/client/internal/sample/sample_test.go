@@ -0,0 +1,195 @@
+package sample
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"os/exec"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/wgliang/opengacm/modules/client/internal/objfile"
+	"github.com/wgliang/opengacm/modules/client/internal/proc"
+	"github.com/wgliang/opengacm/modules/client/internal/report"
+)
+
+// stackMemory is the memory of a stack of frames starting at base.
+type stackMemory struct {
+	base uint64
+	data []byte
+}
+
+func (m *stackMemory) ReadAt(b []byte, off int64) (int, error) {
+	return bytes.NewReader(m.data).ReadAt(b, off-int64(m.base))
+}
+
+// frames is a frame table of the sizes of the frames at some PCs of
+// functions aligned on 0x1000 bytes.
+type frames map[uint64]int64
+
+func (f frames) Frame(pc uint64) (uint64, int64, bool) {
+	size, ok := f[pc]
+	return pc &^ 0xfff, size, ok
+}
+
+func TestUnwinder(t *testing.T) {
+	// The innermost function has a 0x20-byte frame at base once its
+	// prologue has run. Its caller has its frame pointer at base+0x40.
+	const base = 0xc000100000
+	words := make([]uint64, 18)
+	words[3] = base + 0x40                   // frame pointer of the caller
+	words[4] = 0x401010                      // return address into the caller
+	words[8], words[9] = base+0x80, 0x402020 // frame of the caller
+	words[16], words[17] = 0, 0x403030
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, words)
+	mem := &stackMemory{base, buf.Bytes()}
+	tab := frames{
+		0x400000: 0,    // entry
+		0x400001: 8,    // amd64: frame pointer pushed
+		0x400004: 0x20, // arm64: frame allocated
+		0x400010: 0x20, // body
+		0x500010: 0,    // leaf without frame
+	}
+	want := Stack{0, 0x401010, 0x402020, 0x403030}
+
+	for _, tt := range []struct {
+		name string
+		arch string
+		r    Regs
+	}{
+		// The return address is at SP and FP is the caller's until the
+		// frame pointer is pushed below the return address and FP
+		// points to it.
+		{"amd64 entry", "amd64", Regs{PC: 0x400000, SP: base + 0x20, FP: base + 0x40}},
+		{"amd64 prologue", "amd64", Regs{PC: 0x400001, SP: base + 0x18, FP: base + 0x40}},
+		{"amd64 body", "amd64", Regs{PC: 0x400010, SP: base, FP: base + 0x18}},
+		{"amd64 leaf", "amd64", Regs{PC: 0x500010, SP: base + 0x20, FP: base + 0x40}},
+		// The return address is in LR until the prologue saves it at the
+		// bottom of the frame, below which the frame pointer is saved.
+		{"arm64 entry", "arm64", Regs{PC: 0x400000, SP: base + 0x40, FP: base + 0x40, LR: 0x401010}},
+		{"arm64 prologue", "arm64", Regs{PC: 0x400004, SP: base + 0x20, FP: base + 0x40, LR: 0x401010}},
+		{"arm64 body", "arm64", Regs{PC: 0x400010, SP: base + 0x20, FP: base + 0x18}},
+		{"arm64 leaf", "arm64", Regs{PC: 0x500010, SP: base + 0x20, FP: base + 0x40, LR: 0x401010}},
+	} {
+		u := &Unwinder{Frames: tab, Arch: tt.arch}
+		want[0] = tt.r.PC
+		if got := u.Unwind(mem, tt.r); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: Unwind = %#x; want %#x", tt.name, got, want)
+		}
+	}
+	// Out of the functions of the binary, the frame pointers are walked
+	// from FP, missing the caller of the innermost function.
+	u := &Unwinder{Frames: tab, Arch: "amd64"}
+	if got := u.Unwind(mem, Regs{PC: 0x600000, SP: base, FP: base + 0x40}); !reflect.DeepEqual(got, Stack{0x600000, 0x402020, 0x403030}) {
+		t.Errorf("Unwind out of the binary = %#x", got)
+	}
+}
+
+func TestBuild(t *testing.T) {
+	maps := []proc.Mapping{
+		{Start: 0x400000, End: 0x500000, Perms: "r-xp", Path: "/opt/server"},
+		{Start: 0x500000, End: 0x600000, Perms: "r--p", Offset: 0x100000, Path: "/opt/server"},
+		{Start: 0x7f0000000000, End: 0x7f0000100000, Perms: "r-xp", Offset: 0x1000, Path: "/lib/libc.so.6"},
+		{Start: 0x7ffc00000000, End: 0x7ffc00021000, Perms: "rw-p", Path: "[stack]"},
+	}
+	stacks := []Stack{
+		{0x401000, 0x402001},
+		{0x401000, 0x402001},
+		{0x7f0000000100, 0x402001},
+	}
+	p := Build(stacks, 10*time.Millisecond, time.Second, maps, "/opt/server")
+	if err := p.CheckValid(); err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Mapping) != 2 || p.Mapping[0].File != "/opt/server" || p.Mapping[1].File != "/lib/libc.so.6" {
+		t.Fatalf("mappings = %+v; want /opt/server and /lib/libc.so.6", p.Mapping)
+	}
+	if len(p.Sample) != 2 {
+		t.Fatalf("%d samples; want 2", len(p.Sample))
+	}
+	if v := p.Sample[0].Value; v[0] != 2 || v[1] != 2*int64(10*time.Millisecond) {
+		t.Errorf("values of the first sample = %v; want [2 20000000]", v)
+	}
+	// The return addresses are moved back into the calls.
+	if l := p.Sample[0].Location; len(l) != 2 || l[0].Address != 0x401000 || l[1].Address != 0x402000 {
+		t.Errorf("locations of the first sample = %+v", l)
+	}
+	if l := p.Sample[1].Location; l[0].Mapping != p.Mapping[1] || l[1] != p.Sample[0].Location[1] {
+		t.Errorf("locations of the second sample = %+v", l)
+	}
+	if p.DurationNanos != int64(time.Second) || p.Period != int64(10*time.Millisecond) {
+		t.Errorf("duration %d, period %d", p.DurationNanos, p.Period)
+	}
+}
+
+var sink uint64
+
+//go:noinline
+func spin() {
+	for i := uint64(0); ; i++ {
+		sink += i * i
+	}
+}
+
+func TestSample(t *testing.T) {
+	if os.Getenv("SAMPLE_SPIN") != "" {
+		spin()
+	}
+	cmd := exec.Command(os.Args[0], "-test.run=^TestSample$")
+	cmd.Env = append(os.Environ(), "SAMPLE_SPIN=1")
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer cmd.Wait()
+	defer cmd.Process.Kill()
+	pid := cmd.Process.Pid
+
+	exe, err := os.Readlink(proc.Path(pid, "exe"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	maps, err := proc.Maps(pid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := objfile.Open(exe)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	load, err := f.LoadAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	u := &Unwinder{Arch: f.GOARCH()}
+	if u.Frames, err = f.FrameTable(); err != nil {
+		t.Fatal(err)
+	}
+	if m := proc.Mapped(maps, exe); len(m) > 0 {
+		u.Offset = m[0].Start - m[0].Offset - load
+	}
+	stacks, err := Sample(pid, 300*time.Millisecond, 100, u)
+	if err != nil {
+		t.Skip(err)
+	}
+	if len(stacks) == 0 {
+		t.Fatal("no stacks sampled")
+	}
+	p := Build(stacks, 10*time.Millisecond, 300*time.Millisecond, maps, exe)
+	if err := report.Symbolize(p, f); err != nil {
+		t.Fatal(err)
+	}
+	// spin has no frame, its caller is found with the frame table.
+	const pkg = "github.com/wgliang/opengacm/modules/client/internal/sample."
+	for _, s := range p.Sample {
+		if len(s.Location) < 2 || len(s.Location[0].Line) == 0 || len(s.Location[1].Line) == 0 {
+			continue
+		}
+		if s.Location[0].Line[0].Function.Name == pkg+"spin" && s.Location[1].Line[0].Function.Name == pkg+"TestSample" {
+			return
+		}
+	}
+	t.Errorf("no sample in spin called by TestSample out of %d stacks", len(stacks))
+}
@@ -0,0 +1,255 @@
+// Package symbols stores binaries by build ID and resolves addresses in
+// them, so that the profiles collected on many hosts can be symbolized in
+// one place without shipping the binary along with every profile.
+package symbols
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/wgliang/opengacm/modules/client/internal/objfile"
+)
+
+// indexFile is the name of the file listing the binaries of a store.
+const indexFile = "index.json"
+
+// Binary is a binary of a store.
+type Binary struct {
+	Name       string    `json:"name"`                   // name of the application or of the upload
+	File       string    `json:"file"`                   // file name in the store directory
+	BuildID    string    `json:"build_id,omitempty"`     // Go build ID
+	GNUBuildID string    `json:"gnu_build_id,omitempty"` // GNU build ID in hexadecimal, as in pprof mappings
+	Size       int64     `json:"size"`
+	Added      time.Time `json:"added"`
+}
+
+// Store keeps binaries in a directory, looked up by either of their build
+// IDs. It is safe for concurrent use.
+type Store struct {
+	dir string
+
+	mu       sync.Mutex
+	binaries []*Binary
+	byID     map[string]*Binary
+}
+
+// Open returns the store in the directory dir, creating it if needed.
+func Open(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	s := &Store{dir: dir, byID: make(map[string]*Binary)}
+	b, err := ioutil.ReadFile(filepath.Join(dir, indexFile))
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var binaries []*Binary
+	if err := json.Unmarshal(b, &binaries); err != nil {
+		return nil, fmt.Errorf("%s: %v", filepath.Join(dir, indexFile), err)
+	}
+	for _, b := range binaries {
+		// Binaries removed by hand are dropped from the index.
+		if _, err := os.Stat(filepath.Join(dir, b.File)); err == nil {
+			s.index(b)
+		}
+	}
+	return s, nil
+}
+
+// Lookup returns the binary with the Go or GNU build ID id, or nil if the
+// store has none.
+func (s *Store) Lookup(id string) *Binary {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.byID[id]
+}
+
+// Binaries returns the binaries of the store in the order they were added.
+func (s *Store) Binaries() []*Binary {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*Binary(nil), s.binaries...)
+}
+
+// Path returns the path of the file of b.
+func (s *Store) Path(b *Binary) string {
+	return filepath.Join(s.dir, b.File)
+}
+
+// AddFile adds the binary at path under name, unless the store already
+// has it. It returns the stored binary.
+func (s *Store) AddFile(name, path string) (*Binary, error) {
+	goID, gnuID, err := buildIDs(path)
+	if err != nil {
+		return nil, err
+	}
+	if b := s.lookup(goID, gnuID); b != nil {
+		return b, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return s.Add(name, f)
+}
+
+// Add reads a binary from r and adds it under name, unless the store
+// already has it. It returns the stored binary.
+func (s *Store) Add(name string, r io.Reader) (*Binary, error) {
+	tmp, err := ioutil.TempFile(s.dir, ".add")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+	size, err := io.Copy(tmp, r)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return nil, err
+	}
+	goID, gnuID, err := buildIDs(tmp.Name())
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if b := s.lookupLocked(goID, gnuID); b != nil {
+		return b, nil
+	}
+	b := &Binary{
+		Name:       name,
+		File:       fileName(goID, gnuID),
+		BuildID:    goID,
+		GNUBuildID: gnuID,
+		Size:       size,
+		Added:      time.Now(),
+	}
+	if err := os.Rename(tmp.Name(), s.Path(b)); err != nil {
+		return nil, err
+	}
+	s.index(b)
+	if err := s.save(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (s *Store) lookup(goID, gnuID string) *Binary {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lookupLocked(goID, gnuID)
+}
+
+func (s *Store) lookupLocked(goID, gnuID string) *Binary {
+	for _, id := range []string{goID, gnuID} {
+		if b := s.byID[id]; id != "" && b != nil {
+			return b
+		}
+	}
+	return nil
+}
+
+// index adds b to the binaries of s. The lock must be held or s not yet
+// shared.
+func (s *Store) index(b *Binary) {
+	s.binaries = append(s.binaries, b)
+	for _, id := range []string{b.BuildID, b.GNUBuildID} {
+		if id != "" {
+			s.byID[id] = b
+		}
+	}
+}
+
+// save writes the index file, replacing the previous one at once.
+func (s *Store) save() error {
+	data, err := json.MarshalIndent(s.binaries, "", "\t")
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(s.dir, ".index")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(data)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(s.dir, indexFile))
+}
+
+// buildIDs returns the Go and GNU build IDs of the binary at path, which
+// must have at least one.
+func buildIDs(path string) (goID, gnuID string, err error) {
+	f, err := objfile.Open(path)
+	if err != nil {
+		return "", "", err
+	}
+	defer f.Close()
+	goID, _ = f.BuildID()
+	gnuID, _ = f.GNUBuildID()
+	if goID == "" && gnuID == "" {
+		return "", "", fmt.Errorf("%s has no build ID", path)
+	}
+	return goID, gnuID, nil
+}
+
+// fileName returns the name of the file of the binary with the build IDs.
+// Go build IDs are made of URL-safe base64 separated by slashes.
+func fileName(goID, gnuID string) string {
+	if gnuID != "" {
+		return gnuID
+	}
+	return strings.Replace(goID, "/", ".", -1)
+}
+
+// Symbolize returns the names of the functions of b at the addresses, or
+// empty strings for the addresses out of its functions. The addresses are
+// the ones of a mapping of the binary at start with the file offset, as
+// recorded in profiles; with a zero start, they are link-time addresses.
+func (s *Store) Symbolize(b *Binary, addrs []uint64, start, offset uint64) ([]string, error) {
+	f, err := objfile.Open(s.Path(b))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	load, err := f.LoadAddress()
+	if err != nil {
+		return nil, err
+	}
+	// The table of lines is kept in stripped binaries.
+	tab, err := f.PCLineTable()
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, len(addrs))
+	for i, addr := range addrs {
+		if start != 0 {
+			if addr < start {
+				continue
+			}
+			addr = addr - start + offset + load
+		}
+		if _, _, fn := tab.PCToLine(addr); fn != nil {
+			names[i] = fn.Name
+		}
+	}
+	return names, nil
+}
@@ -0,0 +1,114 @@
+package symbols
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"runtime"
+	"testing"
+
+	"github.com/wgliang/opengacm/modules/client/internal/objfile"
+	"github.com/wgliang/opengacm/modules/client/internal/proc"
+)
+
+func TestStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "symbols")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	s, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := s.AddFile("test", os.Args[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.BuildID == "" || b.Name != "test" {
+		t.Errorf("AddFile = %+v", b)
+	}
+	if again, err := s.AddFile("again", os.Args[0]); err != nil || again != b {
+		t.Errorf("AddFile of a stored binary = %+v, %v; want %+v", again, err, b)
+	}
+	if _, err := s.AddFile("script", "symbols_test.go"); err == nil {
+		t.Error("AddFile of a source file succeeded")
+	}
+
+	// The store is read back from its directory.
+	s, err = Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := s.Binaries(); len(got) != 1 || got[0].File != b.File {
+		t.Fatalf("Binaries = %+v; want %+v", got, b)
+	}
+	for _, id := range []string{b.BuildID, b.GNUBuildID} {
+		if id != "" && s.Lookup(id) == nil {
+			t.Errorf("Lookup(%q) = nil", id)
+		}
+	}
+	if s.Lookup("unknown") != nil {
+		t.Error("Lookup of an unknown build ID succeeded")
+	}
+}
+
+func TestSymbolize(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("no /proc file system")
+	}
+	dir, err := ioutil.TempDir("", "symbols")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	s, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := s.AddFile("test", os.Args[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := objfile.Open(os.Args[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	load, err := f.LoadAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The test binary is symbolized at the address of this function in
+	// the process, as in a profile of it.
+	pc := uint64(reflect.ValueOf(TestSymbolize).Pointer())
+	maps, err := proc.Maps(os.Getpid())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m *proc.Mapping
+	for i := range maps {
+		if maps[i].Start <= pc && pc < maps[i].End {
+			m = &maps[i]
+		}
+	}
+	if m == nil {
+		t.Fatalf("%#x is not mapped", pc)
+	}
+	want := []string{"github.com/wgliang/opengacm/modules/client/internal/symbols.TestSymbolize", ""}
+
+	names, err := s.Symbolize(b, []uint64{pc + 1, 1}, m.Start, m.Offset)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("Symbolize of mapped addresses = %q; want %q", names, want)
+	}
+	names, err = s.Symbolize(b, []uint64{pc - m.Start + m.Offset + load + 1, 1}, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("Symbolize of link-time addresses = %q; want %q", names, want)
+	}
+}
@@ -0,0 +1,67 @@
+package symsize
+
+import "sort"
+
+// Delta is the change of the size of a package or symbol between two
+// binaries.
+type Delta struct {
+	Name string
+	Old  Sizes
+	New  Sizes
+}
+
+// Total returns the change of the total size.
+func (d *Delta) Total() int64 { return d.New.Total() - d.Old.Total() }
+
+// Diff returns the changes of the sizes of the packages and of the symbols
+// between base and cur, by decreasing absolute change. Packages and symbols
+// whose size did not change are omitted.
+func Diff(base, cur *Breakdown) (pkgs, syms []*Delta) {
+	pkgDeltas := make(map[string]*Delta)
+	symDeltas := make(map[string]*Delta)
+	get := func(m map[string]*Delta, name string) *Delta {
+		d, ok := m[name]
+		if !ok {
+			d = &Delta{Name: name}
+			m[name] = d
+		}
+		return d
+	}
+	for _, p := range base.Packages {
+		get(pkgDeltas, p.Name).Old = p.Sizes
+		for _, s := range p.Symbols {
+			get(symDeltas, s.Name).Old[s.Kind] += s.Size
+		}
+	}
+	for _, p := range cur.Packages {
+		get(pkgDeltas, p.Name).New = p.Sizes
+		for _, s := range p.Symbols {
+			get(symDeltas, s.Name).New[s.Kind] += s.Size
+		}
+	}
+	return sorted(pkgDeltas), sorted(symDeltas)
+}
+
+func sorted(m map[string]*Delta) []*Delta {
+	var ds []*Delta
+	for _, d := range m {
+		if d.Old != d.New {
+			ds = append(ds, d)
+		}
+	}
+	sort.Slice(ds, func(i, j int) bool {
+		a, b := abs(ds[i].Total()), abs(ds[j].Total())
+		if a != b {
+			return a > b
+		}
+		return ds[i].Name < ds[j].Name
+	})
+	return ds
+}
+
+func abs(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
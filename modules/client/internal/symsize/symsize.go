@@ -0,0 +1,217 @@
+// Package symsize attributes the size of a Go binary to the packages
+// and symbols it is made of.
+package symsize
+
+import (
+	"debug/gosym"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/wgliang/opengacm/modules/client/internal/objfile"
+)
+
+// Kind is the kind of memory a symbol occupies.
+type Kind int
+
+const (
+	Text Kind = iota
+	RoData
+	Data
+	BSS
+	numKinds
+)
+
+var kindNames = [numKinds]string{"text", "rodata", "data", "bss"}
+
+func (k Kind) String() string { return kindNames[k] }
+
+// Sizes are sizes in bytes by kind.
+type Sizes [numKinds]int64
+
+// Total returns the sum of the sizes.
+func (s *Sizes) Total() int64 {
+	var t int64
+	for _, n := range s {
+		t += n
+	}
+	return t
+}
+
+// Symbol is a symbol of a binary.
+type Symbol struct {
+	Name string
+	Kind Kind
+	Size int64
+}
+
+// Package is the symbols of a Go package, or of a group of symbols that
+// do not belong to a package, such as the ones of C code.
+type Package struct {
+	Name    string
+	Sizes   Sizes
+	Symbols []*Symbol // by decreasing size
+}
+
+// Breakdown is the size of a binary by package.
+type Breakdown struct {
+	Packages []*Package // by decreasing total size
+	Sizes    Sizes
+	// Stripped is set if the binary has no symbol table, in
+	// which case only the functions of the pclntab are counted.
+	Stripped bool
+}
+
+// Package returns the package named name, or nil.
+func (b *Breakdown) Package(name string) *Package {
+	for _, p := range b.Packages {
+		if p.Name == name {
+			return p
+		}
+	}
+	return nil
+}
+
+// Read returns the size breakdown of f.
+func Read(f *objfile.File) (*Breakdown, error) {
+	syms, err := f.Symbols()
+	if err == nil && len(syms) > 0 {
+		return fromSymbols(syms), nil
+	}
+	pcln, perr := f.PCLineTable()
+	if perr != nil {
+		if err == nil {
+			err = perr
+		}
+		return nil, err
+	}
+	tab, ok := pcln.(*gosym.Table)
+	if !ok || len(tab.Funcs) == 0 {
+		return nil, fmt.Errorf("no symbols")
+	}
+	syms = syms[:0]
+	for _, fn := range tab.Funcs {
+		syms = append(syms, objfile.Sym{Name: fn.Name, Addr: fn.Entry, Size: int64(fn.End - fn.Entry), Code: 'T'})
+	}
+	b := fromSymbols(syms)
+	b.Stripped = true
+	return b, nil
+}
+
+func fromSymbols(syms []objfile.Sym) *Breakdown {
+	b := &Breakdown{}
+	pkgs := make(map[string]*Package)
+	for _, s := range syms {
+		kind, ok := symKind(s.Code)
+		if !ok || s.Size <= 0 {
+			continue
+		}
+		name := PackageName(s.Name)
+		p := pkgs[name]
+		if p == nil {
+			p = &Package{Name: name}
+			pkgs[name] = p
+			b.Packages = append(b.Packages, p)
+		}
+		p.Symbols = append(p.Symbols, &Symbol{Name: s.Name, Kind: kind, Size: s.Size})
+		p.Sizes[kind] += s.Size
+		b.Sizes[kind] += s.Size
+	}
+	for _, p := range b.Packages {
+		sort.Slice(p.Symbols, func(i, j int) bool {
+			a, b := p.Symbols[i], p.Symbols[j]
+			if a.Size != b.Size {
+				return a.Size > b.Size
+			}
+			return a.Name < b.Name
+		})
+	}
+	sort.Slice(b.Packages, func(i, j int) bool {
+		a, c := b.Packages[i], b.Packages[j]
+		if ta, tc := a.Sizes.Total(), c.Sizes.Total(); ta != tc {
+			return ta > tc
+		}
+		return a.Name < c.Name
+	})
+	return b
+}
+
+// symKind returns the kind of memory of the nm code of a symbol.
+func symKind(code rune) (Kind, bool) {
+	switch code {
+	case 'T', 't':
+		return Text, true
+	case 'R', 'r':
+		return RoData, true
+	case 'D', 'd':
+		return Data, true
+	case 'B', 'b':
+		return BSS, true
+	}
+	return 0, false
+}
+
+// Names of the groups of the symbols that belong to no Go package.
+const (
+	Linker = "<linker>" // generated by the Go linker, such as go:string.*
+	Other  = "<other>"  // C code, assembly glue and the like
+)
+
+// PackageName returns the import path of the Go package that defines the
+// symbol named sym. Type descriptors and equality functions belong to the
+// package of their type, the instantiations of generic functions to the
+// package of the generic function, and vendored packages to the import
+// path of the dependency.
+func PackageName(sym string) string {
+	fallback := Other
+	for _, prefix := range []string{"type:.eq.", "type..eq.", "type:", "type.", "go:itab.", "go.itab."} {
+		if strings.HasPrefix(sym, prefix) {
+			sym = elemType(sym[len(prefix):])
+			if i := strings.IndexByte(sym, ','); i > 0 && strings.Contains(prefix, "itab") {
+				// go:itab.<type>,<interface>
+				sym = elemType(sym[:i])
+			}
+			fallback = Linker
+			break
+		}
+	}
+	if strings.HasPrefix(sym, "go:") || strings.HasPrefix(sym, "go.") || strings.HasPrefix(sym, "gclocals") {
+		return Linker
+	}
+	// The type arguments of instantiations may contain dots and slashes.
+	if i := strings.IndexByte(sym, '['); i > 0 {
+		sym = sym[:i]
+	}
+	if strings.ContainsAny(sym, " (") && !strings.Contains(sym, ".(") {
+		// An unnamed type, such as func(int) or struct { ... }.
+		return fallback
+	}
+	start := strings.LastIndexByte(sym, '/') + 1
+	dot := strings.IndexByte(sym[start:], '.')
+	if dot <= 0 {
+		return fallback
+	}
+	// The linker escapes the dots of the last element of import paths.
+	pkg := strings.Replace(sym[:start+dot], "%2e", ".", -1)
+	// Vendored packages are attributed to the vendored dependency.
+	if i := strings.LastIndex(pkg, "vendor/"); i == 0 || i > 0 && pkg[i-1] == '/' {
+		pkg = pkg[i+len("vendor/"):]
+	}
+	return pkg
+}
+
+// elemType strips the pointer, slice and array prefixes of a type name.
+func elemType(typ string) string {
+	for {
+		switch {
+		case strings.HasPrefix(typ, "*"):
+			typ = typ[1:]
+		case strings.HasPrefix(typ, "[]"):
+			typ = typ[2:]
+		case strings.HasPrefix(typ, "[") && strings.IndexByte(typ, ']') > 0:
+			typ = typ[strings.IndexByte(typ, ']')+1:]
+		default:
+			return typ
+		}
+	}
+}
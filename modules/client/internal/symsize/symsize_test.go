@@ -0,0 +1,117 @@
+package symsize
+
+import (
+	"testing"
+
+	"github.com/wgliang/opengacm/modules/client/internal/objfile"
+)
+
+func TestPackageName(t *testing.T) {
+	for sym, want := range map[string]string{
+		"main.main":                                 "main",
+		"runtime.mallocgc":                          "runtime",
+		"github.com/a/b.(*T).Method":                "github.com/a/b",
+		"github.com/a/b.Func.func1":                 "github.com/a/b",
+		"github.com/a/b.init":                       "github.com/a/b",
+		"slices.Sort[go.shape.[]github.com/a/b.T]":  "slices",
+		"gopkg.in/yaml%2ev2.Unmarshal":              "gopkg.in/yaml.v2",
+		"type:*github.com/a/b.T":                    "github.com/a/b",
+		"type:[4]net/http.Header":                   "net/http",
+		"type:.eq.github.com/a/b.T":                 "github.com/a/b",
+		"type..eq.[2]interface {}":                  Linker,
+		"type:map[string]int":                       Linker,
+		"type:func(int) error":                      Linker,
+		"type:int":                                  Linker,
+		"type:.namedata.*func()-":                   Linker,
+		"go:itab.*os.File,io.Reader":                "os",
+		"go:itab.*net.TCPConn,github.com/a/b.Conn":  "net",
+		"go:string.*":                               Linker,
+		"go.buildid":                                Linker,
+		"gclocals·a5bc4c9ea1a2a2e4f0c1e1a2b1e0d0f3": Linker,
+		"github.com/a/b/vendor/golang.org/x/arch/x86/x86asm.Decode": "golang.org/x/arch/x86/x86asm",
+		"vendor/golang.org/x/net/http2/hpack.NewDecoder":            "golang.org/x/net/http2/hpack",
+		"github.com/a/notvendor/b.F":                                "github.com/a/notvendor/b",
+		"x_cgo_init":                                                Other,
+		"_cgo_topofstack":                                           Other,
+	} {
+		if got := PackageName(sym); got != want {
+			t.Errorf("PackageName(%q) = %q; want %q", sym, got, want)
+		}
+	}
+}
+
+func testBreakdown(syms map[string]int64) *Breakdown {
+	var s []objfile.Sym
+	for name, size := range syms {
+		s = append(s, objfile.Sym{Name: name, Size: size, Code: 'T'})
+	}
+	return fromSymbols(s)
+}
+
+func TestTree(t *testing.T) {
+	b := testBreakdown(map[string]int64{
+		"main.main":                10,
+		"github.com/a/b.F":         100,
+		"github.com/a/b/c.G":       50,
+		"github.com/x/y.H":         30,
+		"github.com/x/y.(*T).M":    5,
+		"golang.org/x/net/http2.F": 20,
+	})
+	if b.Sizes.Total() != 215 {
+		t.Fatalf("total = %d; want 215", b.Sizes.Total())
+	}
+	if p := b.Package("github.com/x/y"); p == nil || p.Sizes[Text] != 35 || p.Symbols[0].Name != "github.com/x/y.H" {
+		t.Errorf("unexpected package github.com/x/y: %+v", p)
+	}
+
+	root := b.Tree()
+	var got []string
+	var walk func(n *Node, indent string)
+	walk = func(n *Node, indent string) {
+		for _, c := range n.Children {
+			got = append(got, indent+c.Name)
+			walk(c, indent+" ")
+		}
+	}
+	walk(root, "")
+	want := []string{
+		"github.com",
+		" github.com/a/b",
+		"  github.com/a/b/c",
+		" github.com/x/y",
+		"golang.org/x/net/http2",
+		"main",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("tree:\n%q\nwant:\n%q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("tree[%d] = %q; want %q", i, got[i], want[i])
+		}
+	}
+	if n := root.Children[0]; n.Sizes.Total() != 185 {
+		t.Errorf("github.com total = %d; want 185", n.Sizes.Total())
+	}
+}
+
+func TestDiff(t *testing.T) {
+	base := testBreakdown(map[string]int64{"main.main": 10, "a.F": 100, "b.G": 50})
+	cur := testBreakdown(map[string]int64{"main.main": 10, "a.F": 80, "c.H": 70})
+	pkgs, syms := Diff(base, cur)
+	want := []struct {
+		name  string
+		delta int64
+	}{{"c", 70}, {"b", -50}, {"a", -20}}
+	if len(pkgs) != len(want) {
+		t.Fatalf("got %d package deltas; want %d", len(pkgs), len(want))
+	}
+	for i, w := range want {
+		if pkgs[i].Name != w.name || pkgs[i].Total() != w.delta {
+			t.Errorf("pkgs[%d] = %s %+d; want %s %+d", i, pkgs[i].Name, pkgs[i].Total(), w.name, w.delta)
+		}
+	}
+	if len(syms) != 3 || syms[0].Name != "c.H" {
+		t.Errorf("unexpected symbol deltas %v", syms)
+	}
+}
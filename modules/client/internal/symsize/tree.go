@@ -0,0 +1,76 @@
+package symsize
+
+import (
+	"sort"
+	"strings"
+)
+
+// Node is a node of the tree of the import paths of the packages.
+// The sizes of a node include the ones of its children.
+type Node struct {
+	Name     string   // import path prefix
+	Package  *Package // nil if no package has this import path
+	Sizes    Sizes
+	Children []*Node // by decreasing total size
+}
+
+// Tree arranges the packages of b by import path, so that the size of
+// a module or of a repository host adds up the sizes of its packages.
+// Path elements leading to a single child are merged into it.
+func (b *Breakdown) Tree() *Node {
+	root := &Node{}
+	for _, p := range b.Packages {
+		n := root
+		elems := strings.Split(p.Name, "/")
+		for i := range elems {
+			name := strings.Join(elems[:i+1], "/")
+			n.Sizes.add(&p.Sizes)
+			n = n.child(name)
+		}
+		n.Sizes.add(&p.Sizes)
+		n.Package = p
+	}
+	root.collapse()
+	root.sort()
+	return root
+}
+
+func (n *Node) child(name string) *Node {
+	for _, c := range n.Children {
+		if c.Name == name {
+			return c
+		}
+	}
+	c := &Node{Name: name}
+	n.Children = append(n.Children, c)
+	return c
+}
+
+func (n *Node) collapse() {
+	for i, c := range n.Children {
+		for c.Package == nil && len(c.Children) == 1 {
+			c = c.Children[0]
+		}
+		n.Children[i] = c
+		c.collapse()
+	}
+}
+
+func (n *Node) sort() {
+	sort.Slice(n.Children, func(i, j int) bool {
+		a, b := n.Children[i], n.Children[j]
+		if ta, tb := a.Sizes.Total(), b.Sizes.Total(); ta != tb {
+			return ta > tb
+		}
+		return a.Name < b.Name
+	})
+	for _, c := range n.Children {
+		c.sort()
+	}
+}
+
+func (s *Sizes) add(o *Sizes) {
+	for k, n := range o {
+		s[k] += n
+	}
+}
@@ -19,6 +19,8 @@ const (
 	MIPS64
 	PPC64
 	S390X
+	MIPS
+	RISCV64
 )
 
 // Arch represents an individual architecture.
@@ -97,6 +99,26 @@ var ArchARM64 = &Arch{
 	MinLC:     4,
 }
 
+var ArchMIPS = &Arch{
+	Name:      "mips",
+	Family:    MIPS,
+	ByteOrder: binary.BigEndian,
+	IntSize:   4,
+	PtrSize:   4,
+	RegSize:   4,
+	MinLC:     4,
+}
+
+var ArchMIPSLE = &Arch{
+	Name:      "mipsle",
+	Family:    MIPS,
+	ByteOrder: binary.LittleEndian,
+	IntSize:   4,
+	PtrSize:   4,
+	RegSize:   4,
+	MinLC:     4,
+}
+
 var ArchMIPS64 = &Arch{
 	Name:      "mips64",
 	Family:    MIPS64,
@@ -137,6 +159,16 @@ var ArchPPC64LE = &Arch{
 	MinLC:     4,
 }
 
+var ArchRISCV64 = &Arch{
+	Name:      "riscv64",
+	Family:    RISCV64,
+	ByteOrder: binary.LittleEndian,
+	IntSize:   8,
+	PtrSize:   8,
+	RegSize:   8,
+	MinLC:     2,
+}
+
 var ArchS390X = &Arch{
 	Name:      "s390x",
 	Family:    S390X,
@@ -153,9 +185,12 @@ var Archs = [...]*Arch{
 	ArchAMD64P32,
 	ArchARM,
 	ArchARM64,
+	ArchMIPS,
+	ArchMIPSLE,
 	ArchMIPS64,
 	ArchMIPS64LE,
 	ArchPPC64,
 	ArchPPC64LE,
+	ArchRISCV64,
 	ArchS390X,
 }
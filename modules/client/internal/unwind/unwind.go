@@ -0,0 +1,44 @@
+// Package unwind walks the stacks of Go programs along their frame
+// pointers. Go functions on amd64 and arm64 save the frame pointer of
+// their caller at the address of their own, followed by the return
+// address.
+package unwind
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+// MaxDepth is the maximum number of frames of a stack.
+const MaxDepth = 128
+
+// FramePointers returns the PCs of the stack starting at pc whose frame
+// pointer is fp, in the memory mem: pc followed by the return addresses.
+// A leaf function stopped before saving the frame pointer, or one too
+// small to have a frame, misses its caller in the stack.
+//
+// Stacks grow down, so the frames of the callers are above, and the walk
+// stops at the first frame pointer that is not. Given switchStacks, it
+// goes on below: the frames of signal handlers and of the system stack
+// link to the ones of the goroutine they interrupted or run for, which
+// can be anywhere in memory. The walk then only stops at a frame linked
+// to itself, or after MaxDepth frames.
+func FramePointers(mem io.ReaderAt, pc, fp uint64, switchStacks bool) []uint64 {
+	stack := []uint64{pc}
+	var b [16]byte
+	for fp != 0 && len(stack) < MaxDepth {
+		if _, err := mem.ReadAt(b[:], int64(fp)); err != nil {
+			break
+		}
+		next, ret := binary.LittleEndian.Uint64(b[:8]), binary.LittleEndian.Uint64(b[8:])
+		if ret == 0 {
+			break
+		}
+		stack = append(stack, ret)
+		if next == fp || next < fp && !switchStacks {
+			break
+		}
+		fp = next
+	}
+	return stack
+}
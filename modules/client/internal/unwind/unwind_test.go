@@ -0,0 +1,73 @@
+package unwind
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"testing"
+)
+
+// stackMemory is the memory of a stack of frames starting at base.
+type stackMemory struct {
+	base uint64
+	data []byte
+}
+
+func (m *stackMemory) ReadAt(b []byte, off int64) (int, error) {
+	return bytes.NewReader(m.data).ReadAt(b, off-int64(m.base))
+}
+
+func newMemory(base uint64, words []uint64) *stackMemory {
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, words)
+	return &stackMemory{base, buf.Bytes()}
+}
+
+func TestFramePointers(t *testing.T) {
+	// Three frames, each holding the frame pointer of its caller and
+	// the return address into it. The outermost frame pointer is 0.
+	const base = 0xc000100000
+	mem := newMemory(base, []uint64{
+		base + 0x20, 0x401010, // frame at base
+		0, 0,
+		base + 0x40, 0x402020, // frame at base+0x20
+		0, 0,
+		0, 0x403030, // frame at base+0x40
+	})
+	want := []uint64{0x400500, 0x401010, 0x402020, 0x403030}
+	for _, switchStacks := range []bool{false, true} {
+		if got := FramePointers(mem, 0x400500, base, switchStacks); !reflect.DeepEqual(got, want) {
+			t.Errorf("FramePointers(switchStacks %v) = %#x; want %#x", switchStacks, got, want)
+		}
+	}
+	// A frame pointer outside of the stack ends it.
+	if got := FramePointers(mem, 0x400500, 0x10, false); !reflect.DeepEqual(got, []uint64{0x400500}) {
+		t.Errorf("FramePointers of a bad frame pointer = %#x", got)
+	}
+}
+
+func TestFramePointersSwitchStacks(t *testing.T) {
+	// The frame of a handler at base+0x40 links to the one of the
+	// goroutine it interrupted at base, below it.
+	const base = 0xc000100000
+	mem := newMemory(base, []uint64{
+		base + 0x10, 0x401010, // goroutine frame at base
+		0, 0x402020, // outermost goroutine frame at base+0x10
+		0, 0,
+		0, 0,
+		base, 0x409090, // handler frame at base+0x40
+		0, 0,
+		base + 0x60, 0x408080, // frame linked to itself at base+0x60
+	})
+	// Stacks are walked upward only, unless they may switch.
+	if got, want := FramePointers(mem, 0x400500, base+0x40, false), []uint64{0x400500, 0x409090}; !reflect.DeepEqual(got, want) {
+		t.Errorf("FramePointers(handler) = %#x; want %#x", got, want)
+	}
+	if got, want := FramePointers(mem, 0x400500, base+0x40, true), []uint64{0x400500, 0x409090, 0x401010, 0x402020}; !reflect.DeepEqual(got, want) {
+		t.Errorf("FramePointers(handler, switchStacks) = %#x; want %#x", got, want)
+	}
+	// A frame linked to itself ends the walk, rather than looping.
+	if got, want := FramePointers(mem, 0x400500, base+0x60, true), []uint64{0x400500, 0x408080}; !reflect.DeepEqual(got, want) {
+		t.Errorf("FramePointers(self-linked frame) = %#x; want %#x", got, want)
+	}
+}
@@ -0,0 +1,343 @@
+// Package vuln matches the module versions recorded in Go binaries
+// against a local vulnerability database in the OSV format, such as an
+// extract of the Go vulnerability database or of the osv.dev Go
+// ecosystem, so that it works without network access.
+package vuln
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"sort"
+	"strings"
+
+	"github.com/wgliang/opengacm/modules/client/internal/objfile"
+	"golang.org/x/mod/semver"
+)
+
+// Stdlib is the package name under which the OSV databases record the
+// vulnerabilities of the standard library.
+const Stdlib = "stdlib"
+
+// Entry is an OSV advisory, reduced to the fields used for matching.
+type Entry struct {
+	ID        string     `json:"id"`
+	Aliases   []string   `json:"aliases,omitempty"`
+	Summary   string     `json:"summary,omitempty"`
+	Details   string     `json:"details,omitempty"`
+	Withdrawn string     `json:"withdrawn,omitempty"`
+	Affected  []Affected `json:"affected"`
+}
+
+// Affected lists the affected versions of a package.
+type Affected struct {
+	Package struct {
+		Name      string `json:"name"`
+		Ecosystem string `json:"ecosystem"`
+	} `json:"package"`
+	Ranges []Range `json:"ranges,omitempty"`
+}
+
+// Range is a range of affected versions, given as the sequence of
+// versions at which the vulnerability was introduced and fixed.
+type Range struct {
+	Type   string  `json:"type"`
+	Events []Event `json:"events"`
+}
+
+// Event is a boundary of a Range. Exactly one of its fields is set.
+type Event struct {
+	Introduced   string `json:"introduced,omitempty"`
+	Fixed        string `json:"fixed,omitempty"`
+	LastAffected string `json:"last_affected,omitempty"`
+}
+
+// DB is a vulnerability database, indexed by the affected modules.
+type DB struct {
+	entries map[string][]*Entry
+	n       int
+}
+
+// Load reads the OSV entries of the JSON files found in dir and its
+// subdirectories. Files that are not OSV entries, such as the index
+// files of the Go vulnerability database, are ignored, as are the
+// withdrawn advisories and the ones of other ecosystems.
+func Load(dir string) (*DB, error) {
+	db := &DB{entries: make(map[string][]*Entry)}
+	err := filepath.Walk(dir, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if fi.IsDir() || filepath.Ext(path) != ".json" {
+			return nil
+		}
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		e := new(Entry)
+		if json.Unmarshal(b, e) != nil || e.ID == "" || e.Withdrawn != "" {
+			return nil
+		}
+		db.add(e)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if db.n == 0 {
+		return nil, fmt.Errorf("no OSV entries found in %s", dir)
+	}
+	return db, nil
+}
+
+func (db *DB) add(e *Entry) {
+	seen := make(map[string]bool)
+	for _, a := range e.Affected {
+		name := a.Package.Name
+		if a.Package.Ecosystem != "Go" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		db.entries[name] = append(db.entries[name], e)
+	}
+	db.n++
+}
+
+// Len returns the number of entries of the database.
+func (db *DB) Len() int {
+	return db.n
+}
+
+// Module is a module version linked in a binary.
+type Module struct {
+	Path    string
+	Version string
+}
+
+// Finding is an advisory affecting a module version.
+type Finding struct {
+	ID      string   `json:"id"`
+	Aliases []string `json:"aliases,omitempty"`
+	Summary string   `json:"summary,omitempty"`
+	Module  string   `json:"module"`
+	Version string   `json:"version"`
+	Fixed   string   `json:"fixed,omitempty"` // empty if there is no fix yet
+}
+
+// Check returns the advisories affecting the modules, sorted by module
+// path and advisory ID. The standard library is checked as the Stdlib
+// module at the version of the module list.
+func (db *DB) Check(mods []Module) []Finding {
+	var findings []Finding
+	for _, m := range mods {
+		for _, e := range db.entries[m.Path] {
+			fixed, ok := e.affects(m.Path, m.Version)
+			if !ok {
+				continue
+			}
+			findings = append(findings, Finding{
+				ID:      e.ID,
+				Aliases: e.Aliases,
+				Summary: e.summary(),
+				Module:  m.Path,
+				Version: m.Version,
+				Fixed:   fixed,
+			})
+		}
+	}
+	sort.Slice(findings, func(i, j int) bool {
+		if findings[i].Module != findings[j].Module {
+			return findings[i].Module < findings[j].Module
+		}
+		return findings[i].ID < findings[j].ID
+	})
+	return findings
+}
+
+// affects reports whether the entry affects version v of the module
+// path, and returns the first version fixing it.
+func (e *Entry) affects(path, v string) (fixed string, ok bool) {
+	v = canonical(v)
+	if v == "" {
+		return "", false
+	}
+	for _, a := range e.Affected {
+		if a.Package.Ecosystem != "Go" || a.Package.Name != path {
+			continue
+		}
+		if len(a.Ranges) == 0 {
+			// No ranges means all the versions are affected.
+			return "", true
+		}
+		for _, r := range a.Ranges {
+			if r.Type != "SEMVER" {
+				continue
+			}
+			if f, ok := r.affects(v); ok {
+				return f, true
+			}
+		}
+	}
+	return "", false
+}
+
+// affects reports whether the canonical version v is in the range, and
+// returns the first fixed version following v.
+func (r Range) affects(v string) (fixed string, ok bool) {
+	events := make([]Event, len(r.Events))
+	copy(events, r.Events)
+	sort.SliceStable(events, func(i, j int) bool {
+		return semver.Compare(events[i].version(), events[j].version()) < 0
+	})
+	affected := false
+	for _, ev := range events {
+		c := semver.Compare(v, ev.version())
+		switch {
+		case ev.Introduced != "":
+			if c >= 0 {
+				affected = true
+			}
+		case ev.Fixed != "":
+			if c >= 0 {
+				affected = false
+			} else if affected && fixed == "" {
+				fixed = ev.version()
+			}
+		case ev.LastAffected != "":
+			if c > 0 {
+				affected = false
+			}
+		}
+	}
+	if !affected {
+		return "", false
+	}
+	return fixed, true
+}
+
+// version returns the canonical version of the event. The introduced
+// version "0" stands for the first version.
+func (ev Event) version() string {
+	switch {
+	case ev.Introduced == "0":
+		return "v0.0.0-0"
+	case ev.Introduced != "":
+		return canonical(ev.Introduced)
+	case ev.Fixed != "":
+		return canonical(ev.Fixed)
+	}
+	return canonical(ev.LastAffected)
+}
+
+// summary returns the summary of the entry, or the first line of its
+// details for the entries without one.
+func (e *Entry) summary() string {
+	if e.Summary != "" {
+		return e.Summary
+	}
+	s := strings.TrimSpace(e.Details)
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		s = s[:i]
+	}
+	return s
+}
+
+// canonical returns the semantic version v, which the OSV entries
+// write without the "v" prefix, or "" if v is not a version.
+func canonical(v string) string {
+	if !strings.HasPrefix(v, "v") {
+		v = "v" + v
+	}
+	if !semver.IsValid(v) {
+		return ""
+	}
+	return v
+}
+
+// GoVersion returns the version of the standard library module for the
+// Go version of a binary, as in "go1.21rc2", or "" for the development
+// versions.
+func GoVersion(v string) string {
+	if i := strings.IndexAny(v, " \t"); i >= 0 {
+		// Drop the experiments, as in "go1.20 X:boringcrypto".
+		v = v[:i]
+	}
+	if !strings.HasPrefix(v, "go1") {
+		return ""
+	}
+	v = v[len("go"):]
+	pre := ""
+	for _, tag := range []string{"rc", "beta"} {
+		if i := strings.Index(v, tag); i >= 0 {
+			pre = "-" + tag + "." + v[i+len(tag):]
+			v = v[:i]
+			break
+		}
+	}
+	if strings.Count(v, ".") == 1 {
+		v += ".0"
+	}
+	v = "v" + v + pre
+	if !semver.IsValid(v) {
+		return ""
+	}
+	return v
+}
+
+// Modules returns the modules linked in a binary with the build
+// information bi, starting with the standard library. Modules without
+// a version, such as the main module of a development build or the
+// replacements by local directories, are omitted.
+func Modules(bi *debug.BuildInfo) []Module {
+	var mods []Module
+	if v := GoVersion(bi.GoVersion); v != "" {
+		mods = append(mods, Module{Stdlib, v})
+	}
+	add := func(m *debug.Module) {
+		if m.Replace != nil {
+			m = m.Replace
+		}
+		if m.Version == "" || m.Version == "(devel)" {
+			return
+		}
+		mods = append(mods, Module{m.Path, m.Version})
+	}
+	add(&bi.Main)
+	for _, m := range bi.Deps {
+		add(m)
+	}
+	return mods
+}
+
+// Result is the result of checking a binary.
+type Result struct {
+	Binary    string    `json:"binary"`
+	GoVersion string    `json:"go_version"`
+	Findings  []Finding `json:"findings"`
+}
+
+// CheckBinary checks the modules linked in the Go binary at path.
+func (db *DB) CheckBinary(path string) (*Result, error) {
+	f, err := objfile.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	bi, err := f.BuildInfo()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	r := &Result{
+		Binary:    path,
+		GoVersion: bi.GoVersion,
+		Findings:  db.Check(Modules(bi)),
+	}
+	if r.Findings == nil {
+		r.Findings = []Finding{}
+	}
+	return r, nil
+}
@@ -0,0 +1,149 @@
+package vuln
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime/debug"
+	"testing"
+)
+
+var testEntries = map[string]string{
+	"ID/GO-2023-0001.json": `{
+		"id": "GO-2023-0001",
+		"aliases": ["CVE-2023-0001"],
+		"summary": "Panic in example.com/a",
+		"affected": [{
+			"package": {"name": "example.com/a", "ecosystem": "Go"},
+			"ranges": [{"type": "SEMVER", "events": [
+				{"introduced": "1.5.0"}, {"fixed": "1.6.1"},
+				{"introduced": "0"}, {"fixed": "1.2.0"}
+			]}]
+		}]
+	}`,
+	"ID/GO-2023-0002.json": `{
+		"id": "GO-2023-0002",
+		"details": "Request smuggling in net/http.\n\nMore details.",
+		"affected": [{
+			"package": {"name": "stdlib", "ecosystem": "Go"},
+			"ranges": [{"type": "SEMVER", "events": [
+				{"introduced": "0"}, {"fixed": "1.20.12"},
+				{"introduced": "1.21.0-0"}, {"fixed": "1.21.5"}
+			]}]
+		}]
+	}`,
+	"ID/GO-2023-0003.json": `{
+		"id": "GO-2023-0003",
+		"withdrawn": "2023-06-01T00:00:00Z",
+		"affected": [{"package": {"name": "example.com/a", "ecosystem": "Go"}}]
+	}`,
+	"ID/GO-2023-0004.json": `{
+		"id": "GO-2023-0004",
+		"summary": "Unfixed flaw in example.com/b",
+		"affected": [{
+			"package": {"name": "example.com/b", "ecosystem": "Go"},
+			"ranges": [{"type": "SEMVER", "events": [{"introduced": "0.3.0"}]}]
+		}]
+	}`,
+	"PYSEC-2023-1.json": `{
+		"id": "PYSEC-2023-1",
+		"affected": [{"package": {"name": "example.com/b", "ecosystem": "PyPI"}}]
+	}`,
+	"index/modules.json": `[{"path": "example.com/a"}]`,
+	"index/db.json":      `{"modified": "2023-06-01T00:00:00Z"}`,
+}
+
+func TestCheck(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vuln")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for name, data := range testEntries {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	db, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if db.Len() != 4 {
+		t.Errorf("Len() = %d; want 4", db.Len())
+	}
+
+	bi := &debug.BuildInfo{
+		GoVersion: "go1.21rc2 X:boringcrypto",
+		Main:      debug.Module{Path: "example.com/main", Version: "(devel)"},
+		Deps: []*debug.Module{
+			{Path: "example.com/a", Version: "v1.1.0"},
+			{Path: "example.com/b", Version: "v0.2.0", Replace: &debug.Module{Path: "example.com/b", Version: "v0.4.0"}},
+			{Path: "example.com/c", Version: "v1.0.0", Replace: &debug.Module{Path: "../c"}},
+		},
+	}
+	want := []Finding{
+		{ID: "GO-2023-0001", Aliases: []string{"CVE-2023-0001"}, Summary: "Panic in example.com/a", Module: "example.com/a", Version: "v1.1.0", Fixed: "v1.2.0"},
+		{ID: "GO-2023-0004", Summary: "Unfixed flaw in example.com/b", Module: "example.com/b", Version: "v0.4.0"},
+		{ID: "GO-2023-0002", Summary: "Request smuggling in net/http.", Module: "stdlib", Version: "v1.21.0-rc.2", Fixed: "v1.21.5"},
+	}
+	if got := db.Check(Modules(bi)); !reflect.DeepEqual(got, want) {
+		t.Errorf("Check() = %+v\nwant %+v", got, want)
+	}
+
+	for _, tt := range []struct {
+		path, version string
+		fixed         string
+		ok            bool
+	}{
+		{"example.com/a", "v1.2.0", "", false},
+		{"example.com/a", "v1.5.0", "v1.6.1", true},
+		{"example.com/a", "v1.6.1", "", false},
+		{"stdlib", "v1.20.11", "v1.20.12", true},
+		{"stdlib", "v1.20.12", "", false},
+		{"stdlib", "v1.21.5", "", false},
+		{"example.com/b", "v0.2.9", "", false},
+	} {
+		var fixed string
+		ok := false
+		for _, e := range db.entries[tt.path] {
+			if f, o := e.affects(tt.path, tt.version); o {
+				fixed, ok = f, true
+			}
+		}
+		if fixed != tt.fixed || ok != tt.ok {
+			t.Errorf("%s@%s: affected %v, fixed %q; want %v, %q", tt.path, tt.version, ok, fixed, tt.ok, tt.fixed)
+		}
+	}
+}
+
+func TestGoVersion(t *testing.T) {
+	for in, want := range map[string]string{
+		"go1.21.3":                    "v1.21.3",
+		"go1.21":                      "v1.21.0",
+		"go1.9":                       "v1.9.0",
+		"go1.22rc1":                   "v1.22.0-rc.1",
+		"go1.18beta2":                 "v1.18.0-beta.2",
+		"go1.20.1 X:boringcrypto":     "v1.20.1",
+		"devel go1.22-a1b2c3d4 +0000": "",
+	} {
+		if got := GoVersion(in); got != want {
+			t.Errorf("GoVersion(%q) = %q; want %q", in, got, want)
+		}
+	}
+}
+
+func TestLoadEmpty(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vuln")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if _, err := Load(dir); err == nil {
+		t.Error("Load(empty directory) succeeded")
+	}
+}
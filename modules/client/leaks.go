@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/wgliang/opengacm/modules/client/internal/goroutine"
+)
+
+var (
+	cleaksInterval = cleaks.Flag("interval", "Time between two goroutine dumps.").Default("30s").Duration()
+	cleaksSamples  = cleaks.Flag("samples", "Number of goroutine dumps to take.").Default("5").Int()
+	cleaksMin      = cleaks.Flag("min", "Minimum growth of a stack to be reported.").Default("1").Int()
+	cleaksGrowths  = cleaks.Flag("growths", "Minimum number of intervals between dumps a stack must grow in, so that a single burst is not reported.").Default("2").Int()
+)
+
+// leaks takes repeated goroutine dumps from the agent and reports the
+// stacks whose goroutine count never decreases and grows in several
+// intervals, with their creation sites.
+func leaks(addr net.TCPAddr) error {
+	if *cleaksSamples < 2 {
+		return fmt.Errorf("at least 2 samples are needed, got %d", *cleaksSamples)
+	}
+	if *cleaksGrowths < 1 || *cleaksGrowths > *cleaksSamples-1 {
+		return fmt.Errorf("--growths must be between 1 and the %d intervals between the samples", *cleaksSamples-1)
+	}
+	fmt.Printf("Taking %d goroutine dumps, %v apart...\n", *cleaksSamples, *cleaksInterval)
+	samples := make([][]*goroutine.Group, 0, *cleaksSamples)
+	for i := 0; i < *cleaksSamples; i++ {
+		if i > 0 {
+			time.Sleep(*cleaksInterval)
+		}
+		groups, err := goroutineGroups(addr, &goroutine.Filter{})
+		if err != nil {
+			return err
+		}
+		total := 0
+		for _, gr := range groups {
+			total += gr.Count
+		}
+		fmt.Printf("sample %d: %d goroutines\n", i+1, total)
+		samples = append(samples, groups)
+	}
+
+	growths := goroutine.Leaks(samples, *cleaksMin, *cleaksGrowths)
+	if len(growths) == 0 {
+		fmt.Println("\nNo steadily growing goroutines found.")
+		return nil
+	}
+	for _, g := range growths {
+		fmt.Printf("\n+%d goroutines %v\n", g.Delta(), g.Counts)
+		if c := g.Group.CreatedBy; c != nil {
+			fmt.Printf("    created by %s\n        %s:%d\n", c.Func, c.File, c.Line)
+		}
+		for _, f := range g.Group.Frames {
+			fmt.Printf("    %s\n        %s:%d\n", f.Func, f.File, f.Line)
+		}
+	}
+	return nil
+}
@@ -46,8 +46,45 @@ var (
 	cpprofCPU  = client.Command("pprof-cpu", `Reads the CPU profile and launches "go tool pprof".`)
 	cstats     = client.Command("stats", "Prints the vital runtime stats.")
 	ctrace     = client.Command("trace", `Runs the runtime tracer for 5 secs and launches "go tool trace".`)
+	cleaks     = client.Command("leaks", "Samples the goroutine dump repeatedly and reports the stacks that grow across several dumps without shrinking.")
+	cbugreport = client.Command("bugreport", "Collects stacks, stats, profiles, a trace, the binary and process info into one archive.")
+	cpprofDiff = client.Command("pprof-diff", "Prints the per-function difference between two heap or CPU profiles.")
+	cflame     = client.Command("flamegraph", "Renders a CPU or heap profile as a standalone flame graph.")
+	ctop       = client.Command("top", "Shows a live view of the Go processes and their runtime stats.")
+	cprocs     = client.Command("processes", "Lists the Go processes of the host.")
+	cinspect   = client.Command("inspect", "Prints the build information of a Go binary or process, without an agent.")
+	csize      = client.Command("size", "Breaks the size of a Go binary down by package and symbol.")
+	cbindiff   = client.Command("bindiff", "Compares the functions and dependencies of two builds of a Go binary.")
+	cdisasm    = client.Command("disasm", "Disassembles the functions of a Go binary or process.")
+	caddr2line = client.Command("addr2line", "Resolves program counters of a Go binary or process to functions, files and lines.")
+	caudit     = client.Command("audit-binary", "Reports the exploit mitigations and build options of a Go binary, against an optional policy; exits with status 1 on deviations.")
+	cvulncheck = client.Command("vulncheck", "Reports the known vulnerabilities of the modules linked in a Go binary, from a local OSV database; exits with status 1 on findings.")
+	ctypes     = client.Command("types", "Prints the layout of the structs of a Go binary, with the padding between their fields.")
+	cglobals   = client.Command("globals", "Lists the package-level variables of a Go binary with their address, size and type.")
+	cpeek      = client.Command("peek", "Prints the value of a package-level variable of a Go process, read from its memory.")
+	ccore      = client.Command("core", "Prints the goroutines and the heap statistics of a crashed Go process from its core dump.")
+	cannotate  = client.Command("annotate", "Prints the instructions of the hottest functions of a CPU profile with their samples and source lines.")
+	csample    = client.Command("sample", `Profiles the CPU of a Go process without agent by sampling its stacks, and launches "go tool pprof".`)
 )
 
+// target is the PID or host:port of the agent the diagnostic commands talk to.
+var target string
+
+func init() {
+	for _, c := range []*kingpin.CmdClause{cstack, cgc, cmemstats, cversion, cpprofHeap, cpprofCPU, cstats, ctrace, cleaks, cbugreport} {
+		c.Arg("target", "PID or host:port of the agent.").StringVar(&target)
+	}
+}
+
+// commandArgs returns the arguments expected by command, with the target
+// in third position wherever it appeared among the flags.
+func commandArgs() []string {
+	if target == "" {
+		return os.Args[:2]
+	}
+	return []string{os.Args[0], os.Args[1], target}
+}
+
 // showVersion is a function that get the version information.
 func showVersion() {
 	fmt.Printf("%s version: %s, commit: %s\n", Name, Version, Commit)
@@ -63,7 +100,7 @@ func main() {
 		processes()
 		return
 	}
-	switch kingpin.MustParse(client.Parse(os.Args[1:2])) {
+	switch kingpin.MustParse(client.Parse(os.Args[1:])) {
 	case start.FullCommand():
 		daemon := NewClientDaemon()
 		daemon.startDaemon()
@@ -74,21 +111,93 @@ func main() {
 	case login.FullCommand():
 		manageApplications()
 	case cstack.FullCommand():
-		command(os.Args, stackTrace)
+		command(commandArgs(), stackTrace)
 	case cgc.FullCommand():
-		command(os.Args, gc)
+		command(commandArgs(), gc)
 	case cmemstats.FullCommand():
-		command(os.Args, memStats)
+		command(commandArgs(), memStats)
 	case cversion.FullCommand():
-		command(os.Args, goVersion)
+		command(commandArgs(), goVersion)
 	case cpprofHeap.FullCommand():
-		command(os.Args, pprofHeap)
+		command(commandArgs(), pprofHeap)
 	case cpprofCPU.FullCommand():
-		command(os.Args, pprofCPU)
+		command(commandArgs(), pprofCPU)
 	case cstats.FullCommand():
-		command(os.Args, stats)
+		command(commandArgs(), stats)
 	case ctrace.FullCommand():
-		command(os.Args, trace)
+		command(commandArgs(), trace)
+	case cleaks.FullCommand():
+		command(commandArgs(), leaks)
+	case cbugreport.FullCommand():
+		command(commandArgs(), bugreport)
+	case cpprofDiff.FullCommand():
+		if err := pprofDiff(); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+		}
+	case cflame.FullCommand():
+		if err := flameGraph(); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+		}
+	case ctop.FullCommand():
+		if err := top(); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+		}
+	case cprocs.FullCommand():
+		processes()
+	case cinspect.FullCommand():
+		if err := inspect(); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+		}
+	case csize.FullCommand():
+		if err := size(); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+		}
+	case cbindiff.FullCommand():
+		if err := bindiff(); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+		}
+	case cdisasm.FullCommand():
+		if err := disasm(); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+		}
+	case caddr2line.FullCommand():
+		if err := addr2line(); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+		}
+	case caudit.FullCommand():
+		if err := audit(); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+	case cvulncheck.FullCommand():
+		if err := vulncheck(); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+	case ctypes.FullCommand():
+		if err := types(); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+		}
+	case cglobals.FullCommand():
+		if err := globals(); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+		}
+	case cpeek.FullCommand():
+		if err := peek(); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+		}
+	case ccore.FullCommand():
+		if err := coreDump(); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+		}
+	case cannotate.FullCommand():
+		if err := annotate(); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+		}
+	case csample.FullCommand():
+		if err := sampleCPU(); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+		}
 	case version.FullCommand():
 		showVersion()
 	case info.FullCommand():
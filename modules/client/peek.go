@@ -0,0 +1,128 @@
+package main
+
+import (
+	"debug/dwarf"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/wgliang/opengacm/modules/client/internal/debuginfo"
+	"github.com/wgliang/opengacm/modules/client/internal/objfile"
+	"github.com/wgliang/opengacm/modules/client/internal/proc"
+)
+
+var (
+	cpeekPID   = cpeek.Arg("pid", "PID of a running Go process.").Required().Int()
+	cpeekVar   = cpeek.Arg("variable", "Package-level variable, such as main.config or net/http.DefaultClient.").Required().String()
+	cpeekDepth = cpeek.Flag("depth", "Number of pointers to follow.").Default("3").Int()
+	cpeekLimit = cpeek.Flag("limit", "Number of elements to print of arrays, slices and maps.").Default("32").Int()
+)
+
+// peek prints the value of a package-level variable of a running Go
+// process, read from its memory without an agent nor stopping it.
+func peek() error {
+	pid := *cpeekPID
+	path := proc.Path(pid, "exe")
+	f, err := objfile.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	syms, err := f.Symbols()
+	if err != nil {
+		return fmt.Errorf("process %d: %v", pid, err)
+	}
+	sym, err := lookupVar(syms, *cpeekVar)
+	if err != nil {
+		return err
+	}
+	offset, err := loadOffset(pid, f)
+	if err != nil {
+		return err
+	}
+	mem, err := os.Open(proc.Path(pid, "mem"))
+	if err != nil {
+		return fmt.Errorf("reading the memory of process %d requires ptrace access: %v", pid, err)
+	}
+	defer mem.Close()
+	addr := sym.Addr + offset
+
+	// Without DWARF information, the type is unknown.
+	t, d := varType(f, sym.Name)
+	if t == nil {
+		b := make([]byte, sym.Size)
+		if _, err := mem.ReadAt(b, int64(addr)); err != nil {
+			return fmt.Errorf("reading %s at %#x: %v", sym.Name, addr, err)
+		}
+		fmt.Printf("%s at %#x, %d bytes (no DWARF type information):\n%s", sym.Name, addr, sym.Size, hex.Dump(b))
+		return nil
+	}
+
+	var types uint64
+	for _, s := range syms {
+		if s.Name == "runtime.types" {
+			types = s.Addr + offset
+		}
+	}
+	p, err := debuginfo.NewPrinter(d, mem, types)
+	if err != nil {
+		return err
+	}
+	p.MaxDepth, p.MaxElems = *cpeekDepth, *cpeekLimit
+	if tab, err := f.PCLineTable(); err == nil {
+		p.FuncName = func(pc uint64) string {
+			if _, _, fn := tab.PCToLine(pc - offset); fn != nil {
+				return fn.Name
+			}
+			return ""
+		}
+	}
+	fmt.Printf("%s %s = ", sym.Name, debuginfo.TypeName(t))
+	return p.Fprint(os.Stdout, t, addr)
+}
+
+// lookupVar returns the symbol of the package-level variable name. A
+// name without the path of its package, such as http.DefaultClient,
+// matches the symbol with the path if there is only one.
+func lookupVar(syms []objfile.Sym, name string) (objfile.Sym, error) {
+	var matches []objfile.Sym
+	for _, s := range syms {
+		if !strings.ContainsRune("DdBbRr", s.Code) {
+			continue
+		}
+		if s.Name == name {
+			return s, nil
+		}
+		if strings.HasSuffix(s.Name, "/"+name) {
+			matches = append(matches, s)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return objfile.Sym{}, fmt.Errorf("no variable %s", name)
+	case 1:
+		return matches[0], nil
+	}
+	names := make([]string, len(matches))
+	for i, s := range matches {
+		names[i] = strconv.Quote(s.Name)
+	}
+	return objfile.Sym{}, fmt.Errorf("%s is ambiguous: %s", name, strings.Join(names, ", "))
+}
+
+// varType returns the DWARF type of the package-level variable name, or
+// nil if f has no DWARF information about it.
+func varType(f *objfile.File, name string) (dwarf.Type, *dwarf.Data) {
+	d, err := f.DWARF()
+	if err != nil {
+		return nil, nil
+	}
+	vars, err := debuginfo.Globals(d, regexp.MustCompile("^"+regexp.QuoteMeta(name)+"$"))
+	if err != nil || len(vars) == 0 {
+		return nil, nil
+	}
+	return vars[0].Type, d
+}
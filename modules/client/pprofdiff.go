@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"github.com/wgliang/opengacm/modules/client/internal/profile"
+	"github.com/wgliang/opengacm/modules/client/internal/report"
+	"github.com/wgliang/opengacm/modules/client/signal"
+)
+
+var (
+	cpprofDiffBase      = cpprofDiff.Arg("base", "Base profile.").String()
+	cpprofDiffNew       = cpprofDiff.Arg("new", "New profile.").String()
+	cpprofDiffSample    = cpprofDiff.Flag("sample", "Sample type to compare, e.g. alloc_space; defaults to the profile default.").String()
+	cpprofDiffTop       = cpprofDiff.Flag("top", "Number of functions to print, 0 for all.").Default("20").Int()
+	cpprofDiffCum       = cpprofDiff.Flag("cum", "Sort by cumulative instead of flat delta.").Bool()
+	cpprofDiffNormalize = cpprofDiff.Flag("normalize", "Scale the base profile to the total of the new one.").Bool()
+	cpprofDiffLive      = cpprofDiff.Flag("live", "Capture both profiles from the agent at PID or host:port instead of reading files.").String()
+	cpprofDiffProfile   = cpprofDiff.Flag("profile", "Profile to capture with --live.").Default("heap").Enum("heap", "cpu")
+	cpprofDiffInterval  = cpprofDiff.Flag("interval", "Time between the two captures with --live.").Default("1m").Duration()
+	cpprofDiffSeconds   = cpprofDiff.Flag("seconds", "Duration of each CPU profile with --live.").Default("30").Uint8()
+)
+
+// pprofDiff prints the per-function difference between two profiles,
+// read from files or captured from a live agent.
+func pprofDiff() error {
+	var base, cur *profile.Profile
+	if *cpprofDiffLive != "" {
+		addr, err := targetToAddr(*cpprofDiffLive)
+		if err != nil {
+			return fmt.Errorf("couldn't resolve addr or pid %v to TCPAddress: %v", *cpprofDiffLive, err)
+		}
+		if base, err = captureProfile(*addr); err != nil {
+			return err
+		}
+		fmt.Printf("Waiting %v before the second capture...\n", *cpprofDiffInterval)
+		time.Sleep(*cpprofDiffInterval)
+		if cur, err = captureProfile(*addr); err != nil {
+			return err
+		}
+	} else {
+		if *cpprofDiffBase == "" || *cpprofDiffNew == "" {
+			return errors.New("two profiles or --live are required")
+		}
+		var err error
+		if base, err = readProfile(*cpprofDiffBase); err != nil {
+			return err
+		}
+		if cur, err = readProfile(*cpprofDiffNew); err != nil {
+			return err
+		}
+	}
+	d, err := report.Compare(base, cur, *cpprofDiffSample, *cpprofDiffNormalize)
+	if err != nil {
+		return err
+	}
+	d.Sort(*cpprofDiffCum)
+	d.Print(os.Stdout, *cpprofDiffTop)
+	return nil
+}
+
+func readProfile(path string) (*profile.Profile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	p, err := profile.Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return p, nil
+}
+
+func captureProfile(addr net.TCPAddr) (*profile.Profile, error) {
+	var (
+		out []byte
+		err error
+	)
+	if *cpprofDiffProfile == "cpu" {
+		fmt.Printf("Profiling CPU now, will take %d secs...\n", *cpprofDiffSeconds)
+		out, err = cmd(addr, signal.CPUProfileFor, *cpprofDiffSeconds)
+	} else {
+		out, err = cmd(addr, signal.HeapProfile)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(out) == 0 {
+		return nil, errors.New("failed to read the profile")
+	}
+	return profile.Parse(bytes.NewReader(out))
+}
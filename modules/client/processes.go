@@ -0,0 +1,308 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+	"runtime"
+	"sort"
+	"strconv"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	gapmdaemon "github.com/wgliang/opengacm/modules/client/controller/daemon"
+	"github.com/wgliang/opengacm/modules/client/internal/discovery"
+	"github.com/wgliang/opengacm/modules/client/internal/proc"
+
+	ps "github.com/keybase/go-ps"
+)
+
+var (
+	cprocsFormat = cprocs.Flag("format", "Output format.").Short('f').Default("table").Enum("table", "tree", "json")
+)
+
+// goProcess is a running Go process found on the host.
+type goProcess struct {
+	PID              int       `json:"pid"`
+	PPID             int       `json:"ppid"`
+	User             string    `json:"user,omitempty"`
+	Start            time.Time `json:"start"`
+	RSS              uint64    `json:"rss"`
+	Exe              string    `json:"exe"`
+	Path             string    `json:"path"`
+	GoVersion        string    `json:"go_version,omitempty"`
+	Agent            bool      `json:"agent"`
+	AgentPort        string    `json:"agent_port,omitempty"`
+	AgentUnreachable bool      `json:"agent_unreachable,omitempty"` // the agent listens in another network namespace
+	Managed          string    `json:"managed,omitempty"`           // name of the application in the local daemon
+	Cgroup           string    `json:"cgroup,omitempty"`
+	Container        string    `json:"container,omitempty"` // ID of the container running the process
+}
+
+// discoveryCache remembers which executables are Go binaries across scans,
+// it is opened on the first scan.
+var (
+	discoveryOnce  sync.Once
+	discoveryCache *discovery.Cache
+)
+
+// goProcesses returns the running Go processes, sorted by PID.
+// The processes are inspected by a bounded number of workers.
+func goProcesses() ([]goProcess, error) {
+	pss, err := ps.Processes()
+	if err != nil {
+		return nil, err
+	}
+	discoveryOnce.Do(func() {
+		path, _ := discovery.DefaultCachePath()
+		discoveryCache = discovery.OpenCache(path)
+	})
+
+	var (
+		mu    sync.Mutex
+		found []goProcess
+		wg    sync.WaitGroup
+		jobs  = make(chan ps.Process)
+	)
+	for i := 0; i < runtime.NumCPU(); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for pr := range jobs {
+				if p, ok := findGo(pr); ok {
+					mu.Lock()
+					found = append(found, p)
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	for _, pr := range pss {
+		jobs <- pr
+	}
+	close(jobs)
+	managed := managedApplications()
+	wg.Wait()
+	discoveryCache.Save()
+	for i := range found {
+		found[i].Managed = managed[found[i].PID]
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].PID < found[j].PID })
+	return found, nil
+}
+
+// processes lists the Go processes of the host in the format of the
+// --format flag, a table by default.
+func processes() {
+	pss, err := goProcesses()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return
+	}
+	switch *cprocsFormat {
+	case "json":
+		if pss == nil {
+			pss = []goProcess{}
+		}
+		printJSON(pss)
+	case "tree":
+		printProcessTree(os.Stdout, pss)
+	default:
+		printProcessTable(os.Stdout, pss)
+	}
+}
+
+// agent returns the port of the agent of p, "unreachable" if it has one
+// out of reach, or an empty string if it has none.
+func (p goProcess) agent() string {
+	if p.AgentUnreachable {
+		return "unreachable"
+	}
+	return p.AgentPort
+}
+
+func printProcessTable(w io.Writer, pss []goProcess) {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "PID\tPPID\tUSER\tSTART\tRSS\tGO\tAGENT\tMANAGED\tCONTAINER\tEXE\tPATH")
+	for _, p := range pss {
+		fmt.Fprintf(tw, "%d\t%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			p.PID, p.PPID, orDash(p.User), startTime(p.Start), shortBytes(p.RSS),
+			orDash(p.GoVersion), orDash(p.agent()), orDash(p.Managed), orDash(shortID(p.Container)), p.Exe, p.Path)
+	}
+	tw.Flush()
+}
+
+// printProcessTree prints the processes indented under their parent.
+// Processes whose parent is not a Go process are printed at the top level.
+func printProcessTree(w io.Writer, pss []goProcess) {
+	children := make(map[int][]goProcess)
+	pids := make(map[int]bool)
+	for _, p := range pss {
+		pids[p.PID] = true
+	}
+	var roots []goProcess
+	for _, p := range pss {
+		if pids[p.PPID] && p.PPID != p.PID {
+			children[p.PPID] = append(children[p.PPID], p)
+			continue
+		}
+		roots = append(roots, p)
+	}
+	var walk func(p goProcess, prefix, branch string)
+	walk = func(p goProcess, prefix, branch string) {
+		fmt.Fprintf(w, "%s%s%d %s", prefix, branch, p.PID, p.Exe)
+		if p.GoVersion != "" {
+			fmt.Fprintf(w, " (%s)", p.GoVersion)
+		}
+		if agent := p.agent(); agent != "" {
+			fmt.Fprintf(w, " agent:%s", agent)
+		}
+		if p.Managed != "" {
+			fmt.Fprintf(w, " managed:%s", p.Managed)
+		}
+		if p.Container != "" {
+			fmt.Fprintf(w, " container:%s", shortID(p.Container))
+		}
+		fmt.Fprintln(w)
+		switch branch {
+		case "├─ ":
+			prefix += "│  "
+		case "└─ ":
+			prefix += "   "
+		}
+		kids := children[p.PID]
+		for i, c := range kids {
+			if i == len(kids)-1 {
+				walk(c, prefix, "└─ ")
+			} else {
+				walk(c, prefix, "├─ ")
+			}
+		}
+	}
+	for _, p := range roots {
+		walk(p, "", "")
+	}
+}
+
+// startTime formats the start time of a process like ps does,
+// with the time of day for processes started today.
+func startTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	now := time.Now()
+	if t.YearDay() == now.YearDay() && t.Year() == now.Year() {
+		return t.Format("15:04")
+	}
+	return t.Format("Jan02")
+}
+
+// shortID abbreviates a container ID the way the docker command does.
+func shortID(id string) string {
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
+}
+
+// printJSON prints v as indented JSON on the standard output.
+func printJSON(v interface{}) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// managedApplications returns the names of the applications run by the
+// local opengacm-client daemon, by PID. It returns nil if the daemon is
+// not running.
+func managedApplications() map[int]string {
+	client, err := gapmdaemon.StartRemoteClient("127.0.0.1"+defaultServerAddr, time.Second)
+	if err != nil {
+		return nil
+	}
+	defer client.Close()
+	done := make(chan map[int]string, 1)
+	go func() {
+		resp, err := client.MonitStatus()
+		if err != nil {
+			done <- nil
+			return
+		}
+		apps := make(map[int]string)
+		for _, app := range resp.Applications {
+			if app.Pid > 0 {
+				apps[app.Pid] = app.Name
+			}
+		}
+		done <- apps
+	}()
+	select {
+	case apps := <-done:
+		return apps
+	case <-time.After(time.Second):
+		return nil
+	}
+}
+
+// findGo determines if the process is a Go process from the build
+// information of its binary, which is cached by device, inode and
+// modification time. The binary is read through /proc/<pid>/exe, which
+// also works for the processes of containers and for deleted binaries.
+// If the process is a Go process, it reports PID, binary name and full
+// path of the binary, along with the process information that is available.
+func findGo(pr ps.Process) (goProcess, bool) {
+	if pr.Pid() == 0 {
+		// ignore system process
+		return goProcess{}, false
+	}
+	path, err := pr.Path()
+	if err != nil {
+		return goProcess{}, false
+	}
+	bin, err := discoveryCache.Lookup(proc.Path(pr.Pid(), "exe"))
+	if os.IsNotExist(err) {
+		// No proc file system.
+		bin, err = discoveryCache.Lookup(path)
+	}
+	if err != nil || !bin.Go {
+		return goProcess{}, false
+	}
+
+	p := goProcess{
+		PID:       pr.Pid(),
+		PPID:      pr.PPid(),
+		Exe:       pr.Executable(),
+		Path:      path,
+		GoVersion: bin.GoVersion,
+	}
+	switch port, err := discovery.AgentPort(p.PID); {
+	case err == nil:
+		p.Agent = true
+		p.AgentPort = port
+	case err == discovery.ErrUnreachable:
+		p.AgentUnreachable = true
+	}
+	if info, err := proc.Read(p.PID); err == nil {
+		p.Start = info.Start
+		p.RSS = info.RSS
+		p.User = strconv.Itoa(info.UID)
+		if u, err := user.LookupId(p.User); err == nil {
+			p.User = u.Username
+		}
+	}
+	if cgroup, err := proc.Cgroup(p.PID); err == nil {
+		p.Cgroup = cgroup
+		p.Container = proc.ContainerID(cgroup)
+	}
+	return p, true
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wgliang/opengacm/modules/client/internal/objfile"
+	"github.com/wgliang/opengacm/modules/client/internal/proc"
+	"github.com/wgliang/opengacm/modules/client/internal/report"
+	"github.com/wgliang/opengacm/modules/client/internal/sample"
+)
+
+var (
+	csamplePID     = csample.Arg("pid", "PID of a running Go process.").Required().Int()
+	csampleSeconds = csample.Flag("seconds", "Duration of the profile, in seconds.").Default("10").Int()
+	csampleHz      = csample.Flag("hz", "Number of samples per second.").Default("100").Int()
+)
+
+// sampleCPU writes the CPU profile of a Go process that doesn't embed the
+// agent, made of the stacks of its running threads stopped periodically
+// with ptrace, and launches "go tool pprof" on it like pprof-cpu.
+func sampleCPU() error {
+	pid := *csamplePID
+	if *csampleSeconds <= 0 || *csampleHz <= 0 || *csampleHz > 1000 {
+		return fmt.Errorf("--seconds must be positive and --hz between 1 and 1000")
+	}
+	path := proc.Path(pid, "exe")
+	exe, err := os.Readlink(path)
+	if err != nil {
+		return err
+	}
+	exe = strings.TrimSuffix(exe, " (deleted)")
+	// Read the binary now, the process may be gone once sampled.
+	bin, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	f, err := objfile.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	maps, err := proc.Maps(pid)
+	if err != nil {
+		return err
+	}
+	// Without the frame table of Go 1.18 and later binaries, the stacks
+	// are unwound along the frame pointers only.
+	u := &sample.Unwinder{Arch: f.GOARCH()}
+	if tab, err := f.FrameTable(); err == nil {
+		if u.Offset, err = loadOffset(pid, f); err != nil {
+			return err
+		}
+		u.Frames = tab
+	}
+
+	d := time.Duration(*csampleSeconds) * time.Second
+	fmt.Printf("Sampling process %d now, will take %d secs...\n", pid, *csampleSeconds)
+	stamp := time.Now()
+	stacks, err := sample.Sample(pid, d, *csampleHz, u)
+	if err != nil {
+		return err
+	}
+	if len(stacks) == 0 {
+		return fmt.Errorf("process %d didn't run during the %v", pid, d)
+	}
+	p := sample.Build(stacks, time.Second/time.Duration(*csampleHz), d, maps, exe)
+	if err := report.Symbolize(p, f); err != nil {
+		return fmt.Errorf("symbolizing the profile: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := p.Write(&buf); err != nil {
+		return err
+	}
+
+	// Artifacts are named after the process, like those of the agent.
+	source := strconv.Itoa(pid)
+	dumpFile, cleanup, err := saveArtifact(buf.Bytes(), source, csample.FullCommand(), ".pb.gz", stamp)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	binFile, cleanup, err := saveArtifact(bin, source, csample.FullCommand(), ".bin", stamp)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	fmt.Printf("Profiling dump saved to: %s\n", dumpFile)
+	fmt.Printf("Binary file saved to: %s\n", binFile)
+	if noLaunch {
+		return nil
+	}
+	cmd := exec.Command("go", "tool", "pprof", binFile, dumpFile)
+	cmd.Env = os.Environ()
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
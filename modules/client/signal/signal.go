@@ -32,4 +32,8 @@ const (
 
 	// BinaryDump returns running binary file.
 	BinaryDump = byte(0x9)
+
+	// CPUProfileFor returns a CPU profile taken for the number of seconds
+	// given in the byte that follows the command.
+	CPUProfileFor = byte(0xa)
 )
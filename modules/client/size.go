@@ -0,0 +1,146 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/wgliang/opengacm/modules/client/internal/objfile"
+	"github.com/wgliang/opengacm/modules/client/internal/symsize"
+)
+
+var (
+	csizeBinary  = csize.Arg("binary", "Go binary, or the older binary with --diff.").Required().String()
+	csizeNew     = csize.Arg("new", "Newer binary compared with --diff.").String()
+	csizeDiff    = csize.Flag("diff", "Print the size changes from the first binary to the second.").Bool()
+	csizeSymbols = csize.Flag("symbols", "Number of the largest symbols listed under each package.").Default("3").Int()
+	csizeDepth   = csize.Flag("depth", "Maximum depth of the package tree, 0 for no limit.").Default("0").Int()
+	csizeTop     = csize.Flag("top", "Number of packages and symbols listed with --diff, 0 for all.").Default("20").Int()
+)
+
+// size prints the sizes of the packages and symbols of a binary as a
+// tree of import paths, or the size changes between two binaries.
+func size() error {
+	if *csizeDiff {
+		if *csizeNew == "" {
+			return errors.New("--diff needs two binaries")
+		}
+		base, err := readBreakdown(*csizeBinary)
+		if err != nil {
+			return err
+		}
+		cur, err := readBreakdown(*csizeNew)
+		if err != nil {
+			return err
+		}
+		printSizeDiff(os.Stdout, base, cur, *csizeTop)
+		return nil
+	}
+	b, err := readBreakdown(*csizeBinary)
+	if err != nil {
+		return err
+	}
+	if b.Stripped {
+		fmt.Println("The binary is stripped, only the functions of the pclntab are counted.")
+	}
+	printSizeTree(os.Stdout, b, *csizeSymbols, *csizeDepth)
+	return nil
+}
+
+func readBreakdown(path string) (*symsize.Breakdown, error) {
+	f, err := objfile.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	b, err := symsize.Read(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return b, nil
+}
+
+func printSizeTree(w io.Writer, b *symsize.Breakdown, symbols, depth int) {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "total\ttext\trodata\tdata\tbss\t\t")
+	row := func(s *symsize.Sizes, name string) {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t\t%s\n", sizeString(s.Total()),
+			sizeString(s[symsize.Text]), sizeString(s[symsize.RoData]),
+			sizeString(s[symsize.Data]), sizeString(s[symsize.BSS]), name)
+	}
+	row(&b.Sizes, "(all)")
+	var walk func(n *symsize.Node, level int)
+	walk = func(n *symsize.Node, level int) {
+		indent := strings.Repeat("  ", level)
+		row(&n.Sizes, indent+n.Name)
+		if p := n.Package; p != nil {
+			for i, s := range p.Symbols {
+				if i >= symbols {
+					break
+				}
+				fmt.Fprintf(tw, "%s\t\t\t\t\t\t%s  %s %s\n", sizeString(s.Size), indent, s.Name, s.Kind)
+			}
+		}
+		if depth > 0 && level+1 >= depth {
+			return
+		}
+		for _, c := range n.Children {
+			walk(c, level+1)
+		}
+	}
+	for _, c := range b.Tree().Children {
+		walk(c, 0)
+	}
+	tw.Flush()
+}
+
+func printSizeDiff(w io.Writer, base, cur *symsize.Breakdown, top int) {
+	delta := cur.Sizes.Total() - base.Sizes.Total()
+	fmt.Fprintf(w, "Total: old %s, new %s, delta %s%s\n",
+		sizeString(base.Sizes.Total()), sizeString(cur.Sizes.Total()), signedSize(delta), sizePercent(delta, base.Sizes.Total()))
+	pkgs, syms := symsize.Diff(base, cur)
+	for _, section := range []struct {
+		title  string
+		deltas []*symsize.Delta
+	}{{"Packages", pkgs}, {"Symbols", syms}} {
+		fmt.Fprintf(w, "\n%s:\n", section.title)
+		tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', tabwriter.AlignRight)
+		fmt.Fprintln(tw, "delta\told\tnew\t\t")
+		for i, d := range section.deltas {
+			if top > 0 && i >= top {
+				break
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t\t%s\n", signedSize(d.Total()),
+				sizeString(d.Old.Total()), sizeString(d.New.Total()), d.Name)
+		}
+		tw.Flush()
+	}
+}
+
+// sizeString formats a size in bytes, with a dash for zero.
+func sizeString(n int64) string {
+	switch {
+	case n == 0:
+		return "-"
+	case n < 0:
+		return "-" + shortBytes(uint64(-n))
+	}
+	return shortBytes(uint64(n))
+}
+
+func signedSize(n int64) string {
+	if n > 0 {
+		return "+" + sizeString(n)
+	}
+	return sizeString(n)
+}
+
+func sizePercent(delta, base int64) string {
+	if base == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" (%+.1f%%)", 100*float64(delta)/float64(base))
+}
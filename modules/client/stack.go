@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"os"
+	"regexp"
+
+	"github.com/wgliang/opengacm/modules/client/internal/goroutine"
+	"github.com/wgliang/opengacm/modules/client/signal"
+)
+
+var (
+	cstackRaw   = cstack.Flag("raw", "Print the goroutine dump as is.").Bool()
+	cstackJSON  = cstack.Flag("json", "Print the grouped goroutines as JSON.").Bool()
+	cstackFunc  = cstack.Flag("func", "Only show goroutines with a function matching the regexp.").String()
+	cstackState = cstack.Flag("state", `Only show goroutines whose state contains the string, e.g. "chan receive".`).String()
+)
+
+// stackTrace reads the goroutine dump of the agent and prints the goroutines
+// grouped by identical stacks, most frequent first.
+func stackTrace(addr net.TCPAddr) error {
+	if *cstackRaw {
+		return cmdWithPrint(addr, signal.StackTrace)
+	}
+	filter := &goroutine.Filter{State: *cstackState}
+	if *cstackFunc != "" {
+		re, err := regexp.Compile(*cstackFunc)
+		if err != nil {
+			return fmt.Errorf("invalid function filter: %v", err)
+		}
+		filter.Func = re
+	}
+	groups, err := goroutineGroups(addr, filter)
+	if err != nil {
+		return err
+	}
+	if *cstackJSON {
+		return printJSON(groups)
+	}
+	goroutine.Print(os.Stdout, groups)
+	return nil
+}
+
+// goroutineGroups reads the goroutine dump of the agent and groups the
+// goroutines selected by filter.
+func goroutineGroups(addr net.TCPAddr, filter *goroutine.Filter) ([]*goroutine.Group, error) {
+	out, err := cmd(addr, signal.StackTrace)
+	if err != nil {
+		return nil, err
+	}
+	gs, err := goroutine.Parse(bytes.NewReader(out))
+	if err != nil {
+		return nil, err
+	}
+	return goroutine.GroupBy(filter.Apply(gs)), nil
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/wgliang/opengacm/modules/client/internal/symbols"
+	"github.com/wgliang/opengacm/modules/client/signal"
+
+	"github.com/valyala/fasthttp"
+)
+
+// symbolsPath is the path binaries are uploaded to the symbol store at.
+const symbolsPath = "/gacmclient/symbols"
+
+// maxBinarySize bounds the size of the binaries uploaded to the symbol
+// store, the bodies of the other requests are bounded by the default of
+// fasthttp.
+const maxBinarySize = 1 << 30
+
+// requestConfig is the configuration of the requests of the daemon with
+// the header h: only the uploads of binaries have large bodies.
+func requestConfig(h *fasthttp.RequestHeader) fasthttp.RequestConfig {
+	// The size is set for every request, or it would be kept for the
+	// next ones on the connection.
+	conf := fasthttp.RequestConfig{MaxRequestBodySize: fasthttp.DefaultMaxRequestBodySize}
+	uri := h.RequestURI()
+	if i := bytes.IndexByte(uri, '?'); i >= 0 {
+		uri = uri[:i]
+	}
+	if h.IsPost() && string(uri) == symbolsPath {
+		conf.MaxRequestBodySize = maxBinarySize
+	}
+	return conf
+}
+
+// symbolStore returns the symbol store of the daemon, or replies with an
+// error if it has none.
+func (cd *ClientDaemon) symbolStore(ctx *fasthttp.RequestCtx) *symbols.Store {
+	if cd.Symbols == nil {
+		ctx.Error("Symbol store unavailable.", fasthttp.StatusServiceUnavailable)
+	}
+	return cd.Symbols
+}
+
+// lookupBinary returns the binary of the symbol store with the build ID of
+// the request path, or replies with an error if there is none.
+func (cd *ClientDaemon) lookupBinary(ctx *fasthttp.RequestCtx) (*symbols.Store, *symbols.Binary) {
+	store := cd.symbolStore(ctx)
+	if store == nil {
+		return nil, nil
+	}
+	// Go build IDs are made of slash-separated parts.
+	id := strings.TrimPrefix(fmt.Sprint(ctx.UserValue("buildid")), "/")
+	b := store.Lookup(id)
+	if b == nil {
+		ctx.Error(fmt.Sprintf("No binary with build ID %q.", id), fasthttp.StatusNotFound)
+	}
+	return store, b
+}
+
+// HandleSymbols lists the binaries of the symbol store as JSON.
+func (cd *ClientDaemon) HandleSymbols(ctx *fasthttp.RequestCtx) {
+	store := cd.symbolStore(ctx)
+	if store == nil {
+		return
+	}
+	replyJSON(ctx, store.Binaries())
+}
+
+// HandleAddSymbols adds a binary to the symbol store and replies with it
+// as JSON. The binary is the body of the request, or the one the agent of
+// the local process with the PID of the agent parameter returns for
+// signal.BinaryDump; the daemon doesn't connect to other hosts on behalf
+// of its clients. The name parameter names the binary in the store.
+func (cd *ClientDaemon) HandleAddSymbols(ctx *fasthttp.RequestCtx) {
+	store := cd.symbolStore(ctx)
+	if store == nil {
+		return
+	}
+	args := ctx.QueryArgs()
+	name := string(args.Peek("name"))
+	bin := ctx.PostBody()
+	if agent := string(args.Peek("agent")); agent != "" {
+		if _, err := strconv.Atoi(agent); err != nil {
+			ctx.Error(fmt.Sprintf("Agent %q is not the PID of a local process.", agent), fasthttp.StatusBadRequest)
+			return
+		}
+		addr, err := targetToAddr(agent)
+		if err != nil {
+			ctx.Error(err.Error(), fasthttp.StatusBadRequest)
+			return
+		}
+		if bin, err = cmd(*addr, signal.BinaryDump); err != nil {
+			ctx.Error(fmt.Sprintf("Failed to read the binary: %v", err), fasthttp.StatusBadGateway)
+			return
+		}
+		if name == "" {
+			name = agent
+		}
+	}
+	if len(bin) == 0 {
+		ctx.Error("No binary in the request.", fasthttp.StatusBadRequest)
+		return
+	}
+	b, err := store.Add(name, bytes.NewReader(bin))
+	if err != nil {
+		ctx.Error(err.Error(), fasthttp.StatusBadRequest)
+		return
+	}
+	replyJSON(ctx, b)
+}
+
+// HandleBinary serves the binary of the symbol store with the build ID of
+// the request path, for the Go tools to symbolize profiles with.
+func (cd *ClientDaemon) HandleBinary(ctx *fasthttp.RequestCtx) {
+	store, b := cd.lookupBinary(ctx)
+	if b == nil {
+		return
+	}
+	ctx.SetContentType("application/octet-stream")
+	ctx.SendFile(store.Path(b))
+}
+
+// HandleSymbolize resolves addresses in the binary of the symbol store
+// with the build ID of the request path. The addresses are separated by
+// "+" in the body of the request, which is answered with a line with the
+// address and the function of each address found. The start and offset
+// parameters are the ones of the mapping of the binary in the profile the
+// addresses are from; without them, addresses are link-time ones.
+//
+// The lines are the ones of the symbol handler of net/http/pprof, but
+// pprof doesn't query this handler: it only queries the one next to the
+// URL it fetched a profile from. Profiles are symbolized with the binary
+// served by HandleBinary instead.
+func (cd *ClientDaemon) HandleSymbolize(ctx *fasthttp.RequestCtx) {
+	store, b := cd.lookupBinary(ctx)
+	if b == nil {
+		return
+	}
+	ctx.SetContentType("text/plain; charset=utf-8")
+	var start, offset uint64
+	for _, p := range []struct {
+		name string
+		v    *uint64
+	}{{"start", &start}, {"offset", &offset}} {
+		if s := string(ctx.QueryArgs().Peek(p.name)); s != "" {
+			v, err := parseAddr(s)
+			if err != nil {
+				ctx.Error(err.Error(), fasthttp.StatusBadRequest)
+				return
+			}
+			*p.v = v
+		}
+	}
+	var addrs []uint64
+	for _, s := range strings.Split(string(ctx.PostBody()), "+") {
+		if s = strings.TrimSpace(s); s == "" {
+			continue
+		}
+		addr, err := parseAddr(s)
+		if err != nil {
+			ctx.Error(err.Error(), fasthttp.StatusBadRequest)
+			return
+		}
+		addrs = append(addrs, addr)
+	}
+	names, err := store.Symbolize(b, addrs, start, offset)
+	if err != nil {
+		ctx.Error(err.Error(), fasthttp.StatusInternalServerError)
+		return
+	}
+	for i, name := range names {
+		if name != "" {
+			fmt.Fprintf(ctx, "%#x %s\n", addrs[i], name)
+		}
+	}
+}
+
+func replyJSON(ctx *fasthttp.RequestCtx, v interface{}) {
+	ctx.SetContentType("application/json")
+	enc := json.NewEncoder(ctx)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		ctx.Error(err.Error(), fasthttp.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,492 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"os/exec"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/wgliang/opengacm/modules/client/internal/goroutine"
+	"github.com/wgliang/opengacm/modules/client/internal/proc"
+	gsignal "github.com/wgliang/opengacm/modules/client/signal"
+)
+
+var (
+	ctopInterval = ctop.Flag("interval", "Refresh interval.").Default("1s").Duration()
+	ctopSort     = ctop.Flag("sort", "Initial sort column.").Default("cpu").Enum("pid", "name", "cpu", "goroutines", "heap", "gc")
+)
+
+// topRow is the state of one process in the top view.
+type topRow struct {
+	proc       goProcess
+	cpu        float64       // CPU usage over the last interval, in percent
+	goroutines int           // -1 if unknown
+	heapInuse  uint64        // bytes
+	numGC      uint64        // total number of GC cycles
+	gcRate     float64       // GC cycles per second over the last interval
+	gcPause    time.Duration // GC pause over the last interval
+	pauseTotal time.Duration // total GC pause
+	ticks      uint64        // total CPU ticks
+	seen       time.Time     // time of the last sample
+	agentErr   error         // last error talking to the agent
+	detail     *topDetail    // last details fetched from the agent, if any
+}
+
+// topDetail is what the detail view shows of a process with an agent.
+type topDetail struct {
+	pid        int
+	memStats   []byte
+	goroutines int
+	groups     []*goroutine.Group
+	err        error
+}
+
+// topView is the interactive top-style view of the Go processes of the host.
+type topView struct {
+	rows     map[int]*topRow
+	order    []int
+	sortBy   string
+	selected int // PID of the selected process
+	detail   bool
+	message  string
+
+	// The details are fetched in the background, so that an agent that is
+	// slow to answer doesn't freeze the view.
+	details  chan *topDetail
+	fetching bool
+}
+
+// top shows a live view of the Go processes of the host, refreshed every
+// interval, with the runtime figures reported by their agents.
+func top() error {
+	restore, err := rawTerminal()
+	if err != nil {
+		return err
+	}
+	defer restore()
+	fmt.Print("\033[?25l")
+	defer fmt.Print("\033[?25h\033[0m\n")
+
+	v := &topView{rows: make(map[int]*topRow), sortBy: *ctopSort, details: make(chan *topDetail, 1)}
+	keys := make(chan string)
+	go readKeys(keys)
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(interrupt)
+
+	ticker := time.NewTicker(*ctopInterval)
+	defer ticker.Stop()
+	v.discover()
+	v.sample()
+	v.render()
+	for tick := 1; ; {
+		select {
+		case <-ticker.C:
+			// Discovery parses every binary, do it less often than sampling.
+			if tick%10 == 0 {
+				v.discover()
+			}
+			tick++
+			v.sample()
+			v.fetchDetail()
+		case k := <-keys:
+			if !v.key(k) {
+				return nil
+			}
+			v.fetchDetail()
+		case d := <-v.details:
+			v.fetching = false
+			if r, ok := v.rows[d.pid]; ok {
+				r.detail = d
+			}
+		case <-interrupt:
+			return nil
+		}
+		v.render()
+	}
+}
+
+// discover refreshes the list of Go processes.
+func (v *topView) discover() {
+	procs, err := goProcesses()
+	if err != nil {
+		v.message = err.Error()
+		return
+	}
+	alive := make(map[int]bool)
+	for _, p := range procs {
+		alive[p.PID] = true
+		if r, ok := v.rows[p.PID]; ok {
+			r.proc = p
+			continue
+		}
+		v.rows[p.PID] = &topRow{proc: p, goroutines: -1}
+	}
+	for pid := range v.rows {
+		if !alive[pid] {
+			delete(v.rows, pid)
+		}
+	}
+}
+
+// sample collects the figures of every process concurrently.
+func (v *topView) sample() {
+	var wg sync.WaitGroup
+	for _, r := range v.rows {
+		wg.Add(1)
+		go func(r *topRow) {
+			defer wg.Done()
+			r.sample(time.Now())
+		}(r)
+	}
+	wg.Wait()
+}
+
+func (r *topRow) sample(now time.Time) {
+	elapsed := now.Sub(r.seen).Seconds()
+	first := r.seen.IsZero()
+	r.seen = now
+
+	if ticks, err := proc.CPUTicks(r.proc.PID); err == nil {
+		if !first && elapsed > 0 {
+			r.cpu = float64(ticks-r.ticks) / proc.ClockTicks / elapsed * 100
+		}
+		r.ticks = ticks
+	}
+	if !r.proc.Agent {
+		return
+	}
+	addr, err := targetToAddr(strconv.Itoa(r.proc.PID))
+	if err != nil {
+		r.agentErr = err
+		return
+	}
+	out, err := cmdTimeout(*addr, gsignal.MemStats, time.Second)
+	if err != nil {
+		r.agentErr = err
+		return
+	}
+	mem := parseFields(out)
+	out, err = cmdTimeout(*addr, gsignal.Stats, time.Second)
+	if err != nil {
+		r.agentErr = err
+		return
+	}
+	stats := parseFields(out)
+	r.agentErr = nil
+
+	r.goroutines, _ = strconv.Atoi(stats["goroutines"])
+	r.heapInuse = byteField(mem["heap-in-use"])
+	numGC, _ := strconv.ParseUint(mem["num-gc"], 10, 64)
+	pause, _ := time.ParseDuration(mem["gc-pause"])
+	if !first && elapsed > 0 {
+		r.gcRate = float64(numGC-r.numGC) / elapsed
+		r.gcPause = pause - r.pauseTotal
+	}
+	r.numGC = numGC
+	r.pauseTotal = pause
+}
+
+// fetchDetail starts fetching the details of the selected process if they
+// are shown and no fetch is under way. They are sent to v.details.
+func (v *topView) fetchDetail() {
+	r, ok := v.rows[v.selected]
+	if !v.detail || v.fetching || !ok || !r.proc.Agent {
+		return
+	}
+	v.fetching = true
+	go func(pid int) {
+		v.details <- agentDetail(pid)
+	}(r.proc.PID)
+}
+
+// agentDetail fetches the memory statistics and the goroutines of the
+// process pid from its agent.
+func agentDetail(pid int) *topDetail {
+	d := &topDetail{pid: pid}
+	addr, err := targetToAddr(strconv.Itoa(pid))
+	if err != nil {
+		d.err = err
+		return d
+	}
+	if d.memStats, err = cmdTimeout(*addr, gsignal.MemStats, time.Second); err != nil {
+		d.err = err
+		return d
+	}
+	out, err := cmdTimeout(*addr, gsignal.StackTrace, time.Second)
+	if err != nil {
+		d.err = err
+		return d
+	}
+	gs, err := goroutine.Parse(bytes.NewReader(out))
+	if err != nil {
+		d.err = err
+		return d
+	}
+	d.goroutines = len(gs)
+	d.groups = goroutine.GroupBy(gs)
+	return d
+}
+
+// key handles a key press, and reports whether the view should keep running.
+func (v *topView) key(k string) bool {
+	if v.detail {
+		switch k {
+		case "q":
+			return false
+		case "\x1b", "\x7f", "b", "\r", "\n":
+			v.detail = false
+		}
+		return true
+	}
+	switch k {
+	case "q":
+		return false
+	case "\x1b[A", "k":
+		v.move(-1)
+	case "\x1b[B", "j":
+		v.move(1)
+	case "\r", "\n":
+		if _, ok := v.rows[v.selected]; ok {
+			v.detail = true
+		}
+	case "p":
+		v.sortBy = "pid"
+	case "n":
+		v.sortBy = "name"
+	case "c":
+		v.sortBy = "cpu"
+	case "g":
+		v.sortBy = "goroutines"
+	case "m":
+		v.sortBy = "heap"
+	case "G":
+		v.sortBy = "gc"
+	}
+	return true
+}
+
+// move moves the selection by delta rows in the current order.
+func (v *topView) move(delta int) {
+	if len(v.order) == 0 {
+		return
+	}
+	i := 0
+	for j, pid := range v.order {
+		if pid == v.selected {
+			i = j
+		}
+	}
+	i += delta
+	if i < 0 {
+		i = 0
+	}
+	if i >= len(v.order) {
+		i = len(v.order) - 1
+	}
+	v.selected = v.order[i]
+}
+
+func (v *topView) sortRows() []*topRow {
+	rows := make([]*topRow, 0, len(v.rows))
+	for _, r := range v.rows {
+		rows = append(rows, r)
+	}
+	less := map[string]func(a, b *topRow) bool{
+		"pid":        func(a, b *topRow) bool { return a.proc.PID < b.proc.PID },
+		"name":       func(a, b *topRow) bool { return a.proc.Exe < b.proc.Exe },
+		"cpu":        func(a, b *topRow) bool { return a.cpu > b.cpu },
+		"goroutines": func(a, b *topRow) bool { return a.goroutines > b.goroutines },
+		"heap":       func(a, b *topRow) bool { return a.heapInuse > b.heapInuse },
+		"gc":         func(a, b *topRow) bool { return a.gcRate > b.gcRate },
+	}[v.sortBy]
+	sort.SliceStable(rows, func(i, j int) bool {
+		if less(rows[i], rows[j]) {
+			return true
+		}
+		if less(rows[j], rows[i]) {
+			return false
+		}
+		return rows[i].proc.PID < rows[j].proc.PID
+	})
+	v.order = v.order[:0]
+	for _, r := range rows {
+		v.order = append(v.order, r.proc.PID)
+	}
+	if _, ok := v.rows[v.selected]; !ok && len(rows) > 0 {
+		v.selected = rows[0].proc.PID
+	}
+	return rows
+}
+
+func (v *topView) render() {
+	var buf bytes.Buffer
+	buf.WriteString("\033[H\033[2J")
+	rows := v.sortRows()
+	if v.detail {
+		if r, ok := v.rows[v.selected]; ok {
+			v.renderDetail(&buf, r)
+			buf.WriteTo(os.Stdout)
+			return
+		}
+		v.detail = false
+	}
+	fmt.Fprintf(&buf, "opengacm-client top - %s - %d Go processes, sorted by %s\n",
+		time.Now().Format("15:04:05"), len(rows), v.sortBy)
+	fmt.Fprintf(&buf, "q quit  j/k select  enter details  sort: p pid, n name, c cpu, g goroutines, m heap, G gc\n\n")
+	fmt.Fprintf(&buf, "\033[1m%-8s %-20s %7s %11s %12s %8s %7s %10s\033[0m\n",
+		"PID", "NAME", "CPU%", "GOROUTINES", "HEAP-INUSE", "NUM-GC", "GC/S", "GC-PAUSE")
+	for _, r := range rows {
+		if r.proc.PID == v.selected {
+			buf.WriteString("\033[7m")
+		}
+		name := r.proc.Exe
+		if len(name) > 20 {
+			name = name[:20]
+		}
+		fmt.Fprintf(&buf, "%-8d %-20s %7.1f ", r.proc.PID, name, r.cpu)
+		switch {
+		case !r.proc.Agent:
+			fmt.Fprintf(&buf, "%11s %12s %8s %7s %10s", "-", "-", "-", "-", "-")
+		case r.agentErr != nil:
+			fmt.Fprintf(&buf, "%-51s", "agent: "+r.agentErr.Error())
+		default:
+			fmt.Fprintf(&buf, "%11d %12s %8d %7.2f %10v",
+				r.goroutines, shortBytes(r.heapInuse), r.numGC, r.gcRate, r.gcPause)
+		}
+		buf.WriteString("\033[0m\n")
+	}
+	if v.message != "" {
+		fmt.Fprintf(&buf, "\n%s\n", v.message)
+	}
+	buf.WriteTo(os.Stdout)
+}
+
+func (v *topView) renderDetail(buf *bytes.Buffer, r *topRow) {
+	fmt.Fprintf(buf, "opengacm-client top - %d %s (%s)\n", r.proc.PID, r.proc.Exe, r.proc.Path)
+	fmt.Fprintf(buf, "esc/enter back  q quit\n\n")
+	if !r.proc.Agent {
+		fmt.Fprintf(buf, "CPU: %.1f%%\n\nNo agent is running in this process.\n", r.cpu)
+		return
+	}
+	d := r.detail
+	switch {
+	case d == nil:
+		fmt.Fprintf(buf, "Reading from the agent...\n")
+		return
+	case d.err != nil:
+		fmt.Fprintf(buf, "agent: %v\n", d.err)
+		return
+	}
+	fmt.Fprintf(buf, "\033[1mMemory\033[0m\n%s\n", d.memStats)
+	groups := d.groups
+	fmt.Fprintf(buf, "\033[1mGoroutines\033[0m: %d in %d groups\n", d.goroutines, len(groups))
+	for i, gr := range groups {
+		if i == 15 {
+			fmt.Fprintf(buf, "...\n")
+			break
+		}
+		fn := "?"
+		if len(gr.Frames) > 0 {
+			fn = gr.Frames[len(gr.Frames)-1].Func
+			if len(gr.Frames) > 1 {
+				fn = gr.Frames[0].Func + " <- " + fn
+			}
+		}
+		fmt.Fprintf(buf, "%7d  %-20s %s\n", gr.Count, gr.StateSummary(), fn)
+	}
+}
+
+// rawTerminal switches the terminal to unbuffered input without echo,
+// and returns a function restoring its previous state.
+func rawTerminal() (func(), error) {
+	stty := func(args ...string) (string, error) {
+		c := exec.Command("stty", args...)
+		c.Stdin = os.Stdin
+		out, err := c.Output()
+		return strings.TrimSpace(string(out)), err
+	}
+	state, err := stty("-g")
+	if err != nil {
+		return nil, fmt.Errorf("top needs a terminal: %v", err)
+	}
+	if _, err := stty("cbreak", "-echo"); err != nil {
+		return nil, err
+	}
+	return func() { stty(state) }, nil
+}
+
+// readKeys sends the keys typed on the terminal to keys.
+// Escape sequences such as arrow keys are sent as a single key.
+func readKeys(keys chan<- string) {
+	r := bufio.NewReader(os.Stdin)
+	buf := make([]byte, 8)
+	for {
+		n, err := r.Read(buf)
+		if err != nil {
+			return
+		}
+		keys <- string(buf[:n])
+	}
+}
+
+// cmdTimeout is like cmd, but gives up after timeout.
+func cmdTimeout(addr net.TCPAddr, c byte, timeout time.Duration) ([]byte, error) {
+	conn, err := net.DialTimeout("tcp", addr.String(), timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(timeout))
+	if _, err := conn.Write([]byte{c}); err != nil {
+		return nil, err
+	}
+	return ioutil.ReadAll(conn)
+}
+
+// parseFields parses the "key: value" lines printed by the agent.
+func parseFields(out []byte) map[string]string {
+	fields := make(map[string]string)
+	for _, line := range strings.Split(string(out), "\n") {
+		i := strings.Index(line, ": ")
+		if i < 0 {
+			continue
+		}
+		fields[line[:i]] = strings.TrimSpace(line[i+2:])
+	}
+	return fields
+}
+
+// byteField returns the number of bytes of a value formatted by the agent,
+// such as "1.50MB (1572864 bytes)" or "1023 bytes".
+func byteField(s string) uint64 {
+	if i := strings.LastIndex(s, "("); i >= 0 {
+		s = s[i+1:]
+	}
+	s = strings.TrimSuffix(strings.TrimSuffix(s, ")"), " bytes")
+	n, _ := strconv.ParseUint(s, 10, 64)
+	return n
+}
+
+// shortBytes formats n bytes in at most a few characters.
+func shortBytes(n uint64) string {
+	switch {
+	case n >= 1<<30:
+		return fmt.Sprintf("%.1fG", float64(n)/(1<<30))
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1fM", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1fK", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%dB", n)
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"debug/dwarf"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"text/tabwriter"
+
+	"github.com/wgliang/opengacm/modules/client/internal/debuginfo"
+	"github.com/wgliang/opengacm/modules/client/internal/objfile"
+)
+
+var (
+	ctypesTarget     = ctypes.Arg("binary", "Go binary, or PID of a running Go process.").Required().String()
+	ctypesRegexp     = ctypes.Arg("regexp", "Only list the structs whose name matches this regular expression.").String()
+	ctypesShrinkable = ctypes.Flag("shrinkable", "Only list the structs that reordering their fields would shrink.").Bool()
+)
+
+// types prints the layout of the structs of a Go binary: the offset and
+// size of their fields and the padding between them.
+func types() error {
+	re, err := compileFilter(*ctypesRegexp)
+	if err != nil {
+		return err
+	}
+	path, err := binaryPath(*ctypesTarget)
+	if err != nil {
+		return err
+	}
+	d, err := openDWARF(path)
+	if err != nil {
+		return err
+	}
+	ptrSize, err := debuginfo.PtrSize(d)
+	if err != nil {
+		return err
+	}
+	structs, err := debuginfo.Structs(d, re)
+	if err != nil {
+		return err
+	}
+	first := true
+	for _, st := range structs {
+		l := debuginfo.StructLayout(st, ptrSize)
+		if *ctypesShrinkable && l.Optimal == l.Size {
+			continue
+		}
+		if !first {
+			fmt.Println()
+		}
+		first = false
+		printLayout(os.Stdout, l)
+	}
+	return nil
+}
+
+// compileFilter compiles the optional regular expression of a command.
+func compileFilter(expr string) (*regexp.Regexp, error) {
+	if expr == "" {
+		return nil, nil
+	}
+	return regexp.Compile(expr)
+}
+
+// openDWARF returns the DWARF information of the binary at path.
+func openDWARF(path string) (*dwarf.Data, error) {
+	f, err := objfile.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	d, err := f.DWARF()
+	if err != nil {
+		return nil, fmt.Errorf("%s: no DWARF information: %v", path, err)
+	}
+	return d, nil
+}
+
+func printLayout(w io.Writer, l *debuginfo.Layout) {
+	fmt.Fprintf(w, "%s: size %d, align %d, padding %d", l.Name, l.Size, l.Align, l.Padding)
+	if l.Optimal < l.Size {
+		fmt.Fprintf(w, ", %d when reordered", l.Optimal)
+	}
+	fmt.Fprintln(w)
+	if len(l.Fields) == 0 {
+		return
+	}
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "  OFFSET\tSIZE\tFIELD\tTYPE")
+	for _, f := range l.Fields {
+		fmt.Fprintf(tw, "  %d\t%d\t%s\t%s\n", f.Offset, f.Size, f.Name, f.Type)
+		if f.Padding > 0 {
+			fmt.Fprintf(tw, "  \t%d\t(padding)\n", f.Padding)
+		}
+	}
+	tw.Flush()
+}
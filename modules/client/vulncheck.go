@@ -0,0 +1,138 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	gapmdaemon "github.com/wgliang/opengacm/modules/client/controller/daemon"
+	"github.com/wgliang/opengacm/modules/client/internal/vuln"
+)
+
+var (
+	cvulncheckTarget = cvulncheck.Arg("binary", "Go binary, or PID of a running Go process.").String()
+	cvulncheckDB     = cvulncheck.Flag("db", "Directory of the OSV vulnerability database.").Required().String()
+	cvulncheckAll    = cvulncheck.Flag("all", "Check the applications managed by the opengacm-client daemon instead.").Bool()
+	cvulncheckJSON   = cvulncheck.Flag("json", "Print the results as JSON.").Bool()
+)
+
+// vulncheck reports the known vulnerabilities of the modules and of the
+// standard library linked in a Go binary, or in the binaries of all the
+// managed applications, from a local OSV database.
+func vulncheck() error {
+	if *cvulncheckAll {
+		return vulncheckAll()
+	}
+	if *cvulncheckTarget == "" {
+		return fmt.Errorf("a binary or PID is required without --all")
+	}
+	db, err := vuln.Load(*cvulncheckDB)
+	if err != nil {
+		return err
+	}
+	path, err := binaryPath(*cvulncheckTarget)
+	if err != nil {
+		return err
+	}
+	r, err := db.CheckBinary(path)
+	if err != nil {
+		return err
+	}
+	r.Binary = *cvulncheckTarget
+	if *cvulncheckJSON {
+		if err := printJSON(r); err != nil {
+			return err
+		}
+	} else {
+		printVulns(os.Stdout, r)
+	}
+	if len(r.Findings) > 0 {
+		return fmt.Errorf("%s: %d known vulnerabilities", r.Binary, len(r.Findings))
+	}
+	return nil
+}
+
+// vulncheckAll has the daemon check the binaries of the applications it
+// manages.
+func vulncheckAll() error {
+	// The daemon doesn't share the working directory of the client.
+	dir, err := filepath.Abs(*cvulncheckDB)
+	if err != nil {
+		return err
+	}
+	client, err := gapmdaemon.StartRemoteClient("127.0.0.1"+defaultServerAddr, time.Second)
+	if err != nil {
+		return fmt.Errorf("couldn't connect to the opengacm-client daemon: %v", err)
+	}
+	defer client.Close()
+	results, err := client.Vulncheck(dir)
+	if err != nil {
+		return err
+	}
+	if *cvulncheckJSON {
+		if err := printJSON(results); err != nil {
+			return err
+		}
+	}
+	n := 0
+	for i, res := range results {
+		if res.Result != nil {
+			n += len(res.Result.Findings)
+		}
+		if *cvulncheckJSON {
+			continue
+		}
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("Application: %s\n", res.Name)
+		if res.Error != "" {
+			fmt.Printf("Error: %s\n", res.Error)
+			continue
+		}
+		printVulns(os.Stdout, res.Result)
+	}
+	if n > 0 {
+		return fmt.Errorf("%d known vulnerabilities in %d applications", n, len(results))
+	}
+	return nil
+}
+
+func printVulns(w io.Writer, r *vuln.Result) {
+	tw := tabwriter.NewWriter(w, 0, 8, 1, ' ', 0)
+	fmt.Fprintf(tw, "Binary:\t%s\n", r.Binary)
+	fmt.Fprintf(tw, "Go version:\t%s\n", r.GoVersion)
+	tw.Flush()
+	if len(r.Findings) == 0 {
+		fmt.Fprintln(w, "No known vulnerabilities.")
+		return
+	}
+	fmt.Fprintln(w)
+	tw = tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tMODULE\tVERSION\tFIXED\tSUMMARY")
+	for _, f := range r.Findings {
+		id := f.ID
+		if len(f.Aliases) > 0 {
+			id += " (" + strings.Join(f.Aliases, ", ") + ")"
+		}
+		fixed := "not fixed"
+		if f.Fixed != "" {
+			fixed = displayVersion(f.Module, f.Fixed)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", id, f.Module, displayVersion(f.Module, f.Version), fixed, f.Summary)
+	}
+	tw.Flush()
+}
+
+// displayVersion returns the version v of the module path as it is
+// written in go.mod files, or as a Go version for the standard library.
+func displayVersion(path, v string) string {
+	if path == vuln.Stdlib {
+		return "go" + strings.TrimPrefix(v, "v")
+	}
+	return v
+}